@@ -12,7 +12,9 @@ import (
 	"go/token"
 	"go/types"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -40,18 +42,82 @@ var (
 type Checker struct {
 	vcs         VCS
 	vlog        io.Writer
-	path        string         // import path
-	recurse     bool           // scan paths recursively
-	excludeFile *regexp.Regexp // exclude files
-	excludeDir  *regexp.Regexp // exclude directory
+	path        string                      // import path
+	recurse     bool                        // scan paths recursively
+	excludeFile *regexp.Regexp              // exclude files
+	excludeDir  *regexp.Regexp              // exclude directory
+	followDeps  bool                        // parse and compare dependency packages
+	depsPrefix  string                      // import path prefix of dependencies to follow
+	depsModule  string                      // resolved prefix of dependencies to follow
+	highImpact  []*regexp.Regexp            // function names with a high impact if changed
+	apiFiles    []string                    // base names of files declaring the API, or all if empty
+	notable     map[string]*types.Interface // interfaces which change behaviour when newly satisfied
+	maxFileSize int64                       // maximum size of a file to parse, or 0 for no limit
+
+	trackZeroValue bool // report structs whose zero value may no longer be usable
+	trackWire      bool // report changes to struct fields' serialised format
+	conservative   bool // treat unclassifiable changes as breaking
+	parseComments  bool // parse comments, see parseMode
+
+	minConfidence Confidence  // minimum confidence of changes to report
+	sizes         types.Sizes // sizes used to check structs' memory layout, if set
+
+	includeIDs []string // glob patterns of declaration IDs to compare, or all if empty
+	excludeIDs []string // glob patterns of declaration IDs not to compare
+
+	versionFile string    // file containing the version to use as the before revision
+	baseline    bool      // return ErrBaselineEstablished if the before revision is missing
+	generate    bool      // run go generate in a checkout of each revision before parsing
+	materialize bool      // parse a temporary checkout of each revision, see SetMaterialize
+	checkedOut  *checkout // checkout of the revision being parsed, if materialized
+
+	classifier func(before, after ast.Decl, change DeclChange) DeclChange // overrides changes, if set
+
+	breakingTagKeys []string  // struct tag keys whose removal is breaking
+	internalMarker  string    // doc comment prefix of declarations to ignore
+	sortOrder       SortOrder // order of the returned changes
+
+	usage map[string]int // declaration ID to number of uses, see SetUsageData
+
+	collapseReexports bool // report changes to re-exported declarations once, see SetCollapseReexports
+
+	sourceURLTemplate string // template of changes' URLs, see SetSourceURLTemplate
+
+	perspective Perspective // who implements exported interfaces, see SetInterfacePerspective
+
+	breakingConstValues bool // report changes to constants' values as breaking, see SetBreakingConstValues
+
+	nonBreakingFieldOrder bool // report structs' fields being reordered as non-breaking, see SetBreakingFieldOrder
+
+	deadline time.Time     // stop comparing when passed, if set, see SetDeadline
+	timeout  time.Duration // stop comparing once elapsed since comparing started, if set, see SetTimeout
+
+	frozen      bool     // report added declarations as breaking, see SetFrozen
+	frozenAllow []string // glob patterns of declaration IDs which may be added while frozen
+
+	strictConsumer bool // report non-breaking changes as breaking, see SetStrictConsumer
+
+	trackConcurrency bool           // report changes to concurrency safety docs
+	concurrencyDocs  *regexp.Regexp // doc sentences describing concurrency safety
+
+	qualifier types.Qualifier // qualifies types compared as strings, by path if nil, see CheckMajorVersions
 
 	b map[string]pkg
 	a map[string]pkg
 }
 
+// defaultHighImpactPatterns are the default patterns of function names that
+// are commonly used as entry points by other packages, such as drivers.
+var defaultHighImpactPatterns = []string{"^Register", "^New", "^Open"}
+
 // New returns a Checker with the given options.
 func New(options ...func(*Checker)) *Checker {
 	c := &Checker{}
+	SetHighImpactPatterns(defaultHighImpactPatterns)(c)
+	SetNotableInterfaces(defaultNotableInterfaces)(c)
+	SetMinConfidence(Low)(c)
+	SetConcurrencyDocPattern(defaultConcurrencyDocPattern)(c)
+	SetBreakingTagKeys(defaultBreakingTagKeys)(c)
 	for _, option := range options {
 		option(c)
 	}
@@ -87,23 +153,459 @@ func SetExcludeDir(pattern string) func(*Checker) {
 	}
 }
 
+// SetFollowDeps is an option to New that also parses and compares the
+// dependencies of the checked packages, at both revisions. Only dependencies
+// within the same module are followed, see SetDepsPrefix to change this.
+func SetFollowDeps(follow bool) func(*Checker) {
+	return func(c *Checker) {
+		c.followDeps = follow
+	}
+}
+
+// SetDepsPrefix sets the import path prefix of dependencies to follow when
+// SetFollowDeps is enabled. If unset, the module path from the nearest go.mod
+// is used.
+func SetDepsPrefix(prefix string) func(*Checker) {
+	return func(c *Checker) {
+		c.depsPrefix = prefix
+	}
+}
+
+// SetHighImpactPatterns is an option to New that sets the regexp patterns of
+// package level function names which have a high impact to the ecosystem when
+// removed or changed, such as driver entry points. Matching changes are marked
+// as HighImpact. Defaults to ^Register, ^New and ^Open, use an empty list to
+// disable.
+func SetHighImpactPatterns(patterns []string) func(*Checker) {
+	return func(c *Checker) {
+		c.highImpact = nil
+		for _, pattern := range patterns {
+			c.highImpact = append(c.highImpact, regexp.MustCompile(pattern))
+		}
+	}
+}
+
+// SetTrackZeroValue is an option to New that reports structs which were usable
+// as their zero value, but have a field added that may require initialisation,
+// such as a map, as a breaking change. This is a heuristic, as the struct's
+// methods may initialise the fields when required.
+func SetTrackZeroValue(track bool) func(*Checker) {
+	return func(c *Checker) {
+		c.trackZeroValue = track
+	}
+}
+
+// SetTrackWireFormat is an option to New that reports changes to how a
+// struct's fields with json or xml tags are serialised, such as a field's type,
+// key or omitempty option changing, as a breaking change.
+func SetTrackWireFormat(track bool) func(*Checker) {
+	return func(c *Checker) {
+		c.trackWire = track
+	}
+}
+
+// SetBreakingConstValues is an option to New that reports constants changing
+// value, such as const Version = 1 or "1.0" becoming 2 or "2.0", as breaking,
+// for packages whose constants' values are part of their API. Changes to the
+// values of enums, of a defined type, are always breaking. Defaults to
+// non-breaking, as callers still compile.
+func SetBreakingConstValues(breaking bool) func(*Checker) {
+	return func(c *Checker) {
+		c.breakingConstValues = breaking
+	}
+}
+
+// SetBreakingFieldOrder is an option to New that sets whether structs' fields
+// being reordered, such as struct{ A, B int } becoming struct{ B, A int }, is
+// breaking. Positional struct literals, such as T{1, 2}, assign the wrong
+// fields, or no longer compile, and the memory layout changes, which matters
+// to cgo or unsafe code. Defaults to breaking, set to false for packages
+// whose structs are only initialized by field name.
+func SetBreakingFieldOrder(breaking bool) func(*Checker) {
+	return func(c *Checker) {
+		c.nonBreakingFieldOrder = !breaking
+	}
+}
+
+// SetConservative is an option to New that treats any change which cannot be
+// classified, such as when type information is unavailable, as a breaking
+// change, instead of returning an error or treating it as unchanged. Such
+// changes are reported regardless of the minimum confidence.
+func SetConservative(conservative bool) func(*Checker) {
+	return func(c *Checker) {
+		c.conservative = conservative
+	}
+}
+
+// SetAPIFiles is an option to New that restricts the checked declarations to
+// those in files with the given base names, such as api.go. Other files are
+// still type checked, but their declarations are ignored. If empty, the
+// default, declarations in all files are checked.
+func SetAPIFiles(names []string) func(*Checker) {
+	return func(c *Checker) {
+		c.apiFiles = names
+	}
+}
+
+// SetParseComments is an option to New that parses comments, which are then
+// available in each Change's Before and After declarations. Options which
+// require comments enable this automatically.
+func SetParseComments(parse bool) func(*Checker) {
+	return func(c *Checker) {
+		c.parseComments = parse
+	}
+}
+
+// SetMaxFileSize is an option to New that sets the maximum size in bytes of a
+// file to parse, larger files are skipped, such as when checking untrusted
+// input. If zero, the default, there's no limit.
+func SetMaxFileSize(size int64) func(*Checker) {
+	return func(c *Checker) {
+		c.maxFileSize = size
+	}
+}
+
+// SetSizes is an option to New that reports changes to the memory layout of
+// structs, such as their size, alignment or field offsets, as a breaking
+// change, using sizes for the target architecture, such as
+// types.SizesFor("gc", "386"). Word size dependent changes, such as fields of
+// type int being reordered, may only be reported for some architectures. If
+// nil, the default, memory layout is not checked.
+func SetSizes(sizes types.Sizes) func(*Checker) {
+	return func(c *Checker) {
+		c.sizes = sizes
+	}
+}
+
+// SetMinConfidence is an option to New that only reports changes with at
+// least the given confidence, such as High to exclude heuristics. Defaults to
+// Low, reporting all changes.
+func SetMinConfidence(confidence Confidence) func(*Checker) {
+	return func(c *Checker) {
+		c.minConfidence = confidence
+	}
+}
+
+// SetNotableInterfaces is an option to New that sets the interfaces, keyed by
+// name, which alter a type's runtime behaviour once satisfied, such as how it's
+// formatted. Existing types newly satisfying one of these interfaces are
+// reported as a non-breaking change. Defaults to error, fmt.Stringer and
+// encoding/json.Marshaler, a nil map disables the check.
+func SetNotableInterfaces(ifaces map[string]*types.Interface) func(*Checker) {
+	return func(c *Checker) {
+		c.notable = ifaces
+	}
+}
+
+// SetTrackConcurrencyDocs is an option to New that reports sentences in the
+// doc comments of functions, methods and types describing whether they're safe
+// for concurrent use being added, removed or changed, as a non-breaking change.
+// This enables parsing comments, see SetConcurrencyDocPattern to change which
+// sentences are matched.
+func SetTrackConcurrencyDocs(track bool) func(*Checker) {
+	return func(c *Checker) {
+		c.trackConcurrency = track
+	}
+}
+
+// SetConcurrencyDocPattern sets the regexp pattern of doc comment sentences
+// describing concurrency safety when SetTrackConcurrencyDocs is enabled, such
+// as "Safe for concurrent use".
+func SetConcurrencyDocPattern(pattern string) func(*Checker) {
+	return func(c *Checker) {
+		c.concurrencyDocs = regexp.MustCompile(pattern)
+	}
+}
+
+// SetIncludeIDs is an option to New that only compares declarations whose ID,
+// such as Server.Close, matches one of the glob patterns, such as Server.* or
+// *Config. See path.Match for the pattern syntax. If empty, the default, all
+// declarations are compared. Panics if a pattern is malformed.
+func SetIncludeIDs(patterns []string) func(*Checker) {
+	mustValidGlobs(patterns)
+	return func(c *Checker) {
+		c.includeIDs = patterns
+	}
+}
+
+// SetExcludeIDs is an option to New that doesn't compare declarations whose
+// ID matches one of the glob patterns, even if included by SetIncludeIDs. See
+// path.Match for the pattern syntax. Panics if a pattern is malformed.
+func SetExcludeIDs(patterns []string) func(*Checker) {
+	mustValidGlobs(patterns)
+	return func(c *Checker) {
+		c.excludeIDs = patterns
+	}
+}
+
+// mustValidGlobs panics if any of the glob patterns are malformed.
+func mustValidGlobs(patterns []string) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("apicompat: invalid glob pattern %q: %v", pattern, err))
+		}
+	}
+}
+
+// SetBeforeFromVersionFile is an option to New that sets the before revision,
+// when not otherwise set, to the tag matching the version in the file at path,
+// relative to the repository's root, at HEAD, such as VERSION containing
+// 1.2.0 for the tag 1.2.0 or v1.2.0. This requires the git VCS, see
+// Git.VersionTag.
+func SetBeforeFromVersionFile(path string) func(*Checker) {
+	return func(c *Checker) {
+		c.versionFile = path
+	}
+}
+
+// ErrBaselineEstablished is returned by Check, with no changes, when the
+// before revision doesn't exist and SetBaselineOnMissing is enabled, such as
+// the first run on a repository without prior commits or tags.
+var ErrBaselineEstablished = errors.New("no before revision, baseline established")
+
+// SetBaselineOnMissing is an option to New that treats a missing before
+// revision, such as HEAD~1 without a parent commit, or no tag matching the
+// version file, as establishing a baseline: Check returns no changes and
+// ErrBaselineEstablished instead of failing. A revision is only known to be
+// missing if the VCS implements RevisionChecker.
+func SetBaselineOnMissing(baseline bool) func(*Checker) {
+	return func(c *Checker) {
+		c.baseline = baseline
+	}
+}
+
+// SetClassifier is an option to New that sets a function to override or
+// refine the change for each declaration, such as to treat struct members
+// being added as breaking, according to an organisation's compatibility
+// policy. The classifier receives the before and after declarations, either of
+// which is nil if the declaration was added or removed, and the default change,
+// whose Change is None if no change was detected, and returns the change to
+// report. Returning a change of None omits it.
+func SetClassifier(classifier func(before, after ast.Decl, change DeclChange) DeclChange) func(*Checker) {
+	return func(c *Checker) {
+		c.classifier = classifier
+	}
+}
+
+// SetBreakingTagKeys is an option to New that sets the struct tag keys, such
+// as db, whose removal from a field is a breaking change, as they're used for
+// reflection based field mapping. Removals of other keys are reported as a
+// non-breaking change. Defaults to json, xml, db and yaml.
+func SetBreakingTagKeys(keys []string) func(*Checker) {
+	return func(c *Checker) {
+		c.breakingTagKeys = keys
+	}
+}
+
+// SetInternalMarker is an option to New that ignores declarations whose doc
+// comment contains a line starting with marker, such as "Internal:", at either
+// revision, including the fields and methods of such a type. This allows
+// exporting declarations for use within a module, which aren't considered part
+// of the public API. This enables parsing comments.
+func SetInternalMarker(marker string) func(*Checker) {
+	return func(c *Checker) {
+		c.internalMarker = marker
+	}
+}
+
+// SetGenerateBefore is an option to New that runs go generate for each
+// package, in a temporary checkout of the package's directory at each
+// revision, before parsing it. This allows checking packages whose generated
+// files, such as .pb.go, aren't committed, at the cost of running the
+// generators, which must be installed. The file system revision is never
+// generated, as it's the working copy.
+func SetGenerateBefore(generate bool) func(*Checker) {
+	return func(c *Checker) {
+		c.generate = generate
+	}
+}
+
+// SetMaterialize is an option to New that writes the files within the working
+// directory at each revision, including subdirectories, to a temporary
+// checkout, and parses the packages from there instead of reading each file
+// from the VCS. The checkout is removed once the revision is parsed. This
+// suits VCSs where reading many individual files is slow or unreliable. The
+// file system revision is never checked out, as it's the working copy.
+func SetMaterialize(materialize bool) func(*Checker) {
+	return func(c *Checker) {
+		c.materialize = materialize
+	}
+}
+
+// SortOrder is the order changes are returned in, see SetSortOrder.
+type SortOrder int
+
+// The different orders changes can be sorted by.
+const (
+	SortByID       SortOrder = iota // by ID, then package and position
+	SortBySeverity                  // breaking changes first, then by position
+	SortByPosition                  // by package, then file and line
+	SortByUsage                     // breaking changes first, then most used, see SetUsageData
+)
+
+// Perspective is who implements a package's exported interfaces, which
+// determines whether adding methods to them is breaking, see
+// SetInterfacePerspective.
+type Perspective int
+
+// The different perspectives of exported interfaces.
+const (
+	PerspectiveImplementer Perspective = iota // implemented by other packages, so adding methods is breaking
+	PerspectiveCaller                         // only implemented by the package, such as returned values, so adding methods isn't breaking
+)
+
+// SetInterfacePerspective is an option to New that sets who implements the
+// exported interfaces of the checked packages. With PerspectiveCaller, methods
+// added to an exported interface are non-breaking, as other packages only call
+// its methods, such as when the package only returns implementations of it.
+// Defaults to PerspectiveImplementer, where added methods break other
+// packages' implementations.
+func SetInterfacePerspective(perspective Perspective) func(*Checker) {
+	return func(c *Checker) {
+		c.perspective = perspective
+	}
+}
+
+// SetSortOrder is an option to New that sets the order changes are returned
+// in, such as SortBySeverity for triage. Defaults to SortByID.
+func SetSortOrder(order SortOrder) func(*Checker) {
+	return func(c *Checker) {
+		c.sortOrder = order
+	}
+}
+
+// SetUsageData is an option to New that sets the number of uses of each
+// declaration, such as from analysing dependent modules, which is set as each
+// change's Usage. Usage is keyed by a change's ID, such as T.M, or its package
+// and ID, such as example.com/pkg.T.M, which takes precedence. Use with
+// SortByUsage to prioritise the most used breaking changes.
+func SetUsageData(usage map[string]int) func(*Checker) {
+	return func(c *Checker) {
+		c.usage = usage
+	}
+}
+
+// ErrPartial is returned by Check, and each of the Checker's other methods
+// comparing revisions, with the changes found so far, if the Checker's
+// deadline passed, or timeout elapsed, before all declarations were compared,
+// see SetDeadline and SetTimeout.
+// Partial changes omit those found after comparing each declaration: changes
+// in packages which failed to type check, to generated declarations, to
+// notable interfaces implemented, to shadowed members and to interfaces no
+// longer implemented, as well as correlated renames and consolidations.
+var ErrPartial = errors.New("deadline exceeded, changes are partial")
+
+// SetDeadline is an option to New that stops comparing declarations once the
+// deadline has passed, such as for rapid feedback in an editor. Check then
+// returns the changes found so far and ErrPartial, see ErrPartial for the
+// changes omitted. The deadline doesn't limit
+// parsing each revision. If zero, the default, there's no deadline.
+func SetDeadline(deadline time.Time) func(*Checker) {
+	return func(c *Checker) {
+		c.deadline = deadline
+	}
+}
+
+// SetTimeout is an option to New that stops comparing declarations once the
+// timeout has elapsed since comparing started, after each revision is parsed,
+// as SetDeadline does. Each comparison of two revisions, such as by CheckMany,
+// has its own timeout. If zero, the default, there's no timeout.
+func SetTimeout(timeout time.Duration) func(*Checker) {
+	return func(c *Checker) {
+		c.timeout = timeout
+	}
+}
+
+// frozenAddedMsg is the message of a declaration added while frozen, see
+// SetFrozen.
+const frozenAddedMsg = "declaration added while frozen"
+
+// SetFrozen is an option to New that reports added declarations as breaking,
+// such as during an API freeze, unless allowed by SetFrozenAllow.
+func SetFrozen(frozen bool) func(*Checker) {
+	return func(c *Checker) {
+		c.frozen = frozen
+	}
+}
+
+// strictConsumerSuffix follows the message of a non-breaking change reported
+// as breaking when printed, see SetStrictConsumer, Change.String and Line.
+const strictConsumerSuffix = " (breaking for strict consumers)"
+
+// SetStrictConsumer is an option to New that reports every non-breaking
+// change, including additions, as breaking, for a consumer of a dependency
+// whose CI should fail on any change to its API, such as to review each
+// upgrade. Unlike SetFrozen, which an author uses to freeze their own API,
+// every change is reported, and Change.Strict identifies the elevated changes,
+// whose messages are unchanged.
+func SetStrictConsumer(strict bool) func(*Checker) {
+	return func(c *Checker) {
+		c.strictConsumer = strict
+	}
+}
+
+// SetFrozenAllow is an option to New that allows declarations whose ID, such as
+// Server.Close, matches one of the glob patterns, such as Server.*, to be added
+// while frozen, see SetFrozen. Allowed declarations are reported as
+// non-breaking additions. See path.Match for the pattern syntax. Panics if a
+// pattern is malformed.
+func SetFrozenAllow(patterns []string) func(*Checker) {
+	mustValidGlobs(patterns)
+	return func(c *Checker) {
+		c.frozenAllow = patterns
+	}
+}
+
+// SetCollapseReexports is an option to New that reports a change to a
+// declaration in an internal package, which is re-exported by an alias in
+// another package, such as type Foo = internal.Foo, once as the internal
+// declaration's change, instead of also reporting it as the re-export's
+// change. Changes to internal packages are only found with SetFollowDeps.
+func SetCollapseReexports(collapse bool) func(*Checker) {
+	return func(c *Checker) {
+		c.collapseReexports = collapse
+	}
+}
+
+// SetSourceURLTemplate is an option to New that sets each change's URL, such as
+// to link to a source browser, from a template of the change's position, such
+// as https://github.com/org/repo/blob/{rev}/{file}#L{line}. {rev} is the
+// revision, {file} the file relative to the repository's root if the VCS is
+// git, otherwise the working directory, and {line} the line. Changes to the
+// file system revision have no URL if the template includes {rev}.
+func SetSourceURLTemplate(template string) func(*Checker) {
+	return func(c *Checker) {
+		c.sourceURLTemplate = template
+	}
+}
+
 // Check an import path and before and after revision for changes. Import path
 // maybe empty, if so, the current working directory will be used. If a
 // revision is blank, the default VCS revision is used.
 func (c *Checker) Check(rel string, recurse bool, beforeRev, afterRev string) ([]Change, error) {
 	// If revision is unset use VCS's default revision
 	dBefore, dAfter := c.vcs.DefaultRevision()
+	if beforeRev == "" && c.versionFile != "" {
+		var err error
+		if dBefore, err = c.beforeFromVersionFile(); err != nil {
+			if c.baseline && errors.Is(err, errNoTag) {
+				c.logf("%v\n", err)
+				return nil, ErrBaselineEstablished
+			}
+			return nil, err
+		}
+	}
 	if beforeRev == "" {
 		beforeRev = dBefore
 	}
 	if afterRev == "" {
 		afterRev = dAfter
 	}
-	c.recurse = recurse
-
-	var err error
-	c.path, err = importPathTo(rel)
-	if err != nil {
+	if rc, ok := c.vcs.(RevisionChecker); ok && c.baseline && !rc.HasRevision(beforeRev) {
+		c.logf("before revision %q not found\n", beforeRev)
+		return nil, ErrBaselineEstablished
+	}
+	if err := c.setPath(rel, recurse, afterRev); err != nil {
 		return nil, err
 	}
 
@@ -111,6 +613,7 @@ func (c *Checker) Check(rel string, recurse bool, beforeRev, afterRev string) ([
 
 	// Parse revisions from VCS into go/ast
 	start := time.Now()
+	var err error
 	if c.b, err = c.parse(beforeRev); err != nil {
 		return nil, err
 	}
@@ -120,26 +623,199 @@ func (c *Checker) Check(rel string, recurse bool, beforeRev, afterRev string) ([
 	parse := time.Since(start)
 
 	start = time.Now()
-	changes, err := c.compareDecls()
+	changes, err := c.compare()
+	if err != nil && err != ErrPartial {
+		return nil, err
+	}
+	compare := time.Since(start)
+
+	c.logf("Timing: parse: %v, compare: %v, total: %v\n", parse, compare, parse+compare)
+	c.logf("Changes detected: %v\n", len(changes))
+
+	return changes, err
+}
+
+// CheckMany checks an import path at the after revision for changes against
+// each of the before revisions, such as several prior releases, returning the
+// changes keyed by before revision. The after revision is only parsed once. If
+// the after revision is blank, the default VCS revision is used. If the
+// Checker's deadline passes, the changes so far are returned with ErrPartial,
+// and before revisions not yet compared are missing.
+func (c *Checker) CheckMany(rel string, recurse bool, afterRev string, beforeRevs []string) (map[string][]Change, error) {
+	if afterRev == "" {
+		_, afterRev = c.vcs.DefaultRevision()
+	}
+	if err := c.setPath(rel, recurse, afterRev); err != nil {
+		return nil, err
+	}
+
+	c.logf("import path: %q before: %q after: %q recursive: %v\n", c.path, beforeRevs, afterRev, c.recurse)
+
+	var err error
+	if c.a, err = c.parse(afterRev); err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string][]Change, len(beforeRevs))
+	for _, beforeRev := range beforeRevs {
+		if c.b, err = c.parse(beforeRev); err != nil {
+			return nil, err
+		}
+		changes[beforeRev], err = c.compare()
+		if err == ErrPartial {
+			return changes, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.logf("Changes detected against %q: %v\n", beforeRev, len(changes[beforeRev]))
+	}
+	return changes, nil
+}
+
+// CheckBidirectional checks an import path for both backward and forward
+// compatibility between two revisions. Forward are the changes from before to
+// after, those which break consumers of before upgrading to after. Backward are
+// the changes from after to before, those which break consumers of after if
+// downgraded to before. Each revision is only parsed once. If a revision is
+// blank, the default VCS revision is used. If the Checker's deadline passes,
+// the changes so far are returned with ErrPartial, backward is nil if the
+// deadline passed while comparing forward.
+func (c *Checker) CheckBidirectional(rel string, recurse bool, beforeRev, afterRev string) (forward, backward []Change, err error) {
+	dBefore, dAfter := c.vcs.DefaultRevision()
+	if beforeRev == "" {
+		beforeRev = dBefore
+	}
+	if afterRev == "" {
+		afterRev = dAfter
+	}
+	if err := c.setPath(rel, recurse, afterRev); err != nil {
+		return nil, nil, err
+	}
+
+	c.logf("import path: %q before: %q after: %q recursive: %v bidirectional\n", c.path, beforeRev, afterRev, c.recurse)
+
+	if c.b, err = c.parse(beforeRev); err != nil {
+		return nil, nil, err
+	}
+	if c.a, err = c.parse(afterRev); err != nil {
+		return nil, nil, err
+	}
+	forward, err = c.compare()
+	if err == ErrPartial {
+		return forward, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	c.a, c.b = c.b, c.a
+	backward, err = c.compare()
+	if err == ErrPartial {
+		return forward, backward, err
+	}
 	if err != nil {
-		var buf bytes.Buffer
-		fmt.Fprintf(&buf, "error comparing declarations: %s\n", err)
-		if derr, ok := err.(*diffError); ok {
-			_ = ast.Fprint(&buf, c.b[derr.pkg].fset, derr.bdecl, ast.NotNilFilter)
-			_ = ast.Fprint(&buf, c.a[derr.pkg].fset, derr.adecl, ast.NotNilFilter)
+		return nil, nil, err
+	}
+	c.logf("Changes detected forward: %v backward: %v\n", len(forward), len(backward))
+	return forward, backward, nil
+}
+
+// CheckNew checks an import path at a revision against an empty before
+// revision, such as for a new package's first changelog, so each declaration
+// is reported as added. If the revision is blank, the default VCS after
+// revision is used. If the Checker's deadline passes, the changes so far are
+// returned with ErrPartial.
+func (c *Checker) CheckNew(rel string, recurse bool, rev string) ([]Change, error) {
+	if rev == "" {
+		_, rev = c.vcs.DefaultRevision()
+	}
+	if err := c.setPath(rel, recurse, rev); err != nil {
+		return nil, err
+	}
+
+	c.logf("import path: %q after: %q recursive: %v\n", c.path, rev, c.recurse)
+
+	var err error
+	if c.a, err = c.parse(rev); err != nil {
+		return nil, err
+	}
+
+	// each package is compared against an empty package without declarations
+	c.b = make(map[string]pkg, len(c.a))
+	for pkgName, apkg := range c.a {
+		if apkg.dep {
+			continue
+		}
+		c.b[pkgName] = pkg{importPath: apkg.importPath, fset: token.NewFileSet(), decls: make(map[string]ast.Decl)}
+	}
+	return c.compare()
+}
+
+// setPath sets the import path to check from the relative path rel, and
+// resolves the dependencies to follow at revision rev.
+func (c *Checker) setPath(rel string, recurse bool, rev string) error {
+	c.recurse = recurse
+
+	var err error
+	c.path, err = importPathTo(rel)
+	if err != nil {
+		return err
+	}
+
+	c.depsModule = ""
+	if c.followDeps {
+		c.depsModule = c.depsPrefix
+		if c.depsModule == "" {
+			abs, err := filepath.Abs(rel)
+			if err != nil {
+				return err
+			}
+			if c.depsModule, err = c.modulePath(rev, abs); err != nil {
+				return err
+			}
+		}
+		c.logf("following dependencies with prefix: %q\n", c.depsModule)
+	}
+	return nil
+}
+
+// compare compares the parsed before and after packages and returns the
+// changes sorted by ID. If the Checker's deadline passed, the changes found so
+// far are returned with ErrPartial.
+func (c *Checker) compare() ([]Change, error) {
+	changes, err := c.compareDecls()
+	if err != nil && err != ErrPartial {
+		return nil, err
+	}
+	partial := err
+	changes = c.reexportChanges(changes)
+	for i, change := range changes {
+		changes[i].Usage = c.usageOf(change)
+		changes[i].URL = c.sourceURLOf(change)
+		if c.strictConsumer && change.Change == NonBreaking {
+			changes[i].Change = Breaking
+			changes[i].Strict = true
 		}
-		return nil, errors.New(buf.String())
 	}
-	diff := time.Since(start)
+	c.sortChanges(changes)
 
-	start = time.Now()
-	sort.Sort(byID(changes))
-	sort := time.Since(start)
-
-	c.logf("Timing: parse: %v, diff: %v, sort: %v, total: %v\n", parse, diff, sort, parse+diff+sort)
-	c.logf("Changes detected: %v\n", len(changes))
+	// filter changes less certain than the minimum confidence
+	filtered := changes[:0]
+	for _, change := range changes {
+		if change.Confidence <= c.minConfidence {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered, partial
+}
 
-	return changes, nil
+// usageOf returns the number of uses of a change's declaration, from the
+// Checker's usage data.
+func (c *Checker) usageOf(change Change) int {
+	if usage, ok := c.usage[change.StableID()]; ok {
+		return usage
+	}
+	return c.usage[change.ID]
 }
 
 func importPathTo(rel string) (string, error) {
@@ -216,14 +892,87 @@ func (c Checker) logf(format string, a ...interface{}) {
 
 type pkg struct {
 	importPath string // import path
+	dep        bool   // package was only parsed as a dependency
 	fset       *token.FileSet
 	decls      map[string]ast.Decl
 	info       *types.Info
+	types      *types.Package
+	generate   []directive // go:generate directives
+	typeErr    *TypeError  // set if the package failed to type check
+
+	options []func(*Checker) // options from the package's configuration file, see parseConfig
 }
 
 func (c Checker) parse(rev string) (pkgs map[string]pkg, err error) {
 	c.logf("Parsing revision: %s path: %s recurse: %v\n", rev, c.path, c.recurse)
 
+	if c.materialize && rev != revisionFS {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		ipath, err := c.importPath(rev, cwd)
+		if err != nil {
+			return nil, err
+		}
+		co, err := c.checkout(rev, wd, ipath)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(co.gopath)
+		c.checkedOut = co
+	}
+
+	paths, err := c.pkgPaths(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs = make(map[string]pkg)
+	var (
+		imp  = importer.Default()
+		deps = c.followDeps && c.depsModule != ""
+	)
+	if deps {
+		imp = &depImporter{c: c, rev: rev, prefix: c.depsModule, pkgs: pkgs, def: imp}
+	}
+	for _, path := range paths {
+		if deps {
+			// paths may be directories, but dependencies are keyed by import path
+			if ipath, err := c.importPath(rev, path); err == nil {
+				if p, ok := pkgs[ipath]; ok {
+					// already parsed as a dependency of another package
+					p.dep = false
+					pkgs[ipath] = p
+					continue
+				}
+			}
+		}
+
+		p, err := c.parseDir(rev, path, imp)
+		if err != nil {
+			if err == errSkipPackage {
+				continue
+			}
+			if terr, ok := err.(*TypeError); ok && c.recurse {
+				// may be caused by a change in another checked package, which
+				// compareDecls will try to correlate
+				pkgs[terr.ImportPath] = pkg{importPath: terr.ImportPath, typeErr: terr}
+				continue
+			}
+			// skip errors if we're recursing and the error is no buildable sources
+			if !c.recurse || !strings.Contains(err.Error(), "no buildable") {
+				return pkgs, err
+			}
+		}
+		pkgs[p.importPath] = p
+	}
+
+	return pkgs, nil
+}
+
+// pkgPaths returns the paths of the packages to check at revision rev.
+func (c Checker) pkgPaths(rev string) ([]string, error) {
 	// c.path is either dot or import path
 	paths := []string{c.path}
 	if c.recurse {
@@ -237,7 +986,7 @@ func (c Checker) parse(rev string) (pkgs map[string]pkg, err error) {
 		var prefix string
 		if c.path == cwd {
 			// could c.path = getwd instead ?
-			if dir, err = os.Getwd(); err != nil {
+			if dir, err = c.srcDir(); err != nil {
 				return nil, err
 			}
 			prefix = "." + string(os.PathSeparator)
@@ -247,7 +996,7 @@ func (c Checker) parse(rev string) (pkgs map[string]pkg, err error) {
 
 	c.logf("building paths: %s\n", paths)
 
-	pkgs = make(map[string]pkg)
+	var included []string
 	for _, path := range paths {
 		if c.excludeDir != nil && c.excludeDir.MatchString(path) {
 			c.logf("Excluding path: %s\n", path)
@@ -257,20 +1006,65 @@ func (c Checker) parse(rev string) (pkgs map[string]pkg, err error) {
 			c.logf("Excluding path: %s\n", path)
 			continue
 		}
+		included = append(included, path)
+	}
+	return included, nil
+}
 
-		p, err := c.parseDir(rev, path)
-		if err != nil {
-			if err == errSkipPackage {
-				continue
+// depImporter is a types.Importer which parses and type checks dependencies
+// matching prefix at a revision using the same pipeline as the checked
+// packages, adding them to pkgs. Other imports use the default importer.
+type depImporter struct {
+	c      Checker
+	rev    string
+	prefix string
+	pkgs   map[string]pkg
+	def    types.Importer
+}
+
+// Import implements types.Importer.
+func (i *depImporter) Import(path string) (*types.Package, error) {
+	if path != i.prefix && !strings.HasPrefix(path, i.prefix+"/") || strings.Contains(path, "vendor/") {
+		return i.def.Import(path)
+	}
+	if p, ok := i.pkgs[path]; ok {
+		return p.types, nil
+	}
+
+	i.c.logf("Parsing dependency: %s revision: %s\n", path, i.rev)
+	p, err := i.c.parseDir(i.rev, path, i)
+	if err != nil {
+		return nil, err
+	}
+	p.dep = true
+	i.pkgs[p.importPath] = p
+	return p.types, nil
+}
+
+// modulePath returns the module path declared by the nearest go.mod file to
+// dir at revision rev, or an empty string if no go.mod could be found.
+func (c Checker) modulePath(rev, dir string) (string, error) {
+	for {
+		r, err := c.vcs.OpenFile(rev, filepath.Join(dir, "go.mod"))
+		if err == nil {
+			contents, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return "", err
 			}
-			// skip errors if we're recursing and the error is no buildable sources
-			if !c.recurse || !strings.Contains(err.Error(), "no buildable") {
-				return pkgs, err
+			for _, line := range strings.Split(string(contents), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 2 && fields[0] == "module" {
+					return strings.Trim(fields[1], `"`), nil
+				}
 			}
 		}
-		pkgs[p.importPath] = p
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
 	}
-	return pkgs, nil
 }
 
 func findGOPATH(path string) (string, error) {
@@ -289,7 +1083,7 @@ func findGOPATH(path string) (string, error) {
 // getDirsRecursive returns relative paths to all subdirectories within base
 // at revision rev. Paths can be prefixed with prefix
 func (c Checker) getDirsRecursive(base, rev, rel, prefix string) (dirs []string) {
-	paths, err := c.vcs.ReadDir(rev, filepath.Join(base, rel))
+	paths, err := c.buildContext(rev).ReadDir(filepath.Join(base, rel))
 	if err != nil {
 		c.logf("could not read path: %s revision: %s, error: %s\n", filepath.Join(base, rel), rev, err)
 		return dirs
@@ -306,35 +1100,141 @@ func (c Checker) getDirsRecursive(base, rev, rel, prefix string) (dirs []string)
 	return dirs
 }
 
-func (c Checker) parseDir(rev, dir string) (pkg, error) {
+// parseDir parses and type checks the package in dir at revision rev. The
+// parsed files are retained, as the declarations refer to their AST and the
+// package to their FileSet, see parseMode and BenchmarkParseLarge.
+func (c Checker) parseDir(rev, dir string, imp types.Importer) (pkg, error) {
+	p, files, err := c.parseFiles(rev, dir)
+	if err != nil {
+		return pkg{}, err
+	}
+	p, err = checkFiles(p, files, imp, c.apiFiles)
+	if terr, ok := err.(*TypeError); ok {
+		terr.Rev = rev
+	}
+	return p, err
+}
+
+// parseMode returns the parser's mode, which only includes comments if
+// they're required, as they're otherwise unused. Identifiers aren't resolved
+// to objects, as go/types resolves them instead, which reduces memory.
+func (c Checker) parseMode() parser.Mode {
+	mode := parser.SkipObjectResolution
+	if c.parseComments || c.trackConcurrency || c.internalMarker != "" {
+		mode |= parser.ParseComments
+	}
+	return mode
+}
 
-	// Use go/build to get the list of files relevant for a specific OS and ARCH
+// buildContext returns a go/build context reading from the VCS at revision rev,
+// except for the revision's checkout, if materialized, which precedes GOPATH
+// and is read from the file system.
+func (c Checker) buildContext(rev string) build.Context {
 	ctx := build.Default
+	ctx.GOPATH = os.Getenv("GOPATH")
+	co := c.checkedOut
+	if co != nil {
+		ctx.GOPATH = strings.Join(append([]string{co.gopath}, filepath.SplitList(ctx.GOPATH)...), string(filepath.ListSeparator))
+	}
 	ctx.ReadDir = func(dir string) ([]os.FileInfo, error) {
+		if co != nil && co.contains(dir) {
+			return ioutil.ReadDir(dir)
+		}
 		return c.vcs.ReadDir(rev, dir)
 	}
 	ctx.OpenFile = func(path string) (io.ReadCloser, error) {
+		if co != nil && co.contains(path) {
+			return os.Open(path)
+		}
 		return c.vcs.OpenFile(rev, path)
 	}
-	ctx.GOPATH = os.Getenv("GOPATH")
+	return ctx
+}
+
+// srcDir returns the directory relative import paths, such as ".", are
+// resolved from: the working directory, or its checkout if materialized.
+func (c Checker) srcDir() (string, error) {
+	if c.checkedOut != nil {
+		return c.checkedOut.dir, nil
+	}
+	return os.Getwd()
+}
+
+// importPath returns the import path of the package in dir at revision rev,
+// which may be a directory relative to the working directory.
+func (c Checker) importPath(rev, dir string) (string, error) {
+	wd, err := c.srcDir()
+	if err != nil {
+		return "", err
+	}
+	ctx := c.buildContext(rev)
+	ipkg, err := ctx.Import(dir, wd, build.FindOnly)
+	if err != nil {
+		return "", err
+	}
+	return ipkg.ImportPath, nil
+}
+
+// parseFiles uses go/build to find the files of the package in dir at revision
+// rev, and parses them. The returned pkg is not type checked.
+func (c Checker) parseFiles(rev, dir string) (pkg, []*ast.File, error) {
+	// Use go/build to get the list of files relevant for a specific OS and ARCH
+	ctx := c.buildContext(rev)
 
 	// wd is for relative imports, such as "."
-	wd, err := os.Getwd()
+	wd, err := c.srcDir()
 	if err != nil {
-		return pkg{}, err
+		return pkg{}, nil, err
+	}
+	if c.generate && rev != revisionFS {
+		gpkg, err := ctx.Import(dir, wd, build.FindOnly)
+		if err != nil {
+			return pkg{}, nil, &BuildError{Rev: rev, ImportPath: dir, Err: err}
+		}
+		gen, err := c.generatePackage(rev, gpkg.Dir)
+		if err != nil {
+			return pkg{}, nil, err
+		}
+		defer os.RemoveAll(gen)
+
+		// read the package's directory from the generated checkout
+		readDir, openFile := ctx.ReadDir, ctx.OpenFile
+		ctx.ReadDir = func(dir string) ([]os.FileInfo, error) {
+			if dir == gpkg.Dir {
+				return ioutil.ReadDir(gen)
+			}
+			return readDir(dir)
+		}
+		ctx.OpenFile = func(path string) (io.ReadCloser, error) {
+			if filepath.Dir(path) == gpkg.Dir {
+				return os.Open(filepath.Join(gen, filepath.Base(path)))
+			}
+			return openFile(path)
+		}
 	}
 	ipkg, err := ctx.Import(dir, wd, 0)
 	if err != nil {
-		return pkg{}, fmt.Errorf("go/build error: %v", err)
+		return pkg{}, nil, &BuildError{Rev: rev, ImportPath: dir, Err: err}
 	}
 
 	if ipkg.Name == "main" {
-		return pkg{}, errSkipPackage
+		return pkg{}, nil, errSkipPackage
+	}
+
+	var options []func(*Checker)
+	if r, err := ctx.OpenFile(filepath.Join(ipkg.Dir, pkgConfigFile)); err == nil {
+		options, err = parseConfig(pkgConfigFile, r)
+		r.Close()
+		if err != nil {
+			return pkg{}, nil, fmt.Errorf("invalid configuration of %q at revision %q: %v", ipkg.ImportPath, rev, err)
+		}
 	}
 
 	var (
-		fset     = token.NewFileSet()
+		p        = pkg{importPath: ipkg.ImportPath, fset: token.NewFileSet(), options: options}
 		pkgFiles []*ast.File
+		// contents is reused for each file, as the parser doesn't retain it
+		contents bytes.Buffer
 	)
 	for _, file := range ipkg.GoFiles {
 		if c.excludeFile != nil && c.excludeFile.MatchString(file) {
@@ -342,50 +1242,80 @@ func (c Checker) parseDir(rev, dir string) (pkg, error) {
 			continue
 		}
 
-		contents, err := c.vcs.OpenFile(rev, filepath.Join(ipkg.Dir, file))
+		r, err := ctx.OpenFile(filepath.Join(ipkg.Dir, file))
 		if err != nil {
-			return pkg{}, fmt.Errorf("could not read file %q at revision %q: %s", file, rev, err)
+			return pkg{}, nil, fmt.Errorf("could not read file %q at revision %q: %s", file, rev, err)
+		}
+		if c.maxFileSize > 0 {
+			// read one byte more than the limit to detect exceeding it
+			r = struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(r, c.maxFileSize+1), r}
+		}
+		contents.Reset()
+		_, err = contents.ReadFrom(r)
+		r.Close()
+		if err != nil {
+			return pkg{}, nil, fmt.Errorf("could not read file %q at revision %q: %s", file, rev, err)
+		}
+		if c.maxFileSize > 0 && int64(contents.Len()) > c.maxFileSize {
+			c.logf("Skipping file: %s exceeds maximum size of %d bytes\n", file, c.maxFileSize)
+			continue
 		}
 
 		filename, err := filepath.Rel(wd, filepath.Join(ipkg.Dir, file))
 		if err != nil {
-			return pkg{}, fmt.Errorf("could not make path relative for revision %q: %s", rev, err)
+			return pkg{}, nil, fmt.Errorf("could not make path relative for revision %q: %s", rev, err)
 		}
 		if rev != revisionFS {
 			// prefix revision to file's path when reading from vcs and not file system
 			filename = rev + ":" + filename
 		}
-		src, err := parser.ParseFile(fset, filename, contents, 0)
+		src, err := parser.ParseFile(p.fset, filename, contents.Bytes(), c.parseMode())
 		if err != nil {
-			return pkg{}, fmt.Errorf("could not parse file %q at revision %q: %s", file, rev, err)
+			return pkg{}, nil, &ParseError{Rev: rev, File: file, Err: err}
 		}
+		p.generate = append(p.generate, generateDirectives(filename, contents.Bytes())...)
 
 		pkgFiles = append(pkgFiles, src)
 	}
+	return p, pkgFiles, nil
+}
 
+// checkFiles type checks a package's parsed files and extracts the
+// declarations to compare.
+func checkFiles(p pkg, files []*ast.File, imp types.Importer, apiFiles []string) (pkg, error) {
 	// Loop through all the parsed files and type check them
-	p := pkg{
-		importPath: ipkg.ImportPath,
-		fset:       fset,
-		info: &types.Info{
-			Types: make(map[ast.Expr]types.TypeAndValue),
-			Defs:  make(map[*ast.Ident]types.Object),
-			Uses:  make(map[*ast.Ident]types.Object),
-		},
+	p.info = &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
 	}
 
+	terr := &TypeError{ImportPath: p.importPath}
 	conf := &types.Config{
 		IgnoreFuncBodies:         true,
 		DisableUnusedImportCheck: true,
-		Importer:                 importer.Default(),
+		Importer:                 imp,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok {
+				terr.Errs = append(terr.Errs, e)
+			}
+		},
 	}
-	_, err = conf.Check(ipkg.ImportPath, fset, pkgFiles, p.info)
+	var err error
+	p.types, err = conf.Check(p.importPath, p.fset, files, p.info)
 	if err != nil {
+		if len(terr.Errs) > 0 {
+			terr.pkg = p.types
+			return pkg{}, terr
+		}
 		return pkg{}, fmt.Errorf("go/types error: %v", err)
 	}
 
 	// Get declarations and nil their bodies, so do it last
-	p.decls = pkgDecls(pkgFiles)
+	p.decls = pkgDecls(p.fset, files, apiFiles)
 
 	return p, nil
 }
@@ -398,7 +1328,7 @@ func (c Checker) parseDir(rev, dir string) (pkg, error) {
 // into one per declaration.
 // from: struct { p1, p2 int, P3, P4 uint }
 // into: struct { P3 uint, P4 uint }
-func pkgDecls(files []*ast.File) map[string]ast.Decl {
+func pkgDecls(fset *token.FileSet, files []*ast.File, apiFiles []string) map[string]ast.Decl {
 	var (
 		// exported values and functions
 		decls = make(map[string]ast.Decl)
@@ -410,6 +1340,9 @@ func pkgDecls(files []*ast.File) map[string]ast.Decl {
 		returned []string
 	)
 	for _, file := range files {
+		if !isAPIFile(fset, file, apiFiles) {
+			continue
+		}
 		for _, astDecl := range file.Decls {
 			switch d := astDecl.(type) {
 			case *ast.GenDecl:
@@ -417,6 +1350,11 @@ func pkgDecls(files []*ast.File) map[string]ast.Decl {
 				// only changed declarations, instead of all, I don't imagine it's needed
 				// for TypeSpec (just ValueSpec), it does this by creating a new GenDecl
 				// with just that loops spec
+				var doc *ast.CommentGroup
+				if !d.Lparen.IsValid() {
+					// the declaration's comment only documents an ungrouped spec
+					doc = d.Doc
+				}
 				for i := range d.Specs {
 					var (
 						id   string
@@ -438,7 +1376,7 @@ func pkgDecls(files []*ast.File) map[string]ast.Decl {
 								// Check j is not nil
 								spec.Values = []ast.Expr{s.Values[j]}
 							}
-							decl = &ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{spec}}
+							decl = &ast.GenDecl{Doc: doc, Tok: d.Tok, Specs: []ast.Spec{spec}}
 						}
 					case *ast.TypeSpec:
 						// type struct/interface/etc
@@ -456,7 +1394,7 @@ func pkgDecls(files []*ast.File) map[string]ast.Decl {
 								}
 							}
 						}
-						decl = &ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{s}}
+						decl = &ast.GenDecl{Doc: doc, Tok: d.Tok, Specs: []ast.Spec{s}}
 					case *ast.ImportSpec:
 						// ignore
 						continue
@@ -477,13 +1415,7 @@ func pkgDecls(files []*ast.File) map[string]ast.Decl {
 				)
 				// check if we have a receiver (and not just `func () Method() {}`)
 				if d.Recv != nil && len(d.Recv.List) > 0 {
-					expr := d.Recv.List[0].Type
-					switch e := expr.(type) {
-					case *ast.Ident:
-						recv = e.Name
-					case *ast.StarExpr:
-						recv = e.X.(*ast.Ident).Name
-					}
+					recv = recvTypeName(d.Recv.List[0].Type)
 					id = recv + "." + id
 				}
 				astDecl.(*ast.FuncDecl).Body = nil
@@ -543,6 +1475,43 @@ func pkgDecls(files []*ast.File) map[string]ast.Decl {
 	return decls
 }
 
+// isAPIFile returns true if file's base name is one of apiFiles, or apiFiles
+// is empty.
+func isAPIFile(fset *token.FileSet, file *ast.File, apiFiles []string) bool {
+	if len(apiFiles) == 0 {
+		return true
+	}
+	name := filepath.Base(fset.Position(file.Pos()).Filename)
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		// remove the revision prefix from files in the current directory
+		name = name[i+1:]
+	}
+	for _, apiFile := range apiFiles {
+		if name == apiFile {
+			return true
+		}
+	}
+	return false
+}
+
+// recvTypeName returns the name of a method's receiver type, without any
+// pointer or type parameters, such as T given *T[K, V].
+func recvTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return recvTypeName(e.X)
+	case *ast.ParenExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexListExpr:
+		return recvTypeName(e.X)
+	}
+	panic(fmt.Sprintf("unknown receiver type: %T", expr))
+}
+
 // expandFieldList expands an ast.FieldList's shorthand notation:
 // (a, b int) to (a int, b int). A ast.FieldList could be function's signature
 // struct, interface etc. If isStruct is true, only exported idents are
@@ -583,12 +1552,13 @@ func keepField(expr ast.Expr, isStruct bool) bool {
 
 	switch etype := expr.(type) {
 	case *ast.StarExpr:
-		switch estar := etype.X.(type) {
-		case *ast.SelectorExpr:
-			return true
-		case *ast.Ident:
-			return ast.IsExported(estar.Name)
-		}
+		return keepField(etype.X, isStruct)
+	case *ast.IndexExpr:
+		// embedded generic instantiation, such as Base[int]
+		return keepField(etype.X, isStruct)
+	case *ast.IndexListExpr:
+		// embedded generic instantiation, such as pkg.Base[int, string]
+		return keepField(etype.X, isStruct)
 	case *ast.SelectorExpr:
 		return true
 	case *ast.Ident:
@@ -607,6 +1577,40 @@ type Change struct {
 	Pos    string   // Pos is the ASTs position prefixed with a version
 	Before ast.Decl // Before is the previous declaration
 	After  ast.Decl // After is the new declaration
+
+	// HighImpact is true if the change is to a function matching a high impact
+	// pattern, such as a driver's registration function.
+	HighImpact bool
+
+	// Confidence is how certain the change is, heuristics have a lower
+	// confidence than direct structural differences.
+	Confidence Confidence
+
+	// Usage is the number of uses of the declaration, from the Checker's usage
+	// data, or 0 if unknown, see SetUsageData.
+	Usage int
+
+	// Origin is the declaration in an internal package, such as
+	// example.com/mod/internal/foo.Foo, a change to a re-exported alias
+	// originates in, or empty if the change isn't to a re-export.
+	Origin string
+
+	// URL is the URL of the change's position, such as in a source browser,
+	// or empty if unknown, see SetSourceURLTemplate.
+	URL string
+
+	// Category is the category of the change, such as declaration-removed or
+	// parameters-changed, which is its rule in SARIF logs, or empty if
+	// uncategorised, see SARIF.
+	Category string
+
+	// Kind is what the change did to the API, such as Addition, regardless of
+	// its message, see Additions, Removals and Modifications.
+	Kind Kind
+
+	// Strict is true if the change is non-breaking, but reported as breaking
+	// for a strict consumer, see SetStrictConsumer.
+	Strict bool
 }
 
 func (c Change) String() string {
@@ -614,7 +1618,11 @@ func (c Change) String() string {
 	var buf bytes.Buffer
 	pcfg := printer.Config{Mode: printer.RawFormat, Indent: 1}
 
-	fmt.Fprintf(&buf, "%s: %s %s\n", c.Pos, c.Change, c.Msg)
+	fmt.Fprintf(&buf, "%s: %s %s", c.Pos, c.Change, c.Msg)
+	if c.Strict {
+		buf.WriteString(strictConsumerSuffix)
+	}
+	fmt.Fprintln(&buf)
 
 	if c.Before != nil {
 		_ = pcfg.Fprint(&buf, &fset, c.Before)
@@ -627,50 +1635,243 @@ func (c Change) String() string {
 	return buf.String()
 }
 
-// byID implements sort.Interface for []change based on the id field
+// StableID returns the change's declaration ID qualified by its package's
+// import path, such as example.com/pkg.Server.Serve, for use as a key to track
+// a declaration's changes across releases. Unlike Pos, it depends only on the
+// declaration's package and name, not its position or the source's layout. A
+// change to a package, such as its removal, has the package's import path.
+// It's also the qualified key of SetUsageData.
+func (c Change) StableID() string {
+	switch {
+	case c.Pkg == "":
+		return c.ID
+	case c.ID == "":
+		return c.Pkg
+	}
+	return c.Pkg + "." + c.ID
+}
+
+// Line returns the change as a single tab separated line, without a trailing
+// newline, of its position, severity, package qualified ID and message, such
+// as for processing with awk or cut. Newlines and tabs in the message are
+// replaced with spaces, and strict changes are identified as when printed.
+func (c Change) Line() string {
+	severity := c.Change
+	if severity == "" {
+		severity = None
+	}
+	msg := strings.NewReplacer("\n", " ", "\t", " ").Replace(c.Msg)
+	if c.Strict {
+		msg += strictConsumerSuffix
+	}
+	return strings.Join([]string{c.Pos, severity, c.StableID(), msg}, "\t")
+}
+
+// byID implements sort.Interface for []change based on the id field, changes
+// with the same id are sorted by package and then position
 type byID []Change
 
-func (a byID) Len() int           { return len(a) }
-func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a byID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+func (a byID) Len() int      { return len(a) }
+func (a byID) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byID) Less(i, j int) bool {
+	if a[i].ID != a[j].ID {
+		return a[i].ID < a[j].ID
+	}
+	if a[i].Pkg != a[j].Pkg {
+		return a[i].Pkg < a[j].Pkg
+	}
+	if a[i].Pos != a[j].Pos {
+		return a[i].Pos < a[j].Pos
+	}
+	return a[i].Msg < a[j].Msg
+}
+
+// byPosition implements sort.Interface for []Change based on the package,
+// then file and line of the position, then the id.
+type byPosition []Change
+
+func (a byPosition) Len() int      { return len(a) }
+func (a byPosition) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byPosition) Less(i, j int) bool {
+	if a[i].Pkg != a[j].Pkg {
+		return a[i].Pkg < a[j].Pkg
+	}
+	ipos, jpos := parsePos(a[i].Pos), parsePos(a[j].Pos)
+	if ipos.file != jpos.file {
+		return ipos.file < jpos.file
+	}
+	if ipos.line != jpos.line {
+		return ipos.line < jpos.line
+	}
+	return byID(a).Less(i, j)
+}
+
+// bySeverity implements sort.Interface for []Change with breaking changes
+// first, then non-breaking, each sorted by position.
+type bySeverity []Change
+
+func (a bySeverity) Len() int      { return len(a) }
+func (a bySeverity) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a bySeverity) Less(i, j int) bool {
+	ibreaking, jbreaking := a[i].Change == Breaking, a[j].Change == Breaking
+	if ibreaking != jbreaking {
+		return ibreaking
+	}
+	return byPosition(a).Less(i, j)
+}
+
+type byUsage []Change
 
-type diffError struct {
-	err error
-	pkg string
-	bdecl,
-	adecl ast.Decl
+func (a byUsage) Len() int      { return len(a) }
+func (a byUsage) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byUsage) Less(i, j int) bool {
+	ibreaking, jbreaking := a[i].Change == Breaking, a[j].Change == Breaking
+	if ibreaking != jbreaking {
+		return ibreaking
+	}
+	if a[i].Usage != a[j].Usage {
+		return a[i].Usage > a[j].Usage
+	}
+	return byID(a).Less(i, j)
 }
 
-func (e diffError) Error() string {
-	return e.err.Error()
+// sortChanges sorts changes by the Checker's sort order.
+func (c Checker) sortChanges(changes []Change) {
+	switch c.sortOrder {
+	case SortBySeverity:
+		sort.Sort(bySeverity(changes))
+	case SortByPosition:
+		sort.Sort(byPosition(changes))
+	case SortByUsage:
+		sort.Sort(byUsage(changes))
+	default:
+		sort.Sort(byID(changes))
+	}
 }
 
 // compareDecls compares a Checker's before and after declarations and returns
-// all changes or nil and an error
+// all changes or nil and an error, or the changes so far and ErrPartial if the
+// Checker's deadline passed or timeout elapsed, without the changes of the
+// passes after comparing each declaration, see ErrPartial.
 func (c Checker) compareDecls() ([]Change, error) {
-	var changes []Change
+	var (
+		changes []Change
+		removed = make(map[string][]string) // package to removed declaration IDs
+		broken  []pkg                       // after packages which failed to type check
+	)
+	start := time.Now()
+	expired := func() bool {
+		now := time.Now()
+		return !c.deadline.IsZero() && !now.Before(c.deadline) || c.timeout > 0 && now.Sub(start) >= c.timeout
+	}
+	for pkgName, apkg := range c.a {
+		if _, ok := c.b[pkgName]; !ok && apkg.typeErr != nil {
+			// only a package in both revisions can be broken by another's change
+			return nil, apkg.typeErr
+		}
+	}
 	for pkgName, bpkg := range c.b {
+		if bpkg.typeErr != nil {
+			return nil, bpkg.typeErr
+		}
 		apkg, ok := c.a[pkgName]
+		if !ok && bpkg.dep {
+			// dependency is no longer imported, but may still exist
+			continue
+		}
 		if !ok {
-			c := Change{Pkg: pkgName, Change: Breaking, Msg: "package removed"}
+			c := Change{Pkg: pkgName, Change: Breaking, Msg: "package removed", Kind: Removal, Category: categoryDeclarationRemoved}
 			changes = append(changes, c)
 			continue
 		}
+		if apkg.typeErr != nil {
+			broken = append(broken, apkg)
+			continue
+		}
+
+		c := c.withPkgOptions(apkg)
 
 		d := NewDeclChecker(bpkg.info, apkg.info)
+		d.bpkg, d.apkg = bpkg.types, apkg.types
+		d.trackZeroValue = c.trackZeroValue
+		d.trackWire = c.trackWire
+		d.sizes = c.sizes
+		d.conservative = c.conservative
+		d.perspective = c.perspective
+		d.breakingConstValues = c.breakingConstValues
+		d.nonBreakingFieldOrder = c.nonBreakingFieldOrder
+		d.qualifier = c.qualifier
+		d.breakingTagKeys = make(map[string]bool)
+		for _, key := range c.breakingTagKeys {
+			d.breakingTagKeys[key] = true
+		}
 		for id, bDecl := range bpkg.decls {
+			if expired() {
+				return changes, ErrPartial
+			}
+			if !c.isIncluded(id) {
+				continue
+			}
+			highImpact := c.isHighImpact(bDecl)
 			aDecl, ok := apkg.decls[id]
+			if c.isInternalID(bpkg.decls, id) || c.isInternalID(apkg.decls, id) {
+				continue
+			}
 			if !ok {
+				if change, ok := c.provenanceChange(pkgName, id); ok {
+					// now promoted from an embedded type
+					change.Before = bDecl
+					changes = append(changes, change)
+					continue
+				}
 				// in before, not in after, therefore it was removed
-				c := Change{Pkg: pkgName, ID: id, Change: Breaking, Msg: "declaration removed", Pos: pos(bpkg.fset, bDecl.End()), Before: bDecl}
-				changes = append(changes, c)
+				removed[pkgName] = append(removed[pkgName], id)
+				change := breaking("declaration removed", bDecl.End()).withKind(Removal).withCategory(categoryDeclarationRemoved)
+				if highImpact {
+					change.Msg = fmt.Sprintf("driver-entry function %s removed", id)
+				}
+				if change = c.classify(bDecl, nil, change); change.Change == None {
+					continue
+				}
+				changes = append(changes, Change{
+					Pkg:        pkgName,
+					ID:         id,
+					Change:     change.Change,
+					Msg:        change.Msg,
+					Pos:        pos(bpkg.fset, change.Pos),
+					Before:     bDecl,
+					HighImpact: highImpact,
+					Confidence: change.Confidence,
+					Kind:       change.Kind,
+					Category:   change.Category,
+				})
 				continue
 			}
 
 			// in before and in after, check if there's a difference
 			change, err := d.Check(bDecl, aDecl)
 			if err != nil {
-				return nil, &diffError{pkg: pkgName, err: err, bdecl: bDecl, adecl: aDecl}
+				if !c.conservative {
+					return nil, &DiffError{Pkg: pkgName, Before: bDecl, After: aDecl, Err: err, bfset: bpkg.fset, afset: apkg.fset}
+				}
+				// reported at any minimum confidence, as the change may be breaking
+				change = breaking(fmt.Sprintf("could not compare declarations: %s", err), aDecl.Pos())
+			}
+			change = c.classify(bDecl, aDecl, change)
+
+			if c.trackConcurrency {
+				if dchange, ok := c.concurrencyDocChange(bDecl, aDecl); ok {
+					changes = append(changes, Change{
+						Pkg:      pkgName,
+						ID:       id,
+						Change:   dchange.Change,
+						Msg:      dchange.Msg,
+						Pos:      pos(apkg.fset, dchange.Pos),
+						Before:   bDecl,
+						After:    aDecl,
+						Category: dchange.Category,
+					})
+				}
 			}
 
 			if change.Change == None {
@@ -678,27 +1879,184 @@ func (c Checker) compareDecls() ([]Change, error) {
 			}
 
 			changes = append(changes, Change{
-				Pkg:    pkgName,
-				ID:     id,
-				Change: change.Change,
-				Msg:    change.Msg,
-				Pos:    pos(apkg.fset, change.Pos),
-				Before: bDecl,
-				After:  aDecl,
+				Pkg:        pkgName,
+				ID:         id,
+				Change:     change.Change,
+				Msg:        change.Msg,
+				Pos:        pos(apkg.fset, change.Pos),
+				Before:     bDecl,
+				After:      aDecl,
+				HighImpact: highImpact,
+				Confidence: change.Confidence,
+				Kind:       change.Kind,
+				Category:   change.Category,
 			})
 		}
 
 		for id, aDecl := range apkg.decls {
+			if expired() {
+				return changes, ErrPartial
+			}
+			if !c.isIncluded(id) || c.isInternalID(apkg.decls, id) || c.isInternalID(bpkg.decls, id) {
+				continue
+			}
 			if _, ok := bpkg.decls[id]; !ok {
+				if change, ok := c.provenanceChange(pkgName, id); ok {
+					// previously promoted from an embedded type
+					change.After = aDecl
+					changes = append(changes, change)
+					continue
+				}
 				// in after, not in before, therefore it was added
-				c := Change{Pkg: pkgName, ID: id, Change: NonBreaking, Msg: "declaration added", Pos: pos(apkg.fset, aDecl.End()), After: aDecl}
-				changes = append(changes, c)
+				added := nonBreaking("declaration added", aDecl.End()).withKind(Addition).withCategory(categoryDeclarationAdded)
+				if c.frozen && !c.isFrozenAllowed(id) {
+					added = breaking(frozenAddedMsg, aDecl.End()).withKind(Addition).withCategory(categoryDeclarationAdded)
+				}
+				change := c.classify(nil, aDecl, added)
+				if change.Change == None {
+					continue
+				}
+				changes = append(changes, Change{
+					Pkg:        pkgName,
+					ID:         id,
+					Change:     change.Change,
+					Msg:        change.Msg,
+					Pos:        pos(apkg.fset, change.Pos),
+					After:      aDecl,
+					Confidence: change.Confidence,
+					Kind:       change.Kind,
+					Category:   change.Category,
+				})
 			}
 		}
+		changes = correlateRenames(d, pkgName, apkg, changes)
+		changes = correlateConsolidations(d, pkgName, apkg, changes)
+		changes = correlateAddedResults(d, pkgName, apkg, changes)
+	}
+	for _, apkg := range broken {
+		bchanges, err := c.brokenChanges(apkg, removed)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, bchanges...)
 	}
+	changes = append(changes, c.generateChanges(removed)...)
+	changes = append(changes, c.notableChanges()...)
+	changes = append(changes, c.shadowChanges()...)
+	changes = append(changes, c.satisfactionChanges()...)
 	return changes, nil
 }
 
+// withPkgOptions returns a copy of the Checker with the options of the after
+// package's configuration file applied, as they apply to every comparison of
+// the package, see parseConfig.
+func (c Checker) withPkgOptions(apkg pkg) Checker {
+	for _, option := range apkg.options {
+		option(&c)
+	}
+	return c
+}
+
+// classify returns the change between the before and after declarations, as
+// determined by the Checker's classifier, or the default change if unset.
+func (c Checker) classify(before, after ast.Decl, change DeclChange) DeclChange {
+	if c.classifier == nil {
+		return change
+	}
+	return c.classifier(before, after, change)
+}
+
+// isInternal returns true if a line of decl's doc comment starts with the
+// Checker's internal marker.
+func (c Checker) isInternal(decl ast.Decl) bool {
+	if c.internalMarker == "" {
+		return false
+	}
+	for _, doc := range declDocs(decl) {
+		for _, line := range strings.Split(doc.Text(), "\n") {
+			if strings.HasPrefix(line, c.internalMarker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInternalID returns true if the declaration of id, or of the type a method
+// or field id belongs to, is marked as internal, see isInternal.
+func (c Checker) isInternalID(decls map[string]ast.Decl, id string) bool {
+	if decl, ok := decls[id]; ok && c.isInternal(decl) {
+		return true
+	}
+	if i := strings.IndexByte(id, '.'); i >= 0 {
+		return c.isInternalID(decls, id[:i])
+	}
+	return false
+}
+
+// declDocs returns the doc comments of a declaration and its spec.
+func declDocs(decl ast.Decl) []*ast.CommentGroup {
+	var docs []*ast.CommentGroup
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		docs = append(docs, d.Doc)
+	case *ast.GenDecl:
+		docs = append(docs, d.Doc)
+		switch s := d.Specs[0].(type) {
+		case *ast.ValueSpec:
+			docs = append(docs, s.Doc)
+		case *ast.TypeSpec:
+			docs = append(docs, s.Doc)
+		}
+	}
+	return docs
+}
+
+// isIncluded returns true if a declaration's id matches the Checker's include
+// patterns, if any, and none of its exclude patterns.
+func (c Checker) isIncluded(id string) bool {
+	for _, pattern := range c.excludeIDs {
+		if ok, _ := path.Match(pattern, id); ok {
+			return false
+		}
+	}
+	if len(c.includeIDs) == 0 {
+		return true
+	}
+	for _, pattern := range c.includeIDs {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isFrozenAllowed returns true if the declaration ID matches one of the
+// Checker's patterns of declarations which may be added while frozen.
+func (c Checker) isFrozenAllowed(id string) bool {
+	for _, pattern := range c.frozenAllow {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isHighImpact returns true if decl is a package level function matching one
+// of the Checker's high impact patterns.
+func (c Checker) isHighImpact(decl ast.Decl) bool {
+	fdecl, ok := decl.(*ast.FuncDecl)
+	if !ok || fdecl.Recv != nil {
+		return false
+	}
+	for _, re := range c.highImpact {
+		if re.MatchString(fdecl.Name.Name) {
+			return true
+		}
+	}
+	return false
+}
+
 // pos returns the declaration's position within a file.
 func pos(fset *token.FileSet, p token.Pos) string {
 	pos := fset.Position(p)
@@ -3,12 +3,20 @@ package apicompat
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestParse tests the results from the parser against an expected golden master
@@ -74,6 +82,11 @@ func TestPaths(t *testing.T) {
 		t.Fatal(err)
 	}
 	testdataDir := filepath.Join(wd, "testdata")
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatalf("cannot chdir in defer: %s", err)
+		}
+	}()
 
 	cmd := exec.Command("./make.sh")
 	cmd.Dir = testdataDir
@@ -135,3 +148,1608 @@ func TestPaths(t *testing.T) {
 		}
 	}
 }
+
+// checkStrVCS checks the changes between before and after, as the contents of
+// a single file, using a Checker with the given options.
+func checkStrVCS(t *testing.T, before, after string, options ...func(*Checker)) []Change {
+	var vcs StrVCS
+	vcs.SetFile("rev1", "abitest.go", []byte(before))
+	vcs.SetFile("rev2", "abitest.go", []byte(after))
+
+	c := New(append([]func(*Checker){SetVCS(vcs)}, options...)...)
+	changes, err := c.Check("", false, "rev1", "rev2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return changes
+}
+
+// TestHighImpact tests functions matching high impact patterns are marked.
+func TestHighImpact(t *testing.T) {
+	const (
+		before = "package lib\nfunc Register() {}\nfunc NewT() {}\nfunc OpenDB() {}\nfunc Other() {}\n"
+		after  = "package lib\nfunc NewT(int) {}\nfunc OpenDB() {}\n"
+	)
+
+	tests := []struct {
+		patterns []string
+		exp      map[string]bool // change ID to whether it's high impact
+	}{
+		{nil, map[string]bool{"NewT": false, "Other": false, "Register": false}},
+		{defaultHighImpactPatterns, map[string]bool{"NewT": true, "Other": false, "Register": true}},
+		{[]string{"^Oth"}, map[string]bool{"NewT": false, "Other": true, "Register": false}},
+	}
+
+	for _, test := range tests {
+		changes := checkStrVCS(t, before, after, SetHighImpactPatterns(test.patterns))
+		if len(changes) != len(test.exp) {
+			t.Fatalf("patterns: %v exp %d changes got %d", test.patterns, len(test.exp), len(changes))
+		}
+		for _, change := range changes {
+			if change.HighImpact != test.exp[change.ID] {
+				t.Errorf("patterns: %v id: %v exp high impact %v got %v", test.patterns, change.ID, test.exp[change.ID], change.HighImpact)
+			}
+		}
+	}
+
+	changes := checkStrVCS(t, before, after)
+	if changes[2].ID != "Register" || changes[2].Msg != "driver-entry function Register removed" {
+		t.Errorf("unexpected default removal: %#v", changes[2])
+	}
+	if rule := sarifRuleOf(changes[2]); rule.id != "declaration-removed" {
+		t.Errorf("unexpected default removal SARIF rule: %q", rule.id)
+	}
+}
+
+// TestTrackConcurrencyDocs tests changes to doc comments describing
+// concurrency safety are only reported when tracked.
+func TestTrackConcurrencyDocs(t *testing.T) {
+	tests := []struct {
+		before, after string
+		track         bool
+		exp           string // expected change message
+	}{
+		{"// F does things.\n// Safe for concurrent use.\nfunc F() {}", "// F does things.\nfunc F() {}", false, ""},
+		{"// F does things.\n// Safe for concurrent use.\nfunc F() {}", "// F does things.\nfunc F() {}", true, "concurrency documentation removed: \"Safe for concurrent use.\""},
+		{"// F does things.\nfunc F() {}", "// F does things. It is safe to call\n// from multiple goroutines.\nfunc F() {}", true, "concurrency documentation added: \"It is safe to call from multiple goroutines.\""},
+		{"// T is thread-safe.\ntype T struct{}", "// T is not safe for concurrent use.\ntype T struct{}", true, "concurrency documentation changed: \"T is thread-safe.\" → \"T is not safe for concurrent use.\""},
+		{"// F does things.\nfunc F() {}", "// F does other things.\nfunc F() {}", true, ""},
+		{"// V is safe for concurrent use.\nvar V int", "var V int", true, ""},
+	}
+	for _, test := range tests {
+		before := "package lib\n" + test.before + "\n"
+		after := "package lib\n" + test.after + "\n"
+		changes := checkStrVCS(t, before, after, SetTrackConcurrencyDocs(test.track))
+
+		var msg string
+		if len(changes) > 0 {
+			msg = changes[0].Msg
+		}
+		if msg != test.exp {
+			t.Errorf("before: %q after: %q track: %v exp %q got %q", test.before, test.after, test.track, test.exp, msg)
+		}
+	}
+
+	before := "package lib\n// F is thread-safe.\nfunc F() {}\n"
+	after := "package lib\n// F is thread-safe. Honestly.\nfunc F() {}\n"
+	changes := checkStrVCS(t, before, after, SetTrackConcurrencyDocs(true), SetConcurrencyDocPattern("(?i)honestly"))
+	if len(changes) != 1 || changes[0].Msg != "concurrency documentation added: \"Honestly.\"" {
+		t.Errorf("custom pattern: unexpected changes: %v", changes)
+	}
+}
+
+// TestIncludeExcludeIDs tests only declarations matching the include
+// patterns, and not matching the exclude patterns, are compared.
+func TestIncludeExcludeIDs(t *testing.T) {
+	const (
+		before = "package lib\ntype Server struct{}\nfunc (Server) Close() {}\nfunc (Server) Serve(int) {}\ntype ServerConfig int\ntype ClientConfig int\nfunc Removed() {}\n"
+		after  = "package lib\ntype Server struct{}\nfunc (Server) Serve(uint) {}\ntype ServerConfig uint\ntype ClientConfig uint\nfunc Added(int) {}\n"
+	)
+	tests := []struct {
+		include, exclude []string
+		exp              []string // IDs of changes
+	}{
+		{nil, nil, []string{"Added", "ClientConfig", "Removed", "Server.Close", "Server.Serve", "ServerConfig"}},
+		{[]string{"Server.*"}, nil, []string{"Server.Close", "Server.Serve"}},
+		{[]string{"*Config"}, nil, []string{"ClientConfig", "ServerConfig"}},
+		{[]string{"Server*"}, nil, []string{"Server.Close", "Server.Serve", "ServerConfig"}},
+		{nil, []string{"Server*"}, []string{"Added", "ClientConfig", "Removed"}},
+		{[]string{"Server*"}, []string{"*Config"}, []string{"Server.Close", "Server.Serve"}},
+		{[]string{"Server.Close"}, []string{"Server.*"}, nil},
+	}
+	for _, test := range tests {
+		changes := checkStrVCS(t, before, after, SetIncludeIDs(test.include), SetExcludeIDs(test.exclude))
+
+		var ids []string
+		for _, change := range changes {
+			ids = append(ids, change.ID)
+		}
+		if !reflect.DeepEqual(ids, test.exp) {
+			t.Errorf("include: %q exclude: %q exp %v got %v", test.include, test.exclude, test.exp, ids)
+		}
+	}
+}
+
+// TestFrozen tests added declarations are breaking while frozen, unless
+// allowed.
+func TestFrozen(t *testing.T) {
+	const (
+		before = "package lib\ntype Server struct{}\n"
+		after  = "package lib\ntype Server struct{ A int }\nfunc (Server) Close() {}\nfunc NewServer() {}\nfunc Debug() {}\n"
+	)
+	tests := []struct {
+		frozen bool
+		allow  []string
+		exp    []string // change ID and change
+	}{
+		{false, nil, []string{
+			"Debug non-breaking change", "NewServer non-breaking change", "Server non-breaking change", "Server.Close non-breaking change",
+		}},
+		{true, nil, []string{
+			"Debug breaking change", "NewServer breaking change", "Server non-breaking change", "Server.Close breaking change",
+		}},
+		{true, []string{"Server.*", "New*"}, []string{
+			"Debug breaking change", "NewServer non-breaking change", "Server non-breaking change", "Server.Close non-breaking change",
+		}},
+		{false, []string{"Server.*"}, []string{
+			"Debug non-breaking change", "NewServer non-breaking change", "Server non-breaking change", "Server.Close non-breaking change",
+		}},
+	}
+	for _, test := range tests {
+		changes := checkStrVCS(t, before, after, SetFrozen(test.frozen), SetFrozenAllow(test.allow))
+
+		var got []string
+		for _, change := range changes {
+			got = append(got, change.ID+" "+change.Change)
+			if change.Change == Breaking && change.Msg != frozenAddedMsg {
+				t.Errorf("frozen: %v allow: %q unexpected message: %q", test.frozen, test.allow, change.Msg)
+			}
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("frozen: %v allow: %q\nexp: %q\ngot: %q", test.frozen, test.allow, test.exp, got)
+		}
+	}
+}
+
+// TestStrictConsumer tests additions and non-breaking changes are elevated to
+// breaking for strict consumers, and breaking changes are unaffected.
+func TestStrictConsumer(t *testing.T) {
+	const (
+		before = "package lib\ntype Server struct{ A int }\nfunc Close(int) {}\nconst Limit = 1\n"
+		after  = "package lib\ntype Server struct{ A, B int }\nfunc NewServer() {}\nconst Limit = 2\n"
+	)
+	tests := []struct {
+		strict bool
+		exp    []string // change ID, change, message and whether it's strict
+	}{
+		{false, []string{
+			"Close breaking change declaration removed false",
+			"Limit non-breaking change constant Limit changed value 1 → 2 false",
+			"NewServer non-breaking change declaration added false",
+			"Server non-breaking change members added false",
+		}},
+		{true, []string{
+			"Close breaking change declaration removed false",
+			"Limit breaking change constant Limit changed value 1 → 2 true",
+			"NewServer breaking change declaration added true",
+			"Server breaking change members added true",
+		}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, change := range checkStrVCS(t, before, after, SetStrictConsumer(test.strict)) {
+			got = append(got, fmt.Sprintf("%s %s %s %v", change.ID, change.Change, change.Msg, change.Strict))
+
+			// elevated changes are identified when printed
+			line := strings.SplitN(change.String(), "\n", 2)[0]
+			if strings.HasSuffix(line, strictConsumerSuffix) != change.Strict {
+				t.Errorf("strict: %v unexpected printed change: %q", change.Strict, line)
+			}
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("strict: %v\nexp: %q\ngot: %q", test.strict, test.exp, got)
+		}
+	}
+}
+
+// TestDeadline tests the changes found before the deadline passes are returned
+// with ErrPartial.
+func TestDeadline(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev1", "abitest.go", []byte("package lib\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\n"))
+
+	changes, err := New(SetVCS(vcs), SetDeadline(time.Now())).Check("", false, "rev1", "rev2")
+	if err != ErrPartial {
+		t.Errorf("immediate deadline: exp ErrPartial got %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("immediate deadline: exp no changes got %v", changes)
+	}
+
+	// the deadline passes while classifying the first declaration
+	deadline := time.Now().Add(10 * time.Millisecond)
+	classifier := func(before, after ast.Decl, change DeclChange) DeclChange {
+		time.Sleep(time.Until(deadline))
+		return change
+	}
+	changes, err = New(SetVCS(vcs), SetDeadline(deadline), SetClassifier(classifier)).Check("", false, "rev1", "rev2")
+	if err != ErrPartial {
+		t.Errorf("passed deadline: exp ErrPartial got %v", err)
+	}
+	if len(changes) != 1 || changes[0].Msg != "declaration removed" {
+		t.Errorf("passed deadline: exp 1 change got %v", changes)
+	}
+
+	changes, err = New(SetVCS(vcs), SetDeadline(time.Now().Add(time.Hour))).Check("", false, "rev1", "rev2")
+	if err != nil {
+		t.Errorf("future deadline: unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("future deadline: exp 3 changes got %v", changes)
+	}
+}
+
+// slowVCS is a VCS which is slow to read each directory.
+type slowVCS struct {
+	StrVCS
+	delay time.Duration
+}
+
+// ReadDir implements VCS.ReadDir
+func (v slowVCS) ReadDir(revision, path string) ([]os.FileInfo, error) {
+	time.Sleep(v.delay)
+	return v.StrVCS.ReadDir(revision, path)
+}
+
+// TestTimeout tests the timeout starts once the revisions are parsed, and the
+// changes found before it elapses are returned with ErrPartial.
+func TestTimeout(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev1", "abitest.go", []byte("package lib\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\n"))
+
+	const timeout = 20 * time.Millisecond
+	changes, err := New(SetVCS(slowVCS{vcs, 2 * timeout}), SetTimeout(timeout)).Check("", false, "rev1", "rev2")
+	if err != nil {
+		t.Errorf("slow parse: unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("slow parse: exp 3 changes got %v", changes)
+	}
+
+	// the timeout elapses while classifying the first declaration
+	classifier := func(before, after ast.Decl, change DeclChange) DeclChange {
+		time.Sleep(timeout)
+		return change
+	}
+	changes, err = New(SetVCS(vcs), SetTimeout(timeout), SetClassifier(classifier)).Check("", false, "rev1", "rev2")
+	if err != ErrPartial {
+		t.Errorf("elapsed timeout: exp ErrPartial got %v", err)
+	}
+	if len(changes) != 1 || changes[0].Msg != "declaration removed" {
+		t.Errorf("elapsed timeout: exp 1 change got %v", changes)
+	}
+}
+
+// TestDeadlineEntryPoints tests each method comparing revisions returns the
+// changes found so far with ErrPartial once the deadline passes.
+func TestDeadlineEntryPoints(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev1", "abitest.go", []byte("package lib\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\n"))
+
+	baseline, err := New(SetVCS(vcs)).ExportBaseline("", false, "rev1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newChecker returns a Checker whose deadline passes while classifying
+	// the first declaration
+	newChecker := func() *Checker {
+		deadline := time.Now().Add(10 * time.Millisecond)
+		classifier := func(before, after ast.Decl, change DeclChange) DeclChange {
+			time.Sleep(time.Until(deadline))
+			return change
+		}
+		return New(SetVCS(vcs), SetDeadline(deadline), SetClassifier(classifier))
+	}
+
+	tests := []struct {
+		name  string
+		check func() ([]Change, error)
+	}{
+		{"Check", func() ([]Change, error) {
+			return newChecker().Check("", false, "rev1", "rev2")
+		}},
+		{"CheckMany", func() ([]Change, error) {
+			many, err := newChecker().CheckMany("", false, "rev2", []string{"rev1", "rev1"})
+			if len(many) != 1 {
+				t.Errorf("CheckMany: exp changes of 1 revision got %v", many)
+			}
+			return many["rev1"], err
+		}},
+		{"CheckBidirectional", func() ([]Change, error) {
+			forward, backward, err := newChecker().CheckBidirectional("", false, "rev1", "rev2")
+			if backward != nil {
+				t.Errorf("CheckBidirectional: exp no backward changes got %v", backward)
+			}
+			return forward, err
+		}},
+		{"CheckNew", func() ([]Change, error) {
+			return newChecker().CheckNew("", false, "rev1")
+		}},
+		{"CheckAgainstBaseline", func() ([]Change, error) {
+			return newChecker().CheckAgainstBaseline("", false, "rev2", baseline)
+		}},
+	}
+	for _, test := range tests {
+		changes, err := test.check()
+		if err != ErrPartial {
+			t.Errorf("%s: exp ErrPartial got %v", test.name, err)
+		}
+		if len(changes) != 1 {
+			t.Errorf("%s: exp 1 change got %v", test.name, changes)
+		}
+	}
+}
+
+// TestClassifier tests a classifier can override the default change of each
+// declaration.
+func TestClassifier(t *testing.T) {
+	const (
+		before = "package lib\ntype S struct{ A int }\nfunc F(int) {}\n"
+		after  = "package lib\ntype S struct{ A, B int }\nfunc F(uint) {}\nfunc Added(int) {}\n"
+	)
+
+	var added []ast.Decl
+	classifier := func(before, after ast.Decl, change DeclChange) DeclChange {
+		switch {
+		case before == nil:
+			added = append(added, after)
+		case change.Msg == "members added":
+			// treat struct members being added as breaking
+			change.Change = Breaking
+		case change.Change == Breaking:
+			// omit other breaking changes
+			change.Change = None
+		}
+		return change
+	}
+
+	changes := checkStrVCS(t, before, after, SetClassifier(classifier))
+	if len(changes) != 2 {
+		t.Fatalf("exp 2 changes got %d: %v", len(changes), changes)
+	}
+	if changes[0].ID != "Added" || changes[0].Change != NonBreaking {
+		t.Errorf("unexpected change: %#v", changes[0])
+	}
+	if changes[1].ID != "S" || changes[1].Change != Breaking || changes[1].Msg != "members added" {
+		t.Errorf("unexpected change: %#v", changes[1])
+	}
+	if len(added) != 1 || added[0].(*ast.FuncDecl).Name.Name != "Added" {
+		t.Errorf("exp classifier called with added declaration Added, got: %v", added)
+	}
+}
+
+// TestBreakingTagKeys tests struct tag keys removed from a field are breaking
+// only when one of the breaking keys.
+func TestBreakingTagKeys(t *testing.T) {
+	tests := []struct {
+		before, after string
+		keys          []string
+		exp           Change // expected Change and Msg
+	}{
+		{"struct{ A int `db:\"a\"` }", "struct{ A int }", defaultBreakingTagKeys, Change{Change: Breaking, Msg: "struct tags removed: field A: db:\"a\""}},
+		{"struct{ A int `db:\"a\"` }", "struct{ A int }", []string{"json"}, Change{Change: NonBreaking, Msg: "struct tags removed: field A: db:\"a\""}},
+		{"struct{ A int `yaml:\"a\" custom:\"b\"` }", "struct{ A int `yaml:\"a\"` }", defaultBreakingTagKeys, Change{Change: NonBreaking, Msg: "struct tags removed: field A: custom:\"b\""}},
+		{"struct{ A int `yaml:\"a\" custom:\"b\"` }", "struct{ A int }", []string{"custom"}, Change{Change: Breaking, Msg: "struct tags removed: field A: custom:\"b\"; field A: yaml:\"a\""}},
+		{"struct{ A int `db:\"a\"` }", "struct{ A int `db:\"b\"` }", defaultBreakingTagKeys, Change{}},
+		{"struct{ A int }", "struct{ A int `db:\"a\"` }", defaultBreakingTagKeys, Change{}},
+	}
+	for _, test := range tests {
+		before := "package lib\ntype T " + test.before + "\n"
+		after := "package lib\ntype T " + test.after + "\n"
+		changes := checkStrVCS(t, before, after, SetBreakingTagKeys(test.keys))
+
+		var got Change
+		if len(changes) > 0 {
+			got = Change{Change: changes[0].Change, Msg: changes[0].Msg}
+		}
+		if got != test.exp {
+			t.Errorf("before: %q after: %q keys: %v exp %q %q got %q %q", test.before, test.after, test.keys, test.exp.Change, test.exp.Msg, got.Change, got.Msg)
+		}
+	}
+}
+
+// TestInternalMarker tests declarations marked as internal in their doc
+// comment, at either revision, are ignored.
+func TestInternalMarker(t *testing.T) {
+	const (
+		before = "package lib\n// F does things.\n//\n// Internal: not part of the public API.\nfunc F(int) {}\nconst (\n\t// Internal: C is for tests.\n\tC = 1\n)\nfunc G(int) {}\n// Internal: H will be removed.\nfunc H() {}\n"
+		after  = "package lib\n// F does things.\n//\n// Internal: not part of the public API.\nfunc F(uint) {}\nconst (\n\t// Internal: C is for tests.\n\tC = \"\"\n)\n// Internal: G is now internal.\nfunc G(uint) {}\n// Internal: I is new.\nfunc I() {}\n"
+	)
+
+	changes := checkStrVCS(t, before, after, SetInternalMarker("Internal:"))
+	if len(changes) != 0 {
+		t.Errorf("exp no changes got %d: %v", len(changes), changes)
+	}
+
+	changes = checkStrVCS(t, before, after)
+	if len(changes) != 4 {
+		t.Errorf("exp 4 changes without a marker got %d: %v", len(changes), changes)
+	}
+}
+
+// TestInternalMarkerMembers tests the fields and methods of a type marked as
+// internal are ignored by every check, not just the declaration's.
+func TestInternalMarkerMembers(t *testing.T) {
+	const (
+		before = "package lib\ntype E struct{ F int }\n// Internal: T is for tests.\ntype T struct{ E }\nfunc (T) M(int) {}\nfunc (T) Close() error { return nil }\ntype Closer interface{ Close() error }\n"
+		after  = "package lib\ntype E struct{ F int }\n// Internal: T is for tests.\ntype T struct {\n\tE\n\tF string\n}\nfunc (T) M(uint) {}\nfunc (*T) Close() error { return nil }\nfunc (T) String() string { return \"\" }\ntype Closer interface{ Close() error }\n"
+	)
+
+	changes := checkStrVCS(t, before, after, SetInternalMarker("Internal:"))
+	if len(changes) != 0 {
+		t.Errorf("exp no changes got %d: %v", len(changes), changes)
+	}
+
+	changes = checkStrVCS(t, before, after)
+	msgs := make(map[string]bool)
+	for _, change := range changes {
+		msgs[change.ID+": "+change.Msg] = true
+	}
+	for _, exp := range []string{
+		"T.M: parameter types changed",
+		"T.F: field F shadows field promoted from embedded E",
+		"T: now implements fmt.Stringer, which may change runtime behaviour",
+		"T: T no longer implements Closer, only *T does",
+	} {
+		if !msgs[exp] {
+			t.Errorf("exp change %q without a marker got %v", exp, changes)
+		}
+	}
+}
+
+// TestNotableInterfaces tests existing types newly satisfying a notable
+// interface are reported, by value or pointer, but not when already satisfied.
+func TestNotableInterfaces(t *testing.T) {
+	const (
+		before = "package lib\ntype S struct{}\ntype E struct{}\ntype A int\nfunc (A) Error() string { return \"\" }\n"
+		after  = "package lib\ntype S struct{}\nfunc (S) String() string { return \"\" }\ntype E struct{}\nfunc (*E) Error() string { return \"\" }\ntype A int\nfunc (A) Error() string { return \"\" }\nfunc (A) Other() {}\n"
+	)
+
+	tests := []struct {
+		ifaces map[string]*types.Interface
+		exp    map[string]string // change ID to notable message
+	}{
+		{nil, map[string]string{}},
+		{defaultNotableInterfaces, map[string]string{
+			"E": "now implements error, which may change runtime behaviour",
+			"S": "now implements fmt.Stringer, which may change runtime behaviour",
+		}},
+		{map[string]*types.Interface{"other": newInterface("Other", nil)}, map[string]string{
+			"A": "now implements other, which may change runtime behaviour",
+		}},
+	}
+
+	for _, test := range tests {
+		got := make(map[string]string)
+		for _, change := range checkStrVCS(t, before, after, SetNotableInterfaces(test.ifaces)) {
+			if !strings.Contains(change.ID, ".") {
+				got[change.ID] = change.Msg
+			}
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("ifaces: %v\nexp: %v\ngot: %v", test.ifaces, test.exp, got)
+		}
+	}
+}
+
+// TestAPIFiles tests changes to declarations outside of the API files are
+// ignored when API files are set.
+func TestAPIFiles(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev1", "api.go", []byte("package lib\nfunc API(a int) {}\n"))
+	vcs.SetFile("rev1", "internal.go", []byte("package lib\nfunc Internal(a int) {}\n"))
+	vcs.SetFile("rev2", "api.go", []byte("package lib\nfunc API(a uint) {}\n"))
+	vcs.SetFile("rev2", "internal.go", []byte("package lib\nfunc Internal(a uint) {}\n"))
+
+	tests := []struct {
+		apiFiles []string
+		exp      []string // IDs of changes
+	}{
+		{nil, []string{"API", "Internal"}},
+		{[]string{"api.go"}, []string{"API"}},
+		{[]string{"other.go"}, nil},
+	}
+	for _, test := range tests {
+		changes, err := New(SetVCS(vcs), SetAPIFiles(test.apiFiles)).Check("", false, "rev1", "rev2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, change := range changes {
+			ids = append(ids, change.ID)
+		}
+		if !reflect.DeepEqual(ids, test.exp) {
+			t.Errorf("api files: %v exp %v got %v", test.apiFiles, test.exp, ids)
+		}
+	}
+}
+
+// TestParseComments tests doc comments are kept in a change's declarations
+// only when parsing comments.
+func TestParseComments(t *testing.T) {
+	const (
+		before = "package lib\n// V doc.\nvar V int\n// T doc.\ntype T int\nconst (\n\t// C doc.\n\tC = 1\n)\n// F doc.\nfunc F() {}\n"
+		after  = "package lib\n// V doc.\nvar V uint\n// T doc.\ntype T uint\nconst (\n\t// C doc.\n\tC = \"\"\n)\n// F doc.\nfunc F(int) {}\n"
+	)
+
+	// doc returns the doc comment of a change's before declaration
+	doc := func(decl ast.Decl) string {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			return d.Doc.Text()
+		case *ast.GenDecl:
+			switch s := d.Specs[0].(type) {
+			case *ast.ValueSpec:
+				if s.Doc != nil {
+					return s.Doc.Text()
+				}
+			case *ast.TypeSpec:
+				if s.Doc != nil {
+					return s.Doc.Text()
+				}
+			}
+			return d.Doc.Text()
+		}
+		return ""
+	}
+
+	for _, parse := range []bool{false, true} {
+		changes := checkStrVCS(t, before, after, SetParseComments(parse))
+		if len(changes) != 4 {
+			t.Fatalf("parse: %v exp 4 changes got %d: %v", parse, len(changes), changes)
+		}
+		for _, change := range changes {
+			exp := ""
+			if parse {
+				exp = change.ID + " doc.\n"
+			}
+			if got := doc(change.Before); got != exp {
+				t.Errorf("parse: %v id: %v exp doc %q got %q", parse, change.ID, exp, got)
+			}
+		}
+	}
+}
+
+// TestRenames tests a removed function is only reported as renamed when it
+// has the same signature as exactly one added function.
+func TestRenames(t *testing.T) {
+	tests := []struct {
+		before, after string
+		exp           []string // messages of changes
+	}{
+		{
+			"func Old(a int) error { return nil }",
+			"func New(b int) error { return nil }",
+			[]string{"function Old likely renamed to New, callers should use New"},
+		},
+		{
+			"func Old(a int) error { return nil }",
+			"func New(a uint) error { return nil }",
+			[]string{"declaration added", "declaration removed"},
+		},
+		{
+			"func Old(a int) {}",
+			"func New1(a int) {}\nfunc New2(a int) {}",
+			[]string{"declaration added", "declaration added", "declaration removed"},
+		},
+		{
+			"type T int\nfunc (T) Old() {}",
+			"type T int\nfunc (T) New() {}",
+			[]string{"declaration added", "declaration removed"},
+		},
+	}
+	for _, test := range tests {
+		changes := checkStrVCS(t, "package lib\n"+test.before+"\n", "package lib\n"+test.after+"\n", SetHighImpactPatterns(nil))
+		var msgs []string
+		for _, change := range changes {
+			msgs = append(msgs, change.Msg)
+		}
+		if !reflect.DeepEqual(msgs, test.exp) {
+			t.Errorf("before: %q after: %q\nexp: %q\ngot: %q", test.before, test.after, test.exp, msgs)
+		}
+	}
+}
+
+// TestCorrelatedKind tests renamed and consolidated functions are reported as
+// removals, as the removed functions' changes were.
+func TestCorrelatedKind(t *testing.T) {
+	const (
+		before = "package lib\nfunc Old(a int) {}\nfunc MaxInt(a, b int) int { return a }\nfunc MaxFloat64(a, b float64) float64 { return a }\n"
+		after  = "package lib\nfunc New(b int) {}\nfunc Max[T int | float64](a, b T) T { return a }\n"
+	)
+	changes := checkStrVCS(t, before, after, SetHighImpactPatterns(nil))
+	if len(changes) != 2 {
+		t.Fatalf("exp 2 changes got %d: %v", len(changes), changes)
+	}
+	for _, change := range changes {
+		if change.Kind != Removal {
+			t.Errorf("%v: exp kind %v got %v", change.ID, Removal, change.Kind)
+		}
+	}
+}
+
+// TestConfidence tests structural changes have a high confidence, heuristics
+// a lower confidence, and changes below the minimum confidence are excluded.
+func TestConfidence(t *testing.T) {
+	const (
+		before = "package lib\nfunc Gone(a string) {}\nfunc Old(a int) error { return nil }\n"
+		after  = "package lib\nfunc New(a int) error { return nil }\n"
+	)
+
+	tests := []struct {
+		min Confidence
+		exp map[string]Confidence // change ID to confidence
+	}{
+		{Low, map[string]Confidence{"Gone": High, "Old": Medium}},
+		{Medium, map[string]Confidence{"Gone": High, "Old": Medium}},
+		{High, map[string]Confidence{"Gone": High}},
+	}
+	for _, test := range tests {
+		got := make(map[string]Confidence)
+		for _, change := range checkStrVCS(t, before, after, SetHighImpactPatterns(nil), SetMinConfidence(test.min)) {
+			got[change.ID] = change.Confidence
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("min: %v exp %v got %v", test.min, test.exp, got)
+		}
+	}
+}
+
+// TestConservativeConfidence tests declarations which could not be compared
+// are reported as breaking when conservative, even at the highest minimum
+// confidence.
+func TestConservativeConfidence(t *testing.T) {
+	c := New(SetConservative(true), SetMinConfidence(High))
+	newPkg := func() map[string]pkg {
+		// a declaration the DeclChecker can't compare
+		decls := map[string]ast.Decl{"X": &ast.BadDecl{}}
+		return map[string]pkg{"lib": {importPath: "lib", fset: token.NewFileSet(), decls: decls}}
+	}
+	c.b, c.a = newPkg(), newPkg()
+
+	changes, err := c.compare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Change != Breaking || !strings.HasPrefix(changes[0].Msg, "could not compare declarations") {
+		t.Errorf("exp X could not be compared got %v", changes)
+	}
+}
+
+// makeGOPATH creates a temporary GOPATH containing a git repository at
+// src/root, with a commit for each of revs. Each rev maps a file's path,
+// relative to root, to its contents. The returned directory should be removed
+// by the caller.
+func makeGOPATH(t *testing.T, root string, revs ...map[string]string) string {
+	gopath, err := ioutil.TempDir("", "apicompat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(gopath, "src", root)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("error running git %v: %v output: %s", args, err, out)
+		}
+	}
+	git("init")
+	git("config", "--local", "user.name", "testdata")
+	git("config", "--local", "user.email", "testdata@example.com")
+
+	for i, files := range revs {
+		for path, contents := range files {
+			path = filepath.Join(dir, path)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git("add", ".")
+		git("commit", "-m", fmt.Sprintf("commit %d", i+1))
+	}
+	return gopath
+}
+
+// chdirGOPATH sets GOPATH and changes the working directory to wd, relative
+// to GOPATH's src directory, returning a function to restore the previous
+// environment.
+func chdirGOPATH(t *testing.T, gopath, wd string) func() {
+	oldPath := os.Getenv("GOPATH")
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("GOPATH", gopath); err != nil {
+		t.Fatalf("cannot setenv: %s", err)
+	}
+	if err := os.Chdir(filepath.Join(gopath, "src", wd)); err != nil {
+		t.Fatalf("cannot chdir: %s", err)
+	}
+	return func() {
+		if err := os.Setenv("GOPATH", oldPath); err != nil {
+			t.Fatalf("cannot setenv: %s", err)
+		}
+		if err := os.Chdir(oldWd); err != nil {
+			t.Fatalf("cannot chdir: %s", err)
+		}
+	}
+}
+
+// TestFollowDeps tests that a change to a type in a dependency within the same
+// module is detected when following dependencies.
+func TestFollowDeps(t *testing.T) {
+	const lib = "package lib\n\nimport \"example.com/mod/dep\"\n\ntype S struct{ F dep.T }\n"
+	gopath := makeGOPATH(t, "example.com/mod",
+		map[string]string{
+			"go.mod":     "module example.com/mod\n",
+			"lib/lib.go": lib,
+			"dep/dep.go": "package dep\n\ntype T struct{ A int }\n",
+		},
+		map[string]string{
+			"dep/dep.go": "package dep\n\ntype T struct{ A uint }\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/mod/lib")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := New(SetVCS(git), SetFollowDeps(true))
+	changes, err := checker.Check(".", false, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("exp 1 change got %d: %v", len(changes), changes)
+	}
+	if changes[0].Pkg != "example.com/mod/dep" || changes[0].ID != "T" || changes[0].Change != Breaking {
+		t.Errorf("unexpected change: %#v", changes[0])
+	}
+}
+
+// TestReexports tests a change to a type in an internal package is also
+// reported as a change to its re-export, or only annotated when collapsed.
+func TestReexports(t *testing.T) {
+	const lib = "package lib\n\nimport \"example.com/mod/internal/foo\"\n\ntype Foo = foo.Foo\n"
+	gopath := makeGOPATH(t, "example.com/mod",
+		map[string]string{
+			"go.mod":              "module example.com/mod\n",
+			"lib/lib.go":          lib,
+			"internal/foo/foo.go": "package foo\n\ntype Foo struct{ A int }\n\nfunc (Foo) M() {}\n",
+		},
+		map[string]string{
+			"internal/foo/foo.go": "package foo\n\ntype Foo struct{ A uint }\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/mod/lib")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		collapse bool
+		exp      []string // expected changes as pkg, ID, message and origin
+		removals int      // expected changes which are removals
+	}{
+		{false, []string{
+			"example.com/mod/internal/foo Foo: members changed types: field A: int → uint ",
+			"example.com/mod/lib Foo: members changed types: field A: int → uint (re-export of example.com/mod/internal/foo.Foo) example.com/mod/internal/foo.Foo",
+			"example.com/mod/internal/foo Foo.M: declaration removed ",
+			"example.com/mod/lib Foo.M: declaration removed (re-export of example.com/mod/internal/foo.Foo.M) example.com/mod/internal/foo.Foo.M",
+		}, 2},
+		{true, []string{
+			"example.com/mod/internal/foo Foo: members changed types: field A: int → uint (re-exported as example.com/mod/lib.Foo) ",
+			"example.com/mod/internal/foo Foo.M: declaration removed (re-exported as example.com/mod/lib.Foo) ",
+		}, 1},
+	}
+	for _, test := range tests {
+		checker := New(SetVCS(git), SetFollowDeps(true), SetCollapseReexports(test.collapse))
+		changes, err := checker.Check(".", false, "HEAD~1", "HEAD")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, change := range changes {
+			got = append(got, fmt.Sprintf("%s %s: %s %s", change.Pkg, change.ID, change.Msg, change.Origin))
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("collapse: %v\nexp: %q\ngot: %q", test.collapse, test.exp, got)
+		}
+		if removals := Removals(changes); len(removals) != test.removals {
+			t.Errorf("collapse: %v exp %d removals got %d: %v", test.collapse, test.removals, len(removals), removals)
+		}
+	}
+}
+
+// TestBrokenPackage tests a package failing to type check, because of a
+// declaration removed from another checked package, is reported as a change
+// instead of an error.
+func TestBrokenPackage(t *testing.T) {
+	const user = "package user\n\nimport \"example.com/mod/a\"\n\nvar V a.Foo\n"
+	for _, user := range []string{
+		user,
+		// the removed declaration's package is imported with another name
+		"package user\n\nimport b \"example.com/mod/a\"\n\nvar V b.Foo\n",
+	} {
+		gopath := makeGOPATH(t, "example.com/mod",
+			map[string]string{
+				"go.mod":       "module example.com/mod\n",
+				"a/a.go":       "package a\n\ntype Foo int\n\ntype Bar int\n",
+				"user/user.go": user,
+			},
+			map[string]string{
+				"a/a.go": "package a\n\ntype Bar int\n",
+			},
+		)
+		defer os.RemoveAll(gopath)
+		defer chdirGOPATH(t, gopath, "example.com/mod")()
+
+		git, err := NewGit(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes, err := New(SetVCS(git), SetFollowDeps(true)).Check(".", true, "HEAD~1", "HEAD")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		exp := []string{
+			"a.Foo removed, breaks package example.com/mod/user",
+			"declaration removed",
+		}
+		if len(changes) != len(exp) {
+			t.Fatalf("exp %d changes got %d: %v", len(exp), len(changes), changes)
+		}
+		for i, msg := range exp {
+			if changes[i].Pkg != "example.com/mod/a" || changes[i].ID != "Foo" || changes[i].Change != Breaking || changes[i].Msg != msg {
+				t.Errorf("unexpected change: %#v", changes[i])
+			}
+		}
+		if changes[0].Pos != "HEAD:user/user.go:5" {
+			t.Errorf("unexpected position of broken package: %q", changes[0].Pos)
+		}
+	}
+
+	// an unrelated type error is still returned, whether or not the package
+	// exists at the before revision
+	for _, before := range []map[string]string{
+		{"go.mod": "module example.com/mod\n", "user/user.go": user},
+		{"go.mod": "module example.com/mod\n", "lib/lib.go": "package lib\n"},
+	} {
+		gopath := makeGOPATH(t, "example.com/mod",
+			before,
+			map[string]string{"user/user.go": "package user\n\nvar W undefined\n"},
+		)
+		defer os.RemoveAll(gopath)
+		defer chdirGOPATH(t, gopath, "example.com/mod")()
+
+		git, err := NewGit(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(SetVCS(git), SetFollowDeps(true)).Check(".", true, "HEAD~1", "HEAD"); err == nil {
+			t.Errorf("before: %v expected type error", before)
+		}
+	}
+}
+
+// openCountingVCS counts the files opened with each base name.
+type openCountingVCS struct {
+	VCS
+	opens map[string]int // file name -> times opened
+}
+
+// OpenFile implements VCS.OpenFile
+func (v openCountingVCS) OpenFile(revision, path string) (io.ReadCloser, error) {
+	v.opens[filepath.Base(path)]++
+	return v.VCS.OpenFile(revision, path)
+}
+
+// TestFollowDepsRecursed tests a dependency which is also a recursed package
+// is only parsed once, whether it's recursed into before or after it's imported.
+func TestFollowDepsRecursed(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/mod",
+		map[string]string{
+			"go.mod":     "module example.com/mod\n",
+			"a/a.go":     "package a\n\nimport \"example.com/mod/z\"\n\ntype S struct{ F z.T }\n",
+			"z/z.go":     "package z\n\ntype T struct{ A int }\n",
+			"z/other.go": "package z\n",
+			"zz/zz.go":   "package zz\n\nimport \"example.com/mod/z\"\n\nvar V z.T\n",
+		},
+		map[string]string{
+			"z/z.go": "package z\n\ntype T struct{ A uint }\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/mod")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the number of times z.go is opened when parsing package z once
+	vcs := openCountingVCS{VCS: git, opens: make(map[string]int)}
+	if _, err := New(SetVCS(vcs)).Check("z", false, "HEAD~1", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	once := vcs.opens["z.go"]
+
+	vcs.opens = make(map[string]int)
+	changes, err := New(SetVCS(vcs), SetFollowDeps(true)).Check(".", true, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opens := vcs.opens["z.go"]; opens != once {
+		t.Errorf("exp z.go opened %d times, as when parsed once, got %d", once, opens)
+	}
+	if len(changes) != 1 || changes[0].Pkg != "example.com/mod/z" || changes[0].ID != "T" {
+		t.Errorf("exp 1 change to example.com/mod/z.T got %d: %v", len(changes), changes)
+	}
+}
+
+// infiniteVCS is a StrVCS where huge.go is a valid, but infinitely long, file.
+type infiniteVCS struct {
+	StrVCS
+}
+
+// OpenFile implements VCS.OpenFile
+func (v infiniteVCS) OpenFile(revision, path string) (io.ReadCloser, error) {
+	if filepath.Base(path) != "huge.go" {
+		return v.StrVCS.OpenFile(revision, path)
+	}
+	return ioutil.NopCloser(io.MultiReader(
+		strings.NewReader("package lib\n\nvar Huge int\n"),
+		infiniteReader{},
+	)), nil
+}
+
+// infiniteReader reads comments forever.
+type infiniteReader struct{}
+
+func (infiniteReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = "// x\n"[i%5]
+	}
+	return len(p), nil
+}
+
+// TestMaxFileSize tests files exceeding the maximum size are skipped without
+// being read entirely.
+func TestMaxFileSize(t *testing.T) {
+	var vcs infiniteVCS
+	for _, rev := range []string{"rev1", "rev2"} {
+		vcs.SetFile(rev, "huge.go", nil)
+	}
+	vcs.SetFile("rev1", "abitest.go", []byte("package lib\nfunc F(int) {}\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\nfunc F(uint) {}\n"))
+
+	var vlog bytes.Buffer
+	changes, err := New(SetVCS(vcs), SetVLog(&vlog), SetMaxFileSize(1<<20)).Check("", false, "rev1", "rev2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].ID != "F" {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if !strings.Contains(vlog.String(), "Skipping file: huge.go exceeds maximum size of 1048576 bytes") {
+		t.Errorf("expected skipped file to be logged, got: %s", vlog.String())
+	}
+}
+
+// TestCheckNew tests each exported declaration is reported as added when
+// checking against an empty before revision.
+func TestCheckNew(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\nconst C = 1\nvar V int\ntype T struct{ A int }\nfunc (T) M() {}\nfunc F() {}\nfunc unexported() {}\n"))
+
+	changes, err := New(SetVCS(vcs)).CheckNew("", false, "rev2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, change := range changes {
+		if change.Change != NonBreaking || change.Msg != "declaration added" || change.After == nil {
+			t.Errorf("unexpected change: %#v", change)
+		}
+		ids = append(ids, change.ID)
+	}
+	if exp := []string{"C", "F", "T", "T.M", "V"}; !reflect.DeepEqual(ids, exp) {
+		t.Errorf("exp additions %v got %v", exp, ids)
+	}
+}
+
+// TestSortOrder tests changes are sorted by each sort order.
+func TestSortOrder(t *testing.T) {
+	changes := []Change{
+		{Pkg: "a", ID: "B", Change: NonBreaking, Pos: "rev2:a.go:2"},
+		{Pkg: "a", ID: "A", Change: Breaking, Pos: "rev2:b.go:1"},
+		{Pkg: "a", ID: "C", Change: Breaking, Pos: "rev1:a.go:10"},
+		{Pkg: "b", ID: "A", Change: Breaking, Pos: "rev2:a.go:1"},
+		{Pkg: "a", ID: "D", Change: NonBreaking, Pos: "rev2:a.go:9"},
+	}
+
+	tests := []struct {
+		order SortOrder
+		exp   []string // package and ID of changes
+	}{
+		{SortByID, []string{"a.A", "b.A", "a.B", "a.C", "a.D"}},
+		{SortByPosition, []string{"a.B", "a.D", "a.C", "a.A", "b.A"}},
+		{SortBySeverity, []string{"a.C", "a.A", "b.A", "a.B", "a.D"}},
+		{SortByUsage, []string{"a.A", "b.A", "a.C", "a.B", "a.D"}},
+	}
+	for _, test := range tests {
+		sorted := append([]Change(nil), changes...)
+		New(SetSortOrder(test.order)).sortChanges(sorted)
+
+		var got []string
+		for _, change := range sorted {
+			got = append(got, change.Pkg+"."+change.ID)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("order: %v exp %v got %v", test.order, test.exp, got)
+		}
+	}
+
+	// Positions of the file system have no revision.
+	changes = []Change{
+		{Pkg: "a", ID: "A", Pos: "b.go:1"},
+		{Pkg: "a", ID: "B", Pos: "a.go:10"},
+		{Pkg: "a", ID: "C", Pos: "a.go:9"},
+	}
+	New(SetSortOrder(SortByPosition)).sortChanges(changes)
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.ID)
+	}
+	if exp := []string{"C", "B", "A"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("file system positions exp %v got %v", exp, got)
+	}
+}
+
+// TestUsageData tests changes are annotated with their usage, and the most
+// used breaking changes are sorted first.
+func TestUsageData(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev1", "abitest.go", []byte("package lib\nfunc A(int) {}\nfunc B(int) {}\nfunc C(int) {}\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\nfunc A(uint) {}\nfunc B(uint) {}\nfunc C(int) {}\nfunc D() {}\n"))
+
+	usage := map[string]int{"A": 1, "B": 50, "C": 1000, "D": 500}
+	check := func(exp []string) {
+		changes, err := New(SetVCS(vcs), SetUsageData(usage), SetSortOrder(SortByUsage)).Check("", false, "rev1", "rev2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, change := range changes {
+			got = append(got, fmt.Sprintf("%s:%d", change.ID, change.Usage))
+			usage[change.Pkg+".A"] = 100 // used by the next check
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("exp %v got %v", exp, got)
+		}
+	}
+	check([]string{"B:50", "A:1", "D:500"})
+
+	// usage keyed by package and ID takes precedence
+	check([]string{"A:100", "B:50", "D:500"})
+}
+
+// countingVCS counts the directories read at each revision.
+type countingVCS struct {
+	StrVCS
+	reads map[string]int // revision -> directories read
+}
+
+// ReadDir implements VCS.ReadDir
+func (v countingVCS) ReadDir(revision, path string) ([]os.FileInfo, error) {
+	v.reads[revision]++
+	return v.StrVCS.ReadDir(revision, path)
+}
+
+// TestCheckMany tests an after revision is compared against each before
+// revision, with the same changes as checking each separately, while only
+// parsing the after revision once.
+func TestCheckMany(t *testing.T) {
+	revs := map[string]string{
+		"v1.0": "package lib\nfunc F(int) {}\ntype T int\n",
+		"v1.1": "package lib\nfunc F(int) {}\ntype T int\nfunc G() {}\n",
+		"v1.2": "package lib\nfunc F(uint) {}\nfunc G() {}\n",
+		"head": "package lib\nfunc F(uint) {}\nfunc G() {}\nfunc H() {}\n",
+	}
+	vcs := countingVCS{reads: make(map[string]int)}
+	for rev, contents := range revs {
+		vcs.SetFile(rev, "abitest.go", []byte(contents))
+	}
+
+	befores := []string{"v1.0", "v1.1", "v1.2"}
+	changes, err := New(SetVCS(vcs)).CheckMany("", false, "head", befores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reads := vcs.reads["head"]; reads != 1 {
+		t.Errorf("exp after revision parsed once, read %d times", reads)
+	}
+
+	exp := map[string]int{"v1.0": 4, "v1.1": 3, "v1.2": 1}
+	for _, before := range befores {
+		if len(changes[before]) != exp[before] {
+			t.Errorf("before: %v exp %d changes got %d: %v", before, exp[before], len(changes[before]), changes[before])
+		}
+		single, err := New(SetVCS(vcs)).Check("", false, before, "head")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(changes[before], single) {
+			t.Errorf("before: %v exp same changes as Check %v got %v", before, single, changes[before])
+		}
+	}
+}
+
+// TestCheckBidirectional tests the changes in each direction are the same as
+// checking each direction separately, while only parsing each revision once.
+func TestCheckBidirectional(t *testing.T) {
+	vcs := countingVCS{reads: make(map[string]int)}
+	vcs.SetFile("rev1", "abitest.go", []byte("package lib\nfunc F(int) {}\ntype T struct{ A int }\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\nfunc F(int) {}\ntype T struct{ A, B int }\nfunc G() {}\n"))
+
+	forward, backward, err := New(SetVCS(vcs)).CheckBidirectional("", false, "rev1", "rev2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rev := range []string{"rev1", "rev2"} {
+		if reads := vcs.reads[rev]; reads != 1 {
+			t.Errorf("exp revision %v parsed once, read %d times", rev, reads)
+		}
+	}
+
+	tests := []struct {
+		direction     string
+		changes       []Change
+		before, after string
+		expBreaking   int
+	}{
+		{"forward", forward, "rev1", "rev2", 0},
+		{"backward", backward, "rev2", "rev1", 2},
+	}
+	for _, test := range tests {
+		var breaking int
+		for _, change := range test.changes {
+			if change.Change == Breaking {
+				breaking++
+			}
+		}
+		if breaking != test.expBreaking {
+			t.Errorf("%v: exp %d breaking changes got %d: %v", test.direction, test.expBreaking, breaking, test.changes)
+		}
+		single, err := New(SetVCS(vcs)).Check("", false, test.before, test.after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(test.changes, single) {
+			t.Errorf("%v: exp same changes as Check %v got %v", test.direction, single, test.changes)
+		}
+	}
+}
+
+// TestGenerateBefore tests a package whose generated files aren't committed is
+// generated at each revision before being checked.
+func TestGenerateBefore(t *testing.T) {
+	const lib = "package lib\n\n//go:generate cp gen.txt gen.go\n\nvar V Generated\n"
+	var vcs StrVCS
+	vcs.SetFile("rev1", "abitest.go", []byte(lib))
+	vcs.SetFile("rev1", "gen.txt", []byte("package lib\n\ntype Generated int\n"))
+	vcs.SetFile("rev2", "abitest.go", []byte(lib))
+	vcs.SetFile("rev2", "gen.txt", []byte("package lib\n\ntype Generated uint\n"))
+
+	if _, err := New(SetVCS(vcs)).Check("", false, "rev1", "rev2"); err == nil {
+		t.Fatalf("expected type error without generating")
+	}
+
+	changes, err := New(SetVCS(vcs), SetGenerateBefore(true)).Check("", false, "rev1", "rev2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].ID != "Generated" || changes[0].Change != Breaking {
+		t.Errorf("exp breaking change to Generated, got: %v", changes)
+	}
+
+	// generation failing is reported
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\n\n//go:generate false\n"))
+	_, err = New(SetVCS(vcs), SetGenerateBefore(true)).Check("", false, "rev1", "rev2")
+	if err == nil || !strings.Contains(err.Error(), `at revision "rev2"`) || !strings.HasPrefix(err.Error(), "go generate failed for") {
+		t.Errorf("exp go generate error for rev2, got: %v", err)
+	}
+}
+
+// BenchmarkParseLarge measures the allocations of parsing and type checking a
+// large package, and the heap retained by the parsed package, which includes
+// its files' AST. The resolve and skip-resolve benchmarks compare parsing the
+// files with and without the object resolution that parseMode skips.
+func BenchmarkParseLarge(b *testing.B) {
+	var (
+		vcs   StrVCS
+		files = make(map[string][]byte)
+	)
+	for i := 0; i < 100; i++ {
+		var src bytes.Buffer
+		fmt.Fprintf(&src, "package lib\n")
+		for j := 0; j < 100; j++ {
+			fmt.Fprintf(&src, "\n// F%[1]d_%[2]d is exported.\nfunc F%[1]d_%[2]d(a, b int) (string, error) {\n\tif a > b {\n\t\treturn \"\", nil\n\t}\n\treturn \"ab\", nil\n}\n", i, j)
+			fmt.Fprintf(&src, "\n// T%[1]d_%[2]d is exported.\ntype T%[1]d_%[2]d struct {\n\tA, B int\n\tc    string\n}\n", i, j)
+		}
+		name := fmt.Sprintf("file%d.go", i)
+		vcs.SetFile("rev1", name, src.Bytes())
+		files[name] = src.Bytes()
+	}
+
+	b.Run("parse", func(b *testing.B) {
+		c := New(SetVCS(vcs))
+		if err := c.setPath("", false, "rev1"); err != nil {
+			b.Fatal(err)
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		var pkgs map[string]pkg
+		for i := 0; i < b.N; i++ {
+			var err error
+			if pkgs, err = c.parse("rev1"); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StopTimer()
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(pkgs)
+		b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "retained-B")
+	})
+
+	for _, bench := range []struct {
+		name string
+		mode parser.Mode
+	}{
+		{"resolve", 0},
+		{"skip-resolve", parser.SkipObjectResolution},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fset := token.NewFileSet()
+				for name, src := range files {
+					if _, err := parser.ParseFile(fset, name, src, bench.mode); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestBaselineOnMissing tests a missing before revision establishes a baseline
+// instead of failing.
+func TestBaselineOnMissing(t *testing.T) {
+	var vcs StrVCS
+	vcs.SetFile("rev2", "abitest.go", []byte("package lib\nfunc F() {}\n"))
+
+	if _, err := New(SetVCS(vcs)).Check("", false, "rev1", "rev2"); err == nil {
+		t.Errorf("expected error without baseline option")
+	}
+	changes, err := New(SetVCS(vcs), SetBaselineOnMissing(true)).Check("", false, "rev1", "rev2")
+	if err != ErrBaselineEstablished || len(changes) != 0 {
+		t.Errorf("exp no changes and ErrBaselineEstablished, got: %v %v", changes, err)
+	}
+
+	// the parent revision of a repository with a single commit
+	gopath := makeGOPATH(t, "example.com/mod", map[string]string{"lib/lib.go": "package lib\n\nfunc F() {}\n"})
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/mod/lib")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err = New(SetVCS(git), SetBaselineOnMissing(true)).Check(".", false, "HEAD~1", "HEAD")
+	if err != ErrBaselineEstablished || len(changes) != 0 {
+		t.Errorf("exp no changes and ErrBaselineEstablished for first commit, got: %v %v", changes, err)
+	}
+
+	// a before revision which exists is checked
+	changes, err = New(SetVCS(git), SetBaselineOnMissing(true)).Check(".", false, "HEAD", "HEAD")
+	if err != nil || len(changes) != 0 {
+		t.Errorf("exp no changes or error, got: %v %v", changes, err)
+	}
+}
+
+// TestCheckMajorVersions tests a package is checked against its next major
+// version in a subdirectory.
+func TestCheckMajorVersions(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/bar", map[string]string{
+		"go.mod":    "module example.com/bar\n",
+		"bar.go":    "package bar\n\nfunc F(int) {}\n\nfunc H() {}\n",
+		"v2/go.mod": "module example.com/bar/v2\n",
+		"v2/bar.go": "package bar\n\nfunc F(uint) {}\n\nfunc G() {}\n\nfunc H() {}\n",
+	})
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/bar")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := New(SetVCS(git)).CheckMajorVersions("example.com/bar", "", "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, change := range changes {
+		got = append(got, fmt.Sprintf("%s.%s: %s", change.Pkg, change.ID, change.Change))
+	}
+	exp := []string{
+		"example.com/bar/v2.F: " + Breaking,
+		"example.com/bar/v2.G: " + NonBreaking,
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("exp %v got %v", exp, got)
+	}
+
+	if _, err := New(SetVCS(git)).CheckMajorVersions("example.com/bar", "", "2"); err == nil {
+		t.Errorf("expected error for invalid major version")
+	}
+
+	// types referring to the package itself are the same at each major version
+	const same = "package bar\n\ntype T struct{ A int }\n\nfunc F() T { return T{} }\n\n" +
+		"func (T) M(*T) {}\n\ntype I interface{ M(*T) }\n"
+	gopath = makeGOPATH(t, "example.com/bar", map[string]string{
+		"go.mod":    "module example.com/bar\n",
+		"bar.go":    same,
+		"v2/go.mod": "module example.com/bar/v2\n",
+		"v2/bar.go": same,
+	})
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/bar")()
+
+	if git, err = NewGit("."); err != nil {
+		t.Fatal(err)
+	}
+	if changes, err = New(SetVCS(git)).CheckMajorVersions("example.com/bar", "", "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("exp no changes between identical major versions got %d: %v", len(changes), changes)
+	}
+}
+
+// TestMaterialize tests a multi-file package, with a subpackage, parsed from a
+// temporary checkout has the same changes as when read from the VCS, and that
+// the checkout is removed.
+func TestMaterialize(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/lib",
+		map[string]string{
+			"a.go":       "package lib\n\ntype T struct{ A int }\n",
+			"b.go":       "package lib\n\nfunc F(t T) {}\n",
+			"sub/sub.go": "package sub\n\nfunc G() {}\n",
+		},
+		map[string]string{
+			"a.go":       "package lib\n\ntype T struct{ A uint }\n",
+			"sub/sub.go": "package sub\n\nfunc G(int) {}\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/lib")()
+
+	tmp, err := ioutil.TempDir("", "apicompat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	oldTmp := os.Getenv("TMPDIR")
+	defer os.Setenv("TMPDIR", oldTmp)
+	if err := os.Setenv("TMPDIR", tmp); err != nil {
+		t.Fatal(err)
+	}
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := New(SetVCS(git)).Check(".", true, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := New(SetVCS(git), SetMaterialize(true)).Check(".", true, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("exp 2 changes got %d: %v", len(changes), changes)
+	}
+	if !reflect.DeepEqual(changes, exp) {
+		t.Errorf("exp same changes as without materializing\nexp: %v\ngot: %v", exp, changes)
+	}
+	if files, err := ioutil.ReadDir(tmp); err != nil || len(files) != 0 {
+		t.Errorf("exp checkouts removed, got: %v, err: %v", files, err)
+	}
+}
+
+// TestPackageConfig tests each package's configuration file applies to its
+// own comparison, with two packages in one run using different perspectives.
+func TestPackageConfig(t *testing.T) {
+	const (
+		before = "interface{ A() }\n"
+		after  = "interface{ A(); B() }\n"
+	)
+	gopath := makeGOPATH(t, "example.com/mod",
+		map[string]string{
+			"caller/caller.go":             "package caller\n\ntype I " + before,
+			"caller/" + pkgConfigFile:      "# only implemented by this package\nperspective = \"caller\"\nexclude = [\"Ignored*\"] # not compared\n",
+			"caller/ignored.go":            "package caller\n\nfunc Ignored() {}\n\ntype IgnoredT struct{}\n",
+			"implementer/implementer.go":   "package implementer\n\ntype I " + before,
+			"implementer/" + pkgConfigFile: "perspective = \"implementer\"\n",
+		},
+		map[string]string{
+			"caller/caller.go":           "package caller\n\ntype I " + after,
+			"caller/ignored.go":          "package caller\n\nfunc Ignored(int) {}\n\ntype IgnoredT struct{}\n\nfunc (IgnoredT) String() string { return \"\" }\n",
+			"implementer/implementer.go": "package implementer\n\ntype I " + after,
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/mod")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := New(SetVCS(git)).Check(".", true, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]string{
+		"example.com/mod/caller.I":      NonBreaking,
+		"example.com/mod/implementer.I": Breaking,
+	}
+	if len(changes) != len(exp) {
+		t.Fatalf("exp %d changes got %d: %v", len(exp), len(changes), changes)
+	}
+	for _, change := range changes {
+		if change.Change != exp[change.StableID()] {
+			t.Errorf("%v: exp %v got %v", change.StableID(), exp[change.StableID()], change.Change)
+		}
+	}
+
+	// the checker's options apply to packages without configuration
+	changes, err = New(SetVCS(git), SetInterfacePerspective(PerspectiveCaller)).Check("./implementer", false, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Change != Breaking {
+		t.Errorf("exp configuration to override checker's perspective, got: %v", changes)
+	}
+
+	// the configuration's exclude patterns add to the checker's
+	changes, err = New(SetVCS(git), SetExcludeIDs([]string{"I"})).Check("./caller", false, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("exp checker's and configuration's excludes to apply, got: %v", changes)
+	}
+}
+
+// TestBreakingConstValues tests constants changing value are breaking with
+// SetBreakingConstValues, and non-breaking without.
+func TestBreakingConstValues(t *testing.T) {
+	const (
+		before = "package lib\nconst (\n\tA = iota\n\tB\n)\nconst Limit = 10\nconst Same = 1\nconst Version = \"1.0\"\n"
+		after  = "package lib\nconst (\n\tB = iota\n\tA\n)\nconst Limit = 20\nconst Same = 2 - 1\nconst Version = \"2.0\"\n"
+	)
+	for _, breakingValues := range []bool{false, true} {
+		exp := NonBreaking
+		if breakingValues {
+			exp = Breaking
+		}
+		changes := checkStrVCS(t, before, after, SetBreakingConstValues(breakingValues))
+		if len(changes) != 4 {
+			t.Fatalf("breaking: %v exp 4 changes got %d: %v", breakingValues, len(changes), changes)
+		}
+		for _, change := range changes {
+			if change.Change != exp || !strings.Contains(change.Msg, "changed value") {
+				t.Errorf("breaking: %v exp %v value change got: %v %v", breakingValues, exp, change.Change, change.Msg)
+			}
+		}
+	}
+}
+
+func TestBreakingFieldOrder(t *testing.T) {
+	const (
+		before = "package lib\ntype T struct {\n\tA, B int\n\tC string\n}\n"
+		after  = "package lib\ntype T struct {\n\tC string\n\tB, A int\n}\n"
+	)
+	tests := []struct {
+		options []func(*Checker)
+		exp     string
+	}{
+		{nil, Breaking},
+		{[]func(*Checker){SetBreakingFieldOrder(true)}, Breaking},
+		{[]func(*Checker){SetBreakingFieldOrder(false)}, NonBreaking},
+	}
+	for _, test := range tests {
+		changes := checkStrVCS(t, before, after, test.options...)
+		if len(changes) != 1 {
+			t.Fatalf("exp 1 change got %d: %v", len(changes), changes)
+		}
+		if exp := "struct fields reordered: A, B, C → C, B, A"; changes[0].Change != test.exp || changes[0].Msg != exp {
+			t.Errorf("exp %v %q got %v %q", test.exp, exp, changes[0].Change, changes[0].Msg)
+		}
+	}
+}
+
+// TestConstraintNarrowedOnce tests a constraint's type set narrowing is
+// reported once, by the constraint, listing its users, instead of again by
+// each generic declaration it constrains.
+func TestConstraintNarrowedOnce(t *testing.T) {
+	const (
+		before = "package lib\ntype Num interface{ ~int | ~float64 | ~uint }\nfunc F[T Num](T) {}\ntype V[T Num] []T\nfunc (V[T]) M() {}\n"
+		after  = "package lib\ntype Num interface{ ~int }\nfunc F[T Num](T) {}\ntype V[T Num] []T\nfunc (V[T]) M() {}\n"
+	)
+	changes := checkStrVCS(t, before, after)
+	if len(changes) != 1 {
+		t.Fatalf("exp 1 change got %d: %v", len(changes), changes)
+	}
+	exp := "type set narrowed: removed ~float64 | ~uint; constrains F, V"
+	if changes[0].ID != "Num" || changes[0].Msg != exp {
+		t.Errorf("exp Num %q got %v %q", exp, changes[0].ID, changes[0].Msg)
+	}
+}
+
+// TestUnexportedConstraintNarrowed tests a named constraint which isn't
+// reported by its own declaration, such as an unexported one, is described by
+// how it changed, instead of by its unchanged name.
+func TestUnexportedConstraintNarrowed(t *testing.T) {
+	const (
+		before = "package lib\ntype num interface{ ~int | ~float64 }\nfunc F[T num](T) {}\n"
+		after  = "package lib\ntype num interface {\n\t~int\n\tString() string\n}\nfunc F[T num](T) {}\n"
+	)
+	changes := checkStrVCS(t, before, after)
+	if len(changes) != 1 {
+		t.Fatalf("exp 1 change got %d: %v", len(changes), changes)
+	}
+	exp := "type parameter T constraint tightened: num added methods String, type set removed ~float64"
+	if changes[0].ID != "F" || changes[0].Msg != exp {
+		t.Errorf("exp F %q got %v %q", exp, changes[0].ID, changes[0].Msg)
+	}
+}
@@ -0,0 +1,65 @@
+// Package apicompattest provides utilities for testing the changes reported
+// by apicompat.
+package apicompattest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bradleyfalzon/apicompat"
+)
+
+// AssertChanges checks a single package's before and after files, each keyed
+// by file name such as lib.go, with the given options, and reports an error
+// unless exactly the wanted changes are reported, in any order. Changes are
+// compared by their ID, Change and Msg, and Pkg if set, as a package's import
+// path depends on the working directory. Other fields, such as Pos, are
+// ignored. See apicompat.RunFixture.
+func AssertChanges(t testing.TB, before, after map[string][]byte, want []apicompat.Change, options ...func(*apicompat.Checker)) {
+	t.Helper()
+	got, err := apicompat.RunFixture(before, after, options...)
+	if err != nil {
+		t.Fatalf("unexpected error checking changes: %v", err)
+	}
+
+	// count each wanted change, comparing Pkg only if wanted
+	remaining := make(map[string]int)
+	for _, change := range want {
+		remaining[key(change, change.Pkg != "")]++
+	}
+	var unexpected []string
+	for _, change := range got {
+		switch k := key(change, true); {
+		case remaining[k] > 0:
+			remaining[k]--
+		case remaining[key(change, false)] > 0:
+			remaining[key(change, false)]--
+		default:
+			unexpected = append(unexpected, k)
+		}
+	}
+	var missing []string
+	for k, n := range remaining {
+		for i := 0; i < n; i++ {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	t.Errorf("unexpected changes\nmissing:\n\t%s\nunexpected:\n\t%s",
+		strings.Join(missing, "\n\t"), strings.Join(unexpected, "\n\t"))
+}
+
+// key returns the compared fields of a change, including its Pkg if withPkg.
+func key(change apicompat.Change, withPkg bool) string {
+	id := change.ID
+	if withPkg {
+		id = change.Pkg + "." + id
+	}
+	return fmt.Sprintf("%s: %s: %s", id, change.Change, change.Msg)
+}
@@ -0,0 +1,72 @@
+package apicompattest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bradleyfalzon/apicompat"
+)
+
+// recorder is a testing.TB which records errors instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+// Helper implements testing.TB.
+func (r *recorder) Helper() {}
+
+// Errorf implements testing.TB.
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestAssertChanges tests the wanted changes are matched regardless of order
+// and position, and missing or unexpected changes are reported.
+func TestAssertChanges(t *testing.T) {
+	before := map[string][]byte{"lib.go": []byte("package lib\n\nfunc F(int) {}\n\nfunc Removed() {}\n")}
+	after := map[string][]byte{"lib.go": []byte("package lib\n\nfunc F(uint) {}\n\nfunc Added(int) {}\n")}
+
+	AssertChanges(t, before, after, []apicompat.Change{
+		{ID: "Removed", Change: apicompat.Breaking, Msg: "declaration removed"},
+		{ID: "F", Change: apicompat.Breaking, Msg: "parameter types changed", Pos: "ignored"},
+		{ID: "Added", Change: apicompat.NonBreaking, Msg: "declaration added"},
+	})
+
+	tests := []struct {
+		want      []apicompat.Change
+		expErrors int
+	}{
+		{[]apicompat.Change{
+			{ID: "Removed", Change: apicompat.Breaking, Msg: "declaration removed"},
+			{ID: "Added", Change: apicompat.NonBreaking, Msg: "declaration added"},
+		}, 1}, // F unexpected
+		{[]apicompat.Change{
+			{ID: "Removed", Change: apicompat.Breaking, Msg: "declaration removed"},
+			{ID: "F", Change: apicompat.NonBreaking, Msg: "parameter types changed"},
+			{ID: "Added", Change: apicompat.NonBreaking, Msg: "declaration added"},
+		}, 1}, // F missing and unexpected
+		{[]apicompat.Change{
+			{Pkg: "example.com/other", ID: "Removed", Change: apicompat.Breaking, Msg: "declaration removed"},
+			{ID: "F", Change: apicompat.Breaking, Msg: "parameter types changed"},
+			{ID: "Added", Change: apicompat.NonBreaking, Msg: "declaration added"},
+		}, 1}, // Removed in a different package
+	}
+	for _, test := range tests {
+		r := &recorder{TB: t}
+		AssertChanges(r, before, after, test.want)
+		if len(r.errors) != test.expErrors {
+			t.Errorf("want: %v exp %d errors got %d: %q", test.want, test.expErrors, len(r.errors), r.errors)
+		}
+	}
+}
+
+// TestAssertChangesOptions tests options are passed to the Checker.
+func TestAssertChangesOptions(t *testing.T) {
+	before := map[string][]byte{"lib.go": []byte("package lib\n")}
+	after := map[string][]byte{"lib.go": []byte("package lib\n\nfunc Added() {}\n")}
+
+	AssertChanges(t, before, after, []apicompat.Change{
+		{ID: "Added", Change: apicompat.Breaking, Msg: "declaration added while frozen"},
+	}, apicompat.SetFrozen(true))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/parser"
 	"go/token"
 	"go/types"
@@ -20,6 +21,84 @@ const (
 	Breaking    = "breaking change"
 )
 
+// Confidence is how certain a change is, see High, Medium and Low.
+type Confidence int
+
+// The different confidence levels of a change, from most to least certain.
+const (
+	High   Confidence = iota // a direct structural difference
+	Medium                   // a heuristic which is usually correct
+	Low                      // a heuristic which may be speculative
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case High:
+		return "high"
+	case Medium:
+		return "medium"
+	case Low:
+		return "low"
+	}
+	return fmt.Sprintf("Confidence(%d)", int(c))
+}
+
+// Kind is what a change did to the API, see Modification, Addition and
+// Removal.
+type Kind int
+
+// The different kinds of change.
+const (
+	Modification Kind = iota // an existing package or declaration changed
+	Addition                 // a declaration or members were added
+	Removal                  // a package, declaration or members were removed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Modification:
+		return "modification"
+	case Addition:
+		return "addition"
+	case Removal:
+		return "removal"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// The categories of changes, such as a parameter's type changing, set when
+// each change is created and used as the change's rule in SARIF logs, see
+// sarifRules. Changes without a category, such as declarations which couldn't
+// be compared, are of the rule sarifOther.
+const (
+	categoryDeclarationRemoved     = "declaration-removed"
+	categoryDeclarationAdded       = "declaration-added"
+	categoryDeclarationRenamed     = "declaration-renamed"
+	categoryMembersAddedRemoved    = "members-added-removed"
+	categoryMembersAdded           = "members-added"
+	categoryMembersRemoved         = "members-removed"
+	categoryMembersChanged         = "members-changed"
+	categoryMemberShadowed         = "member-shadowed"
+	categoryMemberProvenance       = "member-provenance"
+	categoryTypeParametersChanged  = "type-parameters-changed"
+	categoryVariadicChanged        = "variadic-changed"
+	categoryParametersChanged      = "parameters-changed"
+	categoryResultsChanged         = "results-changed"
+	categoryTypeSetChanged         = "type-set-changed"
+	categoryAliasChanged           = "alias-changed"
+	categoryInterfaceImplemented   = "interface-implemented"
+	categoryInterfaceUnimplemented = "interface-unimplemented"
+	categoryInterfaceCompatible    = "interface-compatible"
+	categoryStructFieldsReordered  = "struct-fields-reordered"
+	categoryStructTagsChanged      = "struct-tags-changed"
+	categoryStructLayoutChanged    = "struct-layout-changed"
+	categoryZeroValueChanged       = "zero-value-changed"
+	categoryConstantValueChanged   = "constant-value-changed"
+	categoryConcurrencyDocChanged  = "concurrency-doc-changed"
+	categoryGenerateReference      = "generate-reference"
+	categoryTypeChanged            = "type-changed"
+)
+
 // DeclChange represents a single change between 2 revision.
 type DeclChange struct {
 	// Change is the type of change, see None, NonBreaking and Breaking.
@@ -28,6 +107,31 @@ type DeclChange struct {
 	Msg string
 	// Pos is the position of the change.
 	Pos token.Pos
+	// Confidence is how certain the change is, defaults to High.
+	Confidence Confidence
+	// Kind is what the change did, defaults to Modification.
+	Kind Kind
+	// Category is the category of the change, such as parameters-changed, or
+	// empty if uncategorised, see Change.Category.
+	Category string
+}
+
+// withConfidence returns the change with the given confidence.
+func (d DeclChange) withConfidence(confidence Confidence) DeclChange {
+	d.Confidence = confidence
+	return d
+}
+
+// withKind returns the change with the given kind.
+func (d DeclChange) withKind(kind Kind) DeclChange {
+	d.Kind = kind
+	return d
+}
+
+// withCategory returns the change with the given category.
+func (d DeclChange) withCategory(category string) DeclChange {
+	d.Category = category
+	return d
 }
 
 // DeclChecker takes a list of changes and verifies which, if any, change breaks
@@ -35,6 +139,24 @@ type DeclChange struct {
 type DeclChecker struct {
 	binfo *types.Info
 	ainfo *types.Info
+	bpkg  *types.Package // optional, used to unqualify types in messages
+	apkg  *types.Package // optional, used to unqualify types in messages
+
+	trackZeroValue bool // report structs whose zero value may no longer be usable
+	trackWire      bool // report changes to struct fields' serialised format
+	conservative   bool // treat changes which cannot be classified as breaking
+
+	sizes types.Sizes // if set, report changes to structs' memory layout
+
+	perspective Perspective // who implements exported interfaces, see SetInterfacePerspective
+
+	breakingConstValues bool // report changes to constants' values as breaking
+
+	nonBreakingFieldOrder bool // report structs' fields being reordered as non-breaking
+
+	breakingTagKeys map[string]bool // struct tag keys whose removal is breaking
+
+	qualifier types.Qualifier // qualifies types compared as strings, by path if nil, see CheckMajorVersions
 }
 
 // NewDeclChecker creates a DeclChecker.
@@ -43,13 +165,17 @@ func NewDeclChecker(bi, ai *types.Info) *DeclChecker {
 }
 
 // nonBreaking returns a DeclChange with the non-breaking change type.
-func nonBreaking(msg string, pos token.Pos) DeclChange { return DeclChange{NonBreaking, msg, pos} }
+func nonBreaking(msg string, pos token.Pos) DeclChange {
+	return DeclChange{Change: NonBreaking, Msg: msg, Pos: pos}
+}
 
 // breaking returns a DeclChange with the breaking change type.
-func breaking(msg string, pos token.Pos) DeclChange { return DeclChange{Breaking, msg, pos} }
+func breaking(msg string, pos token.Pos) DeclChange {
+	return DeclChange{Change: Breaking, Msg: msg, Pos: pos}
+}
 
 // none returns a DeclChange with the no change type.
-func none() DeclChange { return DeclChange{None, "", 0} }
+func none() DeclChange { return DeclChange{Change: None} }
 
 // Check compares two declarations and returns the DeclChange associated with
 // that change. For example, comments aren't compared, names of arguments aren't
@@ -59,7 +185,7 @@ func (c DeclChecker) Check(before, after ast.Decl) (DeclChange, error) {
 
 	if reflect.TypeOf(before) != reflect.TypeOf(after) {
 		// Declaration type changed, such as GenDecl to FuncDecl (eg var/const to func)
-		return breaking("changed declaration", after.Pos()), nil
+		return breaking("changed declaration", after.Pos()).withCategory(categoryTypeChanged), nil
 	}
 
 	switch b := before.(type) {
@@ -70,7 +196,7 @@ func (c DeclChecker) Check(before, after ast.Decl) (DeclChange, error) {
 
 		if reflect.TypeOf(b.Specs[0]) != reflect.TypeOf(a.Specs[0]) {
 			// Spec changed, such as ValueSpec to TypeSpec (eg var/const to struct)
-			return breaking("changed spec", a.Specs[0].Pos()), nil
+			return breaking("changed spec", a.Specs[0].Pos()).withCategory(categoryTypeChanged), nil
 		}
 
 		switch bspec := b.Specs[0].(type) {
@@ -80,41 +206,80 @@ func (c DeclChecker) Check(before, after ast.Decl) (DeclChange, error) {
 
 			btype := c.binfo.ObjectOf(bspec.Names[0])
 			atype := c.ainfo.ObjectOf(aspec.Names[0])
+			if btype == nil || atype == nil {
+				if c.conservative {
+					return breaking("could not determine type", aspec.Pos()), nil
+				}
+				return DeclChange{}, fmt.Errorf("could not determine type of %s", aspec.Names[0].Name)
+			}
 
 			if !types.Identical(btype.Type(), atype.Type()) {
 				// Inferred types from external packages (inc. stdlib) aren't identical
 				// according to types.Identical(), so compare the string representations
 				if btype.String() != atype.String() {
-					return breaking("changed type", atype.Pos()), nil
+					return breaking("changed type", atype.Pos()).withCategory(categoryTypeChanged), nil
+				}
+			}
+
+			bconst, bok := btype.(*types.Const)
+			aconst, aok := atype.(*types.Const)
+			if bok && aok {
+				if change, ok := c.enumValueChange(bconst, aconst); ok {
+					return change, nil
+				}
+				if change, ok := c.stringValueChange(bconst, aconst); ok {
+					return change, nil
+				}
+				if change, ok := c.constValueChange(bconst, aconst); ok {
+					return change, nil
 				}
 			}
 		case *ast.TypeSpec:
 			// type struct/interface/aliased
 			aspec := a.Specs[0].(*ast.TypeSpec)
 
-			if reflect.TypeOf(bspec.Type) != reflect.TypeOf(aspec.Type) {
-				// Spec change, such as from StructType to InterfaceType or different aliased types
-				return breaking("changed type of value spec", aspec.Pos()), nil
+			if change, ok := genericMigration("type", bspec.Name, bspec.TypeParams, aspec.TypeParams); ok {
+				return change, nil
+			}
+			if change, ok := typeParamsChange(bspec.TypeParams, aspec.TypeParams); ok {
+				return change, nil
+			}
+			bparams, aparams := typeParams(c.binfo, bspec.Name), typeParams(c.ainfo, aspec.Name)
+			if msg := c.constraintsTightened(bparams, aparams); msg != "" {
+				return breaking(msg, aspec.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), nil
 			}
 
-			switch btype := bspec.Type.(type) {
-			case *ast.InterfaceType:
-				atype := aspec.Type.(*ast.InterfaceType)
-				return c.checkInterface(btype, atype, disallowRemoval)
-			case *ast.StructType:
-				atype := aspec.Type.(*ast.StructType)
-				return c.checkStruct(btype, atype)
-			case *ast.Ident:
-				// alias
-				atype := aspec.Type.(*ast.Ident)
-				if btype.Name != atype.Name {
-					// Alias typing changed underlying types
-					return breaking("alias changed its underlying type", atype.Pos()), nil
-				}
+			change, err := c.checkTypeSpec(bspec, aspec)
+			if err != nil || change.Change != None {
+				return change, err
+			}
+			if msg := c.constraintsLoosened(bparams, aparams); msg != "" {
+				return nonBreaking(msg, aspec.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), nil
 			}
+			return change, nil
 		}
 	case *ast.FuncDecl:
 		a := after.(*ast.FuncDecl)
+		if b.Recv != nil && a.Recv != nil && len(b.Recv.List) > 0 && len(a.Recv.List) > 0 {
+			// the receiver's type parameters are those of the receiver's type
+			bname, bparams := recvTypeParams(b.Recv.List[0].Type)
+			_, aparams := recvTypeParams(a.Recv.List[0].Type)
+			if change, ok := genericMigration("receiver type", bname, bparams, aparams); ok {
+				change.Pos = a.Recv.Pos()
+				return change, nil
+			}
+			change, err := c.checkFunc(b.Type, a.Type)
+			if err != nil || change.Change == Breaking {
+				return change, err
+			}
+			// the method is unusable with type arguments no longer satisfying
+			// its receiver type's constraints
+			aname, _ := recvTypeParams(a.Recv.List[0].Type)
+			if msg := c.constraintsTightened(typeParams(c.binfo, bname), typeParams(c.ainfo, aname)); msg != "" {
+				return breaking(fmt.Sprintf("receiver type %s %s", aname.Name, msg), a.Recv.Pos()).withCategory(categoryTypeParametersChanged), nil
+			}
+			return change, nil
+		}
 		return c.checkFunc(b.Type, a.Type)
 	default:
 		return DeclChange{}, fmt.Errorf("unknown declaration type: %T", before)
@@ -122,18 +287,212 @@ func (c DeclChecker) Check(before, after ast.Decl) (DeclChange, error) {
 	return none(), nil
 }
 
+// enumValueChange returns a breaking change if a constant of a defined type,
+// such as an enum, changed its value. Consumers persisting or serialising the
+// value would interpret it differently.
+func (c DeclChecker) enumValueChange(before, after *types.Const) (DeclChange, bool) {
+	named, ok := after.Type().(*types.Named)
+	if !ok || before.Val().Kind() != after.Val().Kind() {
+		// a change in the underlying type is reported by the type itself
+		return DeclChange{}, false
+	}
+	if constant.Compare(before.Val(), token.EQL, after.Val()) {
+		return DeclChange{}, false
+	}
+	typ := types.TypeString(named, types.RelativeTo(c.apkg))
+	msg := fmt.Sprintf("enum constant %s (%s) changed value %s → %s", after.Name(), typ, before.Val(), after.Val())
+	return breaking(msg, after.Pos()).withCategory(categoryConstantValueChanged), true
+}
+
+// stringValueChange returns a change if a string constant, not of a defined
+// type, see enumValueChange, changed its value. Consumers may depend on the
+// exact string, such as a URL path or protocol identifier, but callers still
+// compile, so it's non-breaking, unless the DeclChecker treats constant values
+// as part of the API, see constValueChange.
+func (c DeclChecker) stringValueChange(before, after *types.Const) (DeclChange, bool) {
+	if before.Val().Kind() != constant.String || after.Val().Kind() != constant.String {
+		return DeclChange{}, false
+	}
+	if constant.Compare(before.Val(), token.EQL, after.Val()) {
+		return DeclChange{}, false
+	}
+	msg := fmt.Sprintf("string constant %s changed value %s → %s", after.Name(), before.Val().ExactString(), after.Val().ExactString())
+	if c.breakingConstValues {
+		return breaking(msg, after.Pos()).withCategory(categoryConstantValueChanged), true
+	}
+	return nonBreaking(msg, after.Pos()).withCategory(categoryConstantValueChanged), true
+}
+
+// constValueChange returns a change if a constant, not an enum or string, see
+// enumValueChange and stringValueChange, changed its value, such as a default
+// limit or a protocol version, including untyped constants and those derived
+// from iota. Callers still compile, so it's non-breaking, unless the
+// DeclChecker treats constant values as part of the API.
+func (c DeclChecker) constValueChange(before, after *types.Const) (DeclChange, bool) {
+	if constant.Compare(before.Val(), token.EQL, after.Val()) {
+		return DeclChange{}, false
+	}
+	msg := fmt.Sprintf("constant %s changed value %s → %s", after.Name(), before.Val(), after.Val())
+	if c.breakingConstValues {
+		return breaking(msg, after.Pos()).withCategory(categoryConstantValueChanged), true
+	}
+	return nonBreaking(msg, after.Pos()).withCategory(categoryConstantValueChanged), true
+}
+
+// checkTypeSpec compares two type declarations' types, excluding their type
+// parameters, which Check compares.
+func (c DeclChecker) checkTypeSpec(bspec, aspec *ast.TypeSpec) (DeclChange, error) {
+	switch {
+	case bspec.Assign.IsValid() && !aspec.Assign.IsValid():
+		return breaking("alias changed to a defined type", aspec.Pos()).withCategory(categoryAliasChanged), nil
+	case !bspec.Assign.IsValid() && aspec.Assign.IsValid():
+		return breaking("defined type changed to an alias", aspec.Pos()).withCategory(categoryAliasChanged), nil
+	case aspec.Assign.IsValid():
+		// type alias, whose target may be any type expression
+		return c.checkAlias(bspec.Type, aspec.Type), nil
+	}
+
+	if reflect.TypeOf(bspec.Type) != reflect.TypeOf(aspec.Type) {
+		// Spec change, such as from StructType to InterfaceType or different aliased types
+		return breaking("changed type of value spec", aspec.Pos()).withCategory(categoryTypeChanged), nil
+	}
+
+	switch btype := bspec.Type.(type) {
+	case *ast.InterfaceType:
+		atype := aspec.Type.(*ast.InterfaceType)
+		change, err := c.checkInterface(btype, atype, disallowRemoval)
+		if typeSet, ok := c.typeSetChange(btype, atype); ok && err == nil && change.Msg == typeSet.Msg {
+			// the change isn't reported again by the constraint's users, see
+			// constraintsChanged
+			if users := c.constraintUsers(aspec.Name.Name); len(users) > 0 {
+				change.Msg += "; constrains " + strings.Join(users, ", ")
+			}
+		}
+		return change, err
+	case *ast.StructType:
+		atype := aspec.Type.(*ast.StructType)
+		return c.checkStruct(btype, atype)
+	case *ast.ArrayType:
+		atype := aspec.Type.(*ast.ArrayType)
+		return c.checkArray(btype, atype), nil
+	case *ast.Ident:
+		// alias
+		atype := aspec.Type.(*ast.Ident)
+		if btype.Name != atype.Name {
+			// Alias typing changed underlying types
+			return breaking("alias changed its underlying type", atype.Pos()).withCategory(categoryAliasChanged), nil
+		}
+	}
+	return none(), nil
+}
+
+// genericMigration returns a breaking change if a type migrated between a
+// non-generic and a generic type, as all references to the type must change.
+// kind describes the declaration, such as "type", name is the type's name and
+// before and after are the type's parameters.
+func genericMigration(kind string, name *ast.Ident, before, after *ast.FieldList) (DeclChange, bool) {
+	bparams, aparams := fieldNames(before), fieldNames(after)
+	switch {
+	case len(bparams) == 0 && len(aparams) > 0:
+		msg := fmt.Sprintf("%s %s became generic %s[%s]", kind, name.Name, name.Name, strings.Join(aparams, ", "))
+		return breaking(msg, after.Pos()).withCategory(categoryTypeParametersChanged), true
+	case len(bparams) > 0 && len(aparams) == 0:
+		msg := fmt.Sprintf("%s %s[%s] is no longer generic", kind, name.Name, strings.Join(bparams, ", "))
+		return breaking(msg, name.Pos()).withCategory(categoryTypeParametersChanged), true
+	}
+	return none(), false
+}
+
+// typeParamsChange returns a breaking change if a generic type's type
+// parameters were added, removed or reordered, such as Map[K, V] becoming
+// Map[V, K], as references to the type instantiate it positionally. Types
+// becoming generic, or no longer generic, are reported by genericMigration,
+// and renamed type parameters aren't changes.
+func typeParamsChange(before, after *ast.FieldList) (DeclChange, bool) {
+	bparams, aparams := fieldNames(before), fieldNames(after)
+	if len(bparams) == 0 || len(aparams) == 0 {
+		return none(), false
+	}
+	if len(bparams) != len(aparams) {
+		msg := fmt.Sprintf("type parameter count changed from %d to %d, [%s] → [%s]",
+			len(bparams), len(aparams), strings.Join(bparams, ", "), strings.Join(aparams, ", "))
+		return breaking(msg, after.Pos()).withCategory(categoryTypeParametersChanged), true
+	}
+	if strings.Join(bparams, ",") == strings.Join(aparams, ",") {
+		return none(), false
+	}
+	sorted := func(names []string) string {
+		names = append([]string(nil), names...)
+		sort.Strings(names)
+		return strings.Join(names, ",")
+	}
+	if sorted(bparams) != sorted(aparams) {
+		// renamed, and constraints are compared by position
+		return none(), false
+	}
+	msg := fmt.Sprintf("type parameters reordered, [%s] → [%s]", strings.Join(bparams, ", "), strings.Join(aparams, ", "))
+	return breaking(msg, after.Pos()).withCategory(categoryTypeParametersChanged), true
+}
+
+// recvTypeParams returns the name of a receiver's type and the type
+// parameters used, such as T and [K, V] given *T[K, V].
+func recvTypeParams(expr ast.Expr) (*ast.Ident, *ast.FieldList) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e, nil
+	case *ast.StarExpr:
+		return recvTypeParams(e.X)
+	case *ast.ParenExpr:
+		return recvTypeParams(e.X)
+	case *ast.IndexExpr:
+		name, _ := recvTypeParams(e.X)
+		return name, indicesFieldList(e.Lbrack, e.Index)
+	case *ast.IndexListExpr:
+		name, _ := recvTypeParams(e.X)
+		return name, indicesFieldList(e.Lbrack, e.Indices...)
+	}
+	return nil, nil
+}
+
+// indicesFieldList returns a field list with a named field for each of a
+// generic receiver's type parameter indices.
+func indicesFieldList(lbrack token.Pos, indices ...ast.Expr) *ast.FieldList {
+	fl := &ast.FieldList{Opening: lbrack}
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			fl.List = append(fl.List, &ast.Field{Names: []*ast.Ident{ident}})
+		}
+	}
+	return fl
+}
+
+// fieldNames returns the names of all fields in a field list, which may be
+// nil.
+func fieldNames(fl *ast.FieldList) []string {
+	var names []string
+	if fl == nil {
+		return names
+	}
+	for _, field := range fl.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
 func (c DeclChecker) checkChan(before, after *ast.ChanType) (DeclChange, error) {
 	if !c.exprEqual(before.Value, after.Value) {
-		return breaking("changed channel's type", after.Pos()), nil
+		return breaking("changed channel's type", after.Pos()).withCategory(categoryTypeChanged), nil
 	}
 
 	// If we're specifying a direction and it's not the same as before
 	// (if we remove direction then that change isn't breaking)
 	if before.Dir != after.Dir {
 		if after.Dir != ast.SEND && after.Dir != ast.RECV {
-			return nonBreaking("removed channel's direction", after.Pos()), nil
+			return nonBreaking("removed channel's direction", after.Pos()).withCategory(categoryTypeChanged), nil
 		}
-		return breaking("changed channel's direction", after.Pos()), nil
+		return breaking("changed channel's direction", after.Pos()).withCategory(categoryTypeChanged), nil
 	}
 	return none(), nil
 }
@@ -152,40 +511,137 @@ func (c DeclChecker) checkInterface(before, after *ast.InterfaceType, allowRemov
 	// Resolving embedded interfaces to their signatures skips false positives
 	// when switching between an embedded type to their equivalent non embedded
 	// eg, from embedded Reader to Read(p []byte) (n int, err error)
-	if err := resolveInterface(c.binfo.Uses, before); err != nil {
+	if err := resolveInterface(c.binfo.Uses, c.bpkg, before); err != nil {
+		if c.conservative {
+			return breaking("could not resolve embedded interface", after.Pos()), nil
+		}
 		return none(), err
 	}
-	if err := resolveInterface(c.ainfo.Uses, after); err != nil {
+	if err := resolveInterface(c.ainfo.Uses, c.apkg, after); err != nil {
+		if c.conservative {
+			return breaking("could not resolve embedded interface", after.Pos()), nil
+		}
 		return none(), err
 	}
 
-	r := c.diffFields(keyOnName, before.Methods.List, after.Methods.List)
-	if r.Added() {
+	typeSet, typeSetChanged := c.typeSetChange(before, after)
+	if typeSetChanged && typeSet.Change == Breaking {
+		return typeSet, nil
+	}
+
+	bmethods, amethods := namedFields(before.Methods.List), namedFields(after.Methods.List)
+	r := c.diffFields(keyOnName, bmethods, amethods)
+	relation := interfaceRelation(r, bmethods)
+	if r.Added() && r.Removed() {
+		// Fields were replaced
+		return breaking("members added and removed: "+relation, r.AddedPos()).withCategory(categoryMembersAddedRemoved), nil
+	} else if r.Added() {
 		// Fields were added
-		return breaking("members added", r.AddedPos()), nil
+		if !allowRemoval && c.perspective == PerspectiveCaller {
+			// only implemented by its own package, see SetInterfacePerspective
+			return nonBreaking("members added: "+relation, r.AddedPos()).withKind(Addition).withCategory(categoryMembersAdded), nil
+		}
+		return breaking("members added: "+relation, r.AddedPos()).withKind(Addition).withCategory(categoryMembersAdded), nil
 	} else if r.Modified() {
 		// Fields changed types
-		return breaking("members changed types", r.ModifiedPos()), nil
+		msg := "members changed types: " + relation
+		if methods := c.methodsChangedMsgs(r); len(methods) > 0 {
+			msg += "; " + strings.Join(methods, "; ")
+		}
+		return breaking(msg, r.ModifiedPos()).withCategory(categoryMembersChanged), nil
 	} else if r.Removed() {
 		if allowRemoval {
-			return nonBreaking("members removed", after.Pos()), nil
+			return nonBreaking("members removed: "+relation, after.Pos()).withKind(Removal).withCategory(categoryMembersRemoved), nil
 		}
-		return breaking("members removed", after.Pos()), nil
+		return breaking("members removed: "+relation, after.Pos()).withKind(Removal).withCategory(categoryMembersRemoved), nil
+	}
+	if typeSetChanged {
+		return typeSet, nil
 	}
 
 	return none(), nil
 }
 
+// methodsChangedMsgs returns a message describing each of the modified
+// interface methods in r, as determined by checkFunc, such as "method Write:
+// parameter types changed".
+func (c DeclChecker) methodsChangedMsgs(r diffResult) []string {
+	var msgs []string
+	for _, mod := range r.modified {
+		bfunc, bok := mod[0].Type.(*ast.FuncType)
+		afunc, aok := mod[1].Type.(*ast.FuncType)
+		if !bok || !aok {
+			continue
+		}
+		change, err := c.checkFunc(bfunc, afunc)
+		if err != nil || change.Change != Breaking {
+			continue
+		}
+		msgs = append(msgs, methodChangedMsg(mod[0].Names[0].Name, change.Msg))
+	}
+	return msgs
+}
+
+// methodChangedMsg describes the change msg to an interface's method name, see
+// methodsChangedMsgs.
+func methodChangedMsg(name, msg string) string {
+	return fmt.Sprintf("method %s: %s", name, msg)
+}
+
+// namedFields returns the fields with names, such as an interface's methods
+// excluding its type set elements.
+func namedFields(fields []*ast.Field) []*ast.Field {
+	var named []*ast.Field
+	for _, field := range fields {
+		if len(field.Names) > 0 {
+			named = append(named, field)
+		}
+	}
+	return named
+}
+
+// interfaceRelation describes the relationship between an interface's method
+// set before and after, given the result r of diffing the before methods with
+// the after methods. A superset breaks implementers, a subset breaks callers,
+// and overlapping or disjoint method sets break both.
+func interfaceRelation(r diffResult, before []*ast.Field) string {
+	return methodSetRelation(len(r.removed), len(r.added), len(r.modified), len(before))
+}
+
+// methodSetRelation describes the relationship between an interface's method
+// set before and after, given the number of methods removed, added and
+// modified, and the number of methods before, see interfaceRelation.
+func methodSetRelation(removed, added, modified, before int) string {
+	var (
+		bonly  = removed + modified
+		aonly  = added + modified
+		common = before - bonly
+	)
+	switch {
+	case bonly == 0:
+		return "after is a superset of before"
+	case aonly == 0:
+		return "after is a subset of before"
+	case common > 0:
+		return "after overlaps before"
+	}
+	return "after is disjoint from before"
+}
+
 // resolveInterface resolves and rewrites an interfaces embedded members.
 // i.e. given an io.ReadCloser, it will return Read(p []byte) (int, error) and
 // Close() error
-func resolveInterface(uses map[*ast.Ident]types.Object, iface *ast.InterfaceType) error {
+func resolveInterface(uses map[*ast.Ident]types.Object, pkg *types.Package, iface *ast.InterfaceType) error {
 	var rmi []int
 	for i, m := range iface.Methods.List {
 		if len(m.Names) > 0 {
 			continue
 		}
-		newIface, err := exprInterfaceType(uses, m.Type)
+		if isTypeSetElement(uses, m.Type) {
+			// type set elements are compared by typeSetChange
+			continue
+		}
+		newIface, err := exprInterfaceType(uses, pkg, m.Type)
 		if err != nil {
 			return err
 		}
@@ -195,7 +651,7 @@ func resolveInterface(uses map[*ast.Ident]types.Object, iface *ast.InterfaceType
 
 	// After adding the signatures, remove the embedded interface
 	for i := len(rmi) - 1; i >= 0; i-- {
-		iface.Methods.List = append(iface.Methods.List[:i], iface.Methods.List[i+1:]...)
+		iface.Methods.List = append(iface.Methods.List[:rmi[i]], iface.Methods.List[rmi[i]+1:]...)
 	}
 
 	return nil
@@ -206,17 +662,253 @@ func (c DeclChecker) checkStruct(before, after *ast.StructType) (DeclChange, err
 	r := c.diffFields(keyOnName, before.Fields.List, after.Fields.List)
 	if r.Removed() {
 		// Fields were removed
-		return breaking("members removed", after.Pos()), nil
-	} else if r.Modified() {
+		return breaking("members removed", after.Pos()).withKind(Removal).withCategory(categoryMembersRemoved), nil
+	}
+	if c.trackWire {
+		if msgs, pos := c.wireChanges(before.Fields.List, after.Fields.List); len(msgs) > 0 {
+			return breaking("wire format changed: "+strings.Join(msgs, "; "), pos).withConfidence(Medium).withCategory(categoryStructTagsChanged), nil
+		}
+	}
+	if r.Modified() {
 		// Fields changed types
-		return breaking("members changed types", r.ModifiedPos()), nil
-	} else if r.Added() {
-		return nonBreaking("members added", r.AddedPos()), nil
+		var fields []string
+		for _, mod := range r.modified {
+			btype, atype := c.typeStrings(mod[0].Type, mod[1].Type)
+			if c.isNamedToAnonymousStruct(mod[0].Type, mod[1].Type) {
+				// references to the named type no longer compile, see isNamedToAnonymousStruct
+				fields = append(fields, fieldChangedMsg(fieldKey(keyOnName, mod[0], 0), btype, "", true))
+				continue
+			}
+			fields = append(fields, fieldChangedMsg(fieldKey(keyOnName, mod[0], 0), btype, atype, false))
+		}
+		return breaking("members changed types: "+strings.Join(fields, "; "), r.ModifiedPos()).withCategory(categoryMembersChanged), nil
+	}
+	tagMsgs, tagBreaking, tagPos := c.tagChanges(before.Fields.List, after.Fields.List)
+	if tagBreaking {
+		return breaking("struct tags removed: "+strings.Join(tagMsgs, "; "), tagPos).withCategory(categoryStructTagsChanged), nil
+	}
+	if c.sizes != nil {
+		if msgs := layoutChanges(c.sizes, c.binfo.TypeOf(before), c.ainfo.TypeOf(after)); len(msgs) > 0 {
+			return breaking("memory layout changed: "+strings.Join(msgs, "; "), after.Pos()).withCategory(categoryStructLayoutChanged), nil
+		}
+	}
+	if !r.Added() {
+		if change, ok := c.fieldsReordered(structFieldNames(before), structFieldNames(after)); ok {
+			change.Pos = after.Pos()
+			return change, nil
+		}
+	}
+	if c.trackZeroValue {
+		if field := zeroValueField(c.binfo.TypeOf(before), c.ainfo.TypeOf(after)); field != nil {
+			msg := fmt.Sprintf("zero value may no longer be usable, added %s field %s", refKind(field.Type()), field.Name())
+			return breaking(msg, after.Pos()).withConfidence(Low).withCategory(categoryZeroValueChanged), nil
+		}
+	}
+	if isEmptyStruct(c.binfo.TypeOf(before)) && !isZeroSize(c.sizes, c.ainfo.TypeOf(after)) {
+		// the empty struct idiom, such as for sets or signals, no longer applies
+		if r.Added() {
+			return nonBreaking("members added: "+noLongerZeroSizeMsg, r.AddedPos()).withKind(Addition).withCategory(categoryMembersAdded), nil
+		}
+		return nonBreaking(noLongerZeroSizeMsg, after.Pos()).withCategory(categoryTypeChanged), nil
+	}
+	if r.Added() {
+		return nonBreaking("members added", r.AddedPos()).withKind(Addition).withCategory(categoryMembersAdded), nil
+	}
+	if len(tagMsgs) > 0 {
+		return nonBreaking("struct tags removed: "+strings.Join(tagMsgs, "; "), tagPos).withCategory(categoryStructTagsChanged), nil
 	}
 	return none(), nil
 }
 
+// noLongerZeroSizeMsg describes an empty struct gaining fields, see checkStruct.
+const noLongerZeroSizeMsg = "no longer zero-size; was empty struct"
+
+// fieldChangedMsg describes a struct's field name changing type from btype to
+// atype, or to an anonymous struct from the named type btype if anonymous is
+// set, which breaks references to the named type.
+func fieldChangedMsg(name, btype, atype string, anonymous bool) string {
+	if anonymous {
+		return fmt.Sprintf("field %s type changed from named %s to anonymous struct", name, btype)
+	}
+	return fmt.Sprintf("field %s: %s → %s", name, btype, atype)
+}
+
+// fieldsReordered returns a change without a position, and true, if a
+// struct's field names, as returned by structFieldNames, are in a different
+// order. Positional struct literals, such as T{a, b}, assign the wrong fields,
+// or no longer compile, and the memory layout changed.
+func (c DeclChecker) fieldsReordered(bnames, anames []string) (DeclChange, bool) {
+	if strings.Join(bnames, ",") == strings.Join(anames, ",") {
+		return DeclChange{}, false
+	}
+	msg := fmt.Sprintf("struct fields reordered: %s → %s", strings.Join(bnames, ", "), strings.Join(anames, ", "))
+	if c.nonBreakingFieldOrder {
+		return nonBreaking(msg, token.NoPos).withCategory(categoryStructFieldsReordered), true
+	}
+	return breaking(msg, token.NoPos).withCategory(categoryStructFieldsReordered), true
+}
+
+// structFieldNames returns the names of a struct's fields in order, including
+// embedded fields, which positional struct literals assign.
+func structFieldNames(s *ast.StructType) []string {
+	var names []string
+	for _, field := range s.Fields.List {
+		if len(field.Names) == 0 {
+			names = append(names, fieldKey(keyOnName, field, 0))
+			continue
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// isEmptyStruct returns true if typ is a struct without any fields, exported
+// or not.
+func isEmptyStruct(typ types.Type) bool {
+	s, ok := typ.(*types.Struct)
+	return ok && s.NumFields() == 0
+}
+
+// isZeroSize returns true if typ occupies no memory according to sizes, or the
+// gc compiler's amd64 sizes if nil. Unknown types are assumed to be zero-size.
+func isZeroSize(sizes types.Sizes, typ types.Type) bool {
+	if typ == nil {
+		return true
+	}
+	if sizes == nil {
+		sizes = types.SizesFor("gc", "amd64")
+	}
+	return sizes.Sizeof(typ) == 0
+}
+
+// checkAlias compares the resolved target types of an alias, as any type
+// expression, such as a selector or map, may refer to the same type.
+func (c DeclChecker) checkAlias(before, after ast.Expr) DeclChange {
+	btype, atype := c.binfo.TypeOf(before), c.ainfo.TypeOf(after)
+	if btype == nil || atype == nil {
+		if c.exprEqual(before, after) {
+			return none()
+		}
+	} else if c.typesEqual(btype, atype) {
+		return none()
+	}
+	bstr, astr := c.typeStrings(before, after)
+	return breaking(fmt.Sprintf("alias changed its target type: %s → %s", bstr, astr), after.Pos()).withCategory(categoryAliasChanged)
+}
+
+// typesEqual returns true if before and after are the same type, where each
+// are from different type checkers, so named types are compared by name.
+func (c DeclChecker) typesEqual(before, after types.Type) bool {
+	// Identical also ignores aliases, such as any and interface{}, but is false
+	// for named types from different type checkers
+	return types.Identical(before, after) || types.TypeString(before, c.qualifier) == types.TypeString(after, c.qualifier)
+}
+
+// typeStrings returns the before and after types of two expressions as
+// strings for use in messages. Types are qualified by package name, unless
+// both strings would be the same, then they're qualified by package path.
+func (c DeclChecker) typeStrings(before, after ast.Expr) (string, string) {
+	bstr := typeString(c.binfo, c.bpkg, before, false)
+	astr := typeString(c.ainfo, c.apkg, after, false)
+	if bstr == astr {
+		bstr = typeString(c.binfo, c.bpkg, before, true)
+		astr = typeString(c.ainfo, c.apkg, after, true)
+	}
+	return bstr, astr
+}
+
+// typeString returns the type of expr as a string, qualified by package name,
+// or path if qualifyPath is true, except for types declared in pkg. If the
+// type is unknown, the expression is returned as written.
+func typeString(info *types.Info, pkg *types.Package, expr ast.Expr, qualifyPath bool) string {
+	typ := info.TypeOf(expr)
+	if typ == nil {
+		return types.ExprString(expr)
+	}
+	return types.TypeString(typ, func(other *types.Package) string {
+		if pkg != nil && other.Path() == pkg.Path() {
+			return ""
+		}
+		if qualifyPath {
+			return other.Path()
+		}
+		return other.Name()
+	})
+}
+
+// zeroValueField returns the first field added to a struct, including
+// unexported fields, which may require initialisation, when all the before
+// struct's fields were usable as their zero value. Returns nil otherwise.
+//
+// This is a heuristic, as methods may initialise fields when required.
+func zeroValueField(before, after types.Type) *types.Var {
+	bstruct, ok := before.(*types.Struct)
+	if !ok {
+		return nil
+	}
+	astruct, ok := after.(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	fields := make(map[string]bool)
+	for i := 0; i < bstruct.NumFields(); i++ {
+		if refKind(bstruct.Field(i).Type()) != "" {
+			// zero value already required initialisation
+			return nil
+		}
+		fields[bstruct.Field(i).Name()] = true
+	}
+
+	for i := 0; i < astruct.NumFields(); i++ {
+		field := astruct.Field(i)
+		if !fields[field.Name()] && refKind(field.Type()) != "" {
+			return field
+		}
+	}
+	return nil
+}
+
+// refKind returns the kind of a type whose zero value is nil, such as map, or
+// an empty string if the type's zero value isn't nil.
+func refKind(typ types.Type) string {
+	switch typ.Underlying().(type) {
+	case *types.Map:
+		return "map"
+	case *types.Slice:
+		return "slice"
+	case *types.Chan:
+		return "channel"
+	case *types.Pointer:
+		return "pointer"
+	case *types.Interface:
+		return "interface"
+	case *types.Signature:
+		return "func"
+	}
+	return ""
+}
+
 func (c DeclChecker) checkFunc(before, after *ast.FuncType) (DeclChange, error) {
+	if change, ok := inferenceChange(before, after); ok {
+		return change, nil
+	}
+	if change, ok := c.genericizedChange(before, after); ok {
+		return change, nil
+	}
+	if change, ok := arityChange(before, after); ok {
+		return change, nil
+	}
+	if change, ok := ungenericizedChange(before, after); ok {
+		return change, nil
+	}
+	tightenedMsg, loosenedMsg := c.funcConstraintsChanged(before, after)
+	if tightenedMsg != "" {
+		return breaking(tightenedMsg, after.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), nil
+	}
+
 	// don't compare argument names
 	bparams := stripNames(before.Params.List)
 	aparams := stripNames(after.Params.List)
@@ -227,14 +919,35 @@ func (c DeclChecker) checkFunc(before, after *ast.FuncType) (DeclChange, error)
 	if err != nil {
 		return DeclChange{}, err
 	}
-	if r.Changed() {
-		return breaking("parameter types changed", after.Pos()), nil
+	// parameters collapsed into one are only reported if the results are
+	// unchanged, as a change to the results may also be breaking
+	collapsed, isCollapsed := c.collapsedParamsChange(before, after)
+	if r.Changed() && !isCollapsed {
+		if msg := c.variadicChangedMsg(bparams, aparams); msg != "" {
+			return breaking(msg, after.Pos()).withCategory(categoryVariadicChanged), nil
+		}
+		if msg := c.optionsAddedMsg(bparams, aparams); msg != "" {
+			return breaking(msg, after.Pos()).withCategory(categoryParametersChanged), nil
+		}
+		if msg := c.paramsChangedMsg(r, aparams, c.underlyingNote); msg != "" {
+			return breaking(msg, after.Pos()).withCategory(categoryParametersChanged), nil
+		}
+		if msg := c.paramsChangedMsg(r, aparams, c.pointerNote); msg != "" {
+			return breaking(msg, after.Pos()).withCategory(categoryParametersChanged), nil
+		}
+		if msg := c.paramsChangedMsg(r, aparams, c.sliceElemNote); msg != "" {
+			return breaking(msg, after.Pos()).withCategory(categoryParametersChanged), nil
+		}
+		return breaking("parameter types changed", after.Pos()).withCategory(categoryParametersChanged), nil
 	}
 
 	if before.Results != nil {
 		if after.Results == nil {
 			// removed return parameter
-			return breaking("removed return parameter", after.Pos()), nil
+			if len(before.Results.List) == 1 && c.isError(before.Results.List[0].Type) {
+				return breaking(removedErrorMsg, after.Pos()).withCategory(categoryResultsChanged), nil
+			}
+			return breaking("removed return parameter", after.Pos()).withCategory(categoryResultsChanged), nil
 		}
 
 		// don't compare argument names
@@ -245,22 +958,565 @@ func (c DeclChecker) checkFunc(before, after *ast.FuncType) (DeclChange, error)
 		// ok, so only check if for breaking changes if there was parameters before
 		if len(before.Results.List) > 0 {
 			r := c.diffFields(keyOnPosition, bresults, aresults)
+			if len(r.removed) == 1 && !r.Added() && !r.Modified() && c.isError(r.removed[0].Type) {
+				return breaking(removedErrorMsg, after.Pos()).withCategory(categoryResultsChanged), nil
+			}
 			if r.Changed() {
-				return breaking("return parameters changed", after.Pos()), nil
+				if msg := c.insertedBeforeErrorMsg(bresults, aresults); msg != "" {
+					return breaking(msg, after.Pos()).withCategory(categoryResultsChanged), nil
+				}
+				if msg := c.interfaceToConcreteMsg(r); msg != "" {
+					return breaking(msg, after.Pos()).withConfidence(Medium).withCategory(categoryResultsChanged), nil
+				}
+				if msg := c.resultsChangedMsg(r, aresults); msg != "" {
+					return breaking(msg, after.Pos()).withCategory(categoryResultsChanged), nil
+				}
+				return breaking("return parameters changed", after.Pos()).withCategory(categoryResultsChanged), nil
 			}
 		}
 	}
 
 	switch {
+	case isCollapsed:
+		return collapsed, nil
 	case interfaceMsg != "":
-		return nonBreaking(interfaceMsg, after.Pos()), nil
+		return nonBreaking(interfaceMsg, after.Pos()).withCategory(categoryInterfaceCompatible), nil
 	case variadicMsg != "":
-		return nonBreaking(variadicMsg, after.Pos()), nil
+		return nonBreaking(variadicMsg, after.Pos()).withCategory(categoryVariadicChanged), nil
+	case loosenedMsg != "":
+		return nonBreaking(loosenedMsg, after.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), nil
 	default:
 		return none(), nil
 	}
 }
 
+// variadicChangedMsg returns a message describing a change to a trailing
+// variadic parameter in bparams, such as it becoming a regular parameter or
+// moving position, or an empty string if before wasn't variadic or the
+// variadic parameter was unchanged. Such changes alter how existing callers'
+// arguments map to parameters.
+func (c DeclChecker) variadicChangedMsg(bparams, aparams []*ast.Field) string {
+	if len(bparams) == 0 {
+		return ""
+	}
+	bpos := len(bparams) - 1
+	bvariadic, ok := bparams[bpos].Type.(*ast.Ellipsis)
+	if !ok {
+		return ""
+	}
+
+	apos := len(aparams) - 1
+	var avariadic *ast.Ellipsis
+	if apos >= 0 {
+		avariadic, _ = aparams[apos].Type.(*ast.Ellipsis)
+	}
+
+	switch {
+	case avariadic != nil && apos != bpos:
+		return fmt.Sprintf("variadic parameter moved from position %d to %d: %s → %s",
+			bpos+1, apos+1, types.ExprString(bvariadic), types.ExprString(avariadic))
+	case avariadic != nil && elemWidened(c.ainfo.TypeOf(avariadic.Elt), c.binfo.TypeOf(bvariadic.Elt), c.qualifier):
+		// callers passing values not of the narrower element type break
+		return fmt.Sprintf("variadic parameter %d element type narrowed: %s → %s",
+			bpos+1, types.ExprString(bvariadic), types.ExprString(avariadic))
+	case avariadic != nil && !c.exprEqual(bvariadic.Elt, avariadic.Elt):
+		return fmt.Sprintf("variadic parameter %d changed type: %s → %s",
+			bpos+1, types.ExprString(bvariadic), types.ExprString(avariadic))
+	case avariadic == nil && bpos < len(aparams):
+		return fmt.Sprintf("variadic parameter %d changed to non-variadic: %s → %s",
+			bpos+1, types.ExprString(bvariadic), types.ExprString(aparams[bpos].Type))
+	case avariadic == nil:
+		return fmt.Sprintf("variadic parameter %d removed: %s", bpos+1, types.ExprString(bvariadic))
+	}
+	return ""
+}
+
+// optionsAddedMsg returns a message describing a single struct parameter
+// appended to bparams, such as Do() becoming Do(o Options), or an empty string
+// if the parameters changed otherwise. Such a parameter is likely an options
+// struct, so callers can migrate by passing its zero value.
+func (c DeclChecker) optionsAddedMsg(bparams, aparams []*ast.Field) string {
+	n := len(bparams)
+	if len(aparams) != n+1 {
+		return ""
+	}
+	for i := 0; i < n; i++ {
+		if !c.exprEqual(bparams[i].Type, aparams[i].Type) {
+			return ""
+		}
+	}
+	added := aparams[n].Type
+	typ := c.ainfo.TypeOf(added)
+	if typ == nil {
+		return ""
+	}
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return ""
+	}
+	return fmt.Sprintf("added options struct parameter %s; pass %s{} to migrate",
+		types.ExprString(added), types.ExprString(added))
+}
+
+// insertedBeforeErrorMsg returns a message describing a single result being
+// inserted before a trailing error result, such as (V, error) becoming
+// (V, bool, error), or an empty string if the results changed otherwise.
+func (c DeclChecker) insertedBeforeErrorMsg(bresults, aresults []*ast.Field) string {
+	n := len(bresults)
+	if n == 0 || len(aresults) != n+1 {
+		return ""
+	}
+	if !c.isError(bresults[n-1].Type) || !isErrorInterface(c.ainfo.TypeOf(aresults[n].Type)) {
+		return ""
+	}
+	for i := 0; i < n-1; i++ {
+		if !c.exprEqual(bresults[i].Type, aresults[i].Type) {
+			return ""
+		}
+	}
+	_, inserted := c.typeStrings(bresults[n-1].Type, aresults[n-1].Type)
+	return fmt.Sprintf("inserted return value %s before error; update call sites to capture the new value", inserted)
+}
+
+// resultsAddedMsg returns a message describing results appended to a
+// function's existing results, aresults, and the call sites which no longer
+// compile, those assigning each of the previous results.
+func (c DeclChecker) resultsAddedMsg(r diffResult, aresults []*ast.Field) string {
+	added := make(map[*ast.Field]bool)
+	for _, field := range r.added {
+		added[field] = true
+	}
+	var typs []string
+	for _, field := range aresults {
+		if added[field] {
+			typs = append(typs, typeString(c.ainfo, c.apkg, field.Type, false))
+		}
+	}
+
+	var (
+		previous = len(aresults) - len(r.added)
+		impact   string
+	)
+	switch previous {
+	case 1:
+		impact = "call sites using the single result, such as v := f(), no longer compile"
+	default:
+		vars := make([]string, previous)
+		for i := range vars {
+			vars[i] = string(rune('a' + i%26))
+		}
+		impact = fmt.Sprintf("call sites assigning %d results, such as %s := f(), no longer compile", previous, strings.Join(vars, ", "))
+	}
+	noun := "value"
+	if len(typs) > 1 {
+		noun = "values"
+	}
+	return fmt.Sprintf("added return %s %s; %s", noun, strings.Join(typs, ", "), impact)
+}
+
+// paramsChangedMsg returns a message describing each modified parameter in
+// r by its position in aparams, starting at 1, and its before and after types,
+// with a note from describe explaining who breaks, or an empty string if any
+// parameters were added or removed, or describe returns false for any
+// modified parameter.
+func (c DeclChecker) paramsChangedMsg(r diffResult, aparams []*ast.Field, describe func(before, after ast.Expr) (string, bool)) string {
+	if r.Added() || r.Removed() {
+		return ""
+	}
+	var msgs []string
+	for _, modified := range r.modified {
+		before, after := modified[0].Type, modified[1].Type
+		note, ok := describe(before, after)
+		if !ok {
+			return ""
+		}
+		bstr, astr := c.typeStrings(before, after)
+		for i, afield := range aparams {
+			if afield == modified[1] {
+				msgs = append(msgs, fmt.Sprintf("parameter %d changed %s → %s (%s)", i+1, bstr, astr, note))
+			}
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// underlyingNote describes a parameter changing from a named type to its
+// underlying type, such as time.Duration to int64, see paramsChangedMsg.
+// Callers passing values of the named type break without a conversion.
+func (c DeclChecker) underlyingNote(before, after ast.Expr) (string, bool) {
+	if !c.isNamedToUnderlying(before, after) {
+		return "", false
+	}
+	return "named type to its underlying type, callers passing the named type break", true
+}
+
+// pointerNote describes a parameter changing between a pointer and a value of
+// the same type, such as *Buffer and Buffer, including whether nil is
+// accepted, see paramsChangedMsg.
+func (c DeclChecker) pointerNote(before, after ast.Expr) (string, bool) {
+	if bstar, ok := before.(*ast.StarExpr); ok && c.exprEqual(bstar.X, after) {
+		return "nil no longer accepted, callers passing a pointer break", true
+	}
+	if astar, ok := after.(*ast.StarExpr); ok && c.exprEqual(before, astar.X) {
+		return "nil now accepted, callers passing a value break", true
+	}
+	return "", false
+}
+
+// collapsedParamsChange returns a change if consecutive parameters of the
+// same type were collapsed into a single slice or variadic parameter of that
+// element type, such as Sum(a, b int) becoming Sum(nums []int). Callers must
+// wrap their arguments in a slice, so it's breaking, but calls to a variadic
+// parameter, such as Sum(nums ...int), still compile, so that's non-breaking.
+func (c DeclChecker) collapsedParamsChange(before, after *ast.FuncType) (DeclChange, bool) {
+	btypes, atypes := paramTypes(before.Params), paramTypes(after.Params)
+	n := len(btypes) - len(atypes) + 1 // number of parameters collapsed
+	if n < 2 {
+		return none(), false
+	}
+	for i, atype := range atypes {
+		var elt ast.Expr
+		switch t := atype.(type) {
+		case *ast.ArrayType:
+			if t.Len == nil {
+				elt = t.Elt
+			}
+		case *ast.Ellipsis:
+			elt = t.Elt
+		}
+		if elt == nil || !c.exprsEqual(btypes[:i], atypes[:i]) || !c.exprsEqual(btypes[i+n:], atypes[i+1:]) {
+			continue
+		}
+		for _, btype := range btypes[i : i+n] {
+			if !c.exprEqual(btype, elt) {
+				return none(), false
+			}
+		}
+		if _, ok := atype.(*ast.Ellipsis); ok {
+			msg := fmt.Sprintf("parameters %d-%d collapsed into variadic %s, existing calls still compile", i+1, i+n, types.ExprString(atype))
+			return nonBreaking(msg, after.Pos()).withCategory(categoryVariadicChanged), true
+		}
+		msg := fmt.Sprintf("parameters %d-%d collapsed into slice %s, callers must wrap their arguments", i+1, i+n, types.ExprString(atype))
+		return breaking(msg, after.Pos()).withCategory(categoryParametersChanged), true
+	}
+	return none(), false
+}
+
+// paramTypes returns the type of each of a function's parameters, with a
+// type per name, such as int, int given (a, b int).
+func paramTypes(params *ast.FieldList) []ast.Expr {
+	var exprs []ast.Expr
+	if params == nil {
+		return exprs
+	}
+	for _, field := range params.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			exprs = append(exprs, field.Type)
+		}
+	}
+	return exprs
+}
+
+// exprsEqual returns true if before and after are the same length and each of
+// their expressions are equal, see exprEqual.
+func (c DeclChecker) exprsEqual(before, after []ast.Expr) bool {
+	if len(before) != len(after) {
+		return false
+	}
+	for i := range before {
+		if !c.exprEqual(before[i], after[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sliceElemNote describes a slice parameter changing element type, such as
+// []string to []int, see paramsChangedMsg.
+func (c DeclChecker) sliceElemNote(before, after ast.Expr) (string, bool) {
+	if _, _, ok := c.sliceElemChanged(before, after); !ok {
+		return "", false
+	}
+	return "slice element type changed, callers passing the slice break", true
+}
+
+// checkArray compares the underlying slice or array types of a defined type,
+// such as type Buffer []byte. Changes between a slice and an array are
+// breaking, as arrays can't be appended to, and indexing or slicing beyond
+// their length panics, as are changes to an array's length or the element
+// type.
+func (c DeclChecker) checkArray(before, after *ast.ArrayType) DeclChange {
+	bslice, aslice := before.Len == nil, after.Len == nil
+	switch {
+	case bslice && !aslice:
+		bstr, astr := c.typeStrings(before, after)
+		return breaking(fmt.Sprintf("underlying type changed from slice %s to array %s", bstr, astr), after.Pos()).withCategory(categoryTypeChanged)
+	case !bslice && aslice:
+		bstr, astr := c.typeStrings(before, after)
+		return breaking(fmt.Sprintf("underlying type changed from array %s to slice %s", bstr, astr), after.Pos()).withCategory(categoryTypeChanged)
+	}
+	if !c.exprEqual(before.Elt, after.Elt) {
+		belt, aelt := c.typeStrings(before.Elt, after.Elt)
+		return breaking(fmt.Sprintf("element type changed %s → %s", belt, aelt), after.Pos()).withCategory(categoryTypeChanged)
+	}
+	if !bslice {
+		barray, bok := c.binfo.TypeOf(before).(*types.Array)
+		aarray, aok := c.ainfo.TypeOf(after).(*types.Array)
+		if bok && aok && barray.Len() != aarray.Len() {
+			return breaking(fmt.Sprintf("array length changed from %d to %d", barray.Len(), aarray.Len()), after.Pos()).withCategory(categoryTypeChanged)
+		}
+	}
+	return none()
+}
+
+// sliceElemChanged returns the element types of before and after if both are
+// slices whose element types differ. Arrays aren't slices, as a change in their
+// length is a change distinct from their element type.
+func (c DeclChecker) sliceElemChanged(before, after ast.Expr) (string, string, bool) {
+	bslice, bok := before.(*ast.ArrayType)
+	aslice, aok := after.(*ast.ArrayType)
+	if !bok || !aok || bslice.Len != nil || aslice.Len != nil || c.exprEqual(bslice.Elt, aslice.Elt) {
+		return "", "", false
+	}
+	belt, aelt := c.typeStrings(bslice.Elt, aslice.Elt)
+	return belt, aelt, true
+}
+
+// wrappedInStruct returns the name of the field holding the before type if
+// after is a named struct, such as Result, with a field of the before type,
+// which isn't itself a struct, such as int.
+func (c DeclChecker) wrappedInStruct(before, after ast.Expr) (string, bool) {
+	btype, atype := c.binfo.TypeOf(before), c.ainfo.TypeOf(after)
+	if btype == nil || atype == nil {
+		return "", false
+	}
+	if _, ok := btype.Underlying().(*types.Struct); ok {
+		return "", false
+	}
+	named, ok := atype.(*types.Named)
+	if !ok {
+		return "", false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return "", false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if field := st.Field(i); c.typesEqual(btype, field.Type()) {
+			return field.Name(), true
+		}
+	}
+	return "", false
+}
+
+// isNamedToAnonymousStruct returns true if the before expression is a named
+// struct type, such as Settings or pkg.Settings, and the after expression is
+// an anonymous struct, such as struct{ A int }. Callers referencing the named
+// type, such as to construct the field's value, break even if the anonymous
+// struct has the same fields.
+func (c DeclChecker) isNamedToAnonymousStruct(before, after ast.Expr) bool {
+	if _, ok := after.(*ast.StructType); !ok {
+		return false
+	}
+	switch before.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+	default:
+		return false
+	}
+	named, ok := c.binfo.TypeOf(before).(*types.Named)
+	if !ok {
+		return false
+	}
+	_, ok = named.Underlying().(*types.Struct)
+	return ok
+}
+
+// isNamedToUnderlying returns true if the before expression's type is a named
+// type and the after expression's type is its unnamed underlying type.
+func (c DeclChecker) isNamedToUnderlying(before, after ast.Expr) bool {
+	btype, atype := c.binfo.TypeOf(before), c.ainfo.TypeOf(after)
+	if btype == nil || atype == nil {
+		return false
+	}
+	if _, ok := btype.(*types.Named); !ok {
+		return false
+	}
+	if _, ok := atype.(*types.Named); ok {
+		return false
+	}
+	return types.Identical(btype.Underlying(), atype)
+}
+
+// resultsChangedMsg returns a message describing a change in a function's
+// results, aresults, or an empty string if the change has no more specific
+// description.
+func (c DeclChecker) resultsChangedMsg(r diffResult, aresults []*ast.Field) string {
+	if r.Added() && !r.Removed() && !r.Modified() {
+		return c.resultsAddedMsg(r, aresults)
+	}
+	if r.Added() || r.Removed() {
+		return ""
+	}
+
+	if len(r.modified) == 1 {
+		before, after := r.modified[0][0].Type, r.modified[0][1].Type
+
+		// Unlike parameters, where only callers passing nil or taking an address
+		// break, a result changing between pointer and value breaks any caller
+		// assigning it to a typed variable, or comparing it to nil.
+		if bstar, ok := before.(*ast.StarExpr); ok && c.exprEqual(bstar.X, after) {
+			return fmt.Sprintf("return type changed from %s to %s (callers using nil-check or pointer semantics break)",
+				types.ExprString(before), types.ExprString(after))
+		}
+		if astar, ok := after.(*ast.StarExpr); ok && c.exprEqual(before, astar.X) {
+			return fmt.Sprintf("return type changed from %s to %s (callers using value semantics break)",
+				types.ExprString(before), types.ExprString(after))
+		}
+
+		if c.isNamedToUnderlying(before, after) {
+			bstr, astr := c.typeStrings(before, after)
+			return fmt.Sprintf("return type changed from named type %s to underlying %s", bstr, astr)
+		}
+
+		if belt, aelt, ok := c.sliceElemChanged(before, after); ok {
+			return fmt.Sprintf("return slice element type changed %s → %s", belt, aelt)
+		}
+
+		// Wrapping the result in a struct, such as to later add more results,
+		// is intentional but breaks all callers using the result.
+		if field, ok := c.wrappedInStruct(before, after); ok {
+			bstr, astr := c.typeStrings(before, after)
+			return fmt.Sprintf("return value wrapped in struct %s, %s is now field %s", astr, bstr, field)
+		}
+
+		// A type made generic, with the result now a specific instantiation,
+		// breaks callers assigning the result to the non-generic type.
+		if x := indexedType(after); x != nil && types.ExprString(before) == types.ExprString(x) {
+			return fmt.Sprintf("return type %s became generic, now returns instantiation %s (callers assigning to %s break)",
+				types.ExprString(before), types.ExprString(after), types.ExprString(before))
+		}
+
+		// Changing between a concrete error type and the error interface
+		// breaks callers depending on either type.
+		btype, atype := c.binfo.TypeOf(before), c.ainfo.TypeOf(after)
+		switch {
+		case isConcreteError(btype) && isErrorInterface(atype):
+			return fmt.Sprintf("return type changed from %s to error (callers using type assertions or field access on %s break)",
+				types.ExprString(before), types.ExprString(before))
+		case isErrorInterface(btype) && isConcreteError(atype):
+			return fmt.Sprintf("return type changed from error to %s (callers assigning to error may receive a non-nil error holding a nil %s)",
+				types.ExprString(after), types.ExprString(after))
+		}
+	}
+
+	// describe each modified result by its position, starting at 1
+	var msgs []string
+	for _, modified := range r.modified {
+		for i, afield := range aresults {
+			if afield != modified[1] {
+				continue
+			}
+			bstr, astr := c.typeStrings(modified[0].Type, modified[1].Type)
+			msgs = append(msgs, resultChangedMsg(i, bstr, astr))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// resultChangedMsg describes the result at index i changing type from btype to
+// atype, see resultsChangedMsg.
+func resultChangedMsg(i int, btype, atype string) string {
+	return fmt.Sprintf("return value %d changed: %s → %s", i+1, btype, atype)
+}
+
+// interfaceToConcreteMsg returns a message describing a single result
+// changing from an interface to a concrete type which implements it, such as
+// Store becoming *memStore, or an empty string if the results changed
+// otherwise.
+//
+// Such a change is only partially compatible: the result is still assignable
+// to the interface, so callers assigning it to a variable of the interface type
+// or passing it to a function accepting it still compile. But callers using a
+// type switch or assertion on the result, assigning the function to a variable
+// of its previous type, or reassigning another implementation to a variable
+// declared with :=, no longer compile. As the common uses are compatible, it's
+// reported as breaking with Medium confidence.
+//
+// The error interface is excluded, see resultsChangedMsg.
+func (c DeclChecker) interfaceToConcreteMsg(r diffResult) string {
+	if r.Added() || r.Removed() || len(r.modified) != 1 {
+		return ""
+	}
+	before, after := r.modified[0][0].Type, r.modified[0][1].Type
+	btype, atype := c.binfo.TypeOf(before), c.ainfo.TypeOf(after)
+	if btype == nil || atype == nil || isErrorInterface(btype) || types.IsInterface(atype) {
+		return ""
+	}
+	iface, ok := btype.Underlying().(*types.Interface)
+	if !ok || !implementsByName(atype, iface, c.qualifier) {
+		return ""
+	}
+	bstr, astr := c.typeStrings(before, after)
+	return fmt.Sprintf("return type changed from interface %s to %s, which implements it (assignments to %s still compile, but type switches and assertions on the result break)",
+		bstr, astr, bstr)
+}
+
+// implementsByName returns true if typ's method set has a method with the same
+// name and signature as each of iface's methods. Unlike types.Implements,
+// signatures are compared by their qualified names, so typ and iface may be
+// from different type checkers, such as different revisions, and qualified by
+// q, which qualifies by package path if nil.
+func implementsByName(typ types.Type, iface *types.Interface, q types.Qualifier) bool {
+	mset := types.NewMethodSet(typ)
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		sel := mset.Lookup(m.Pkg(), m.Name())
+		if sel == nil && !m.Exported() {
+			// unexported methods must be from the same package, by path
+			for j := 0; j < mset.Len(); j++ {
+				if obj := mset.At(j).Obj(); obj.Name() == m.Name() && obj.Pkg().Path() == m.Pkg().Path() {
+					sel = mset.At(j)
+				}
+			}
+		}
+		if sel == nil || types.TypeString(sel.Type(), q) != types.TypeString(m.Type(), q) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexedType returns the generic type of an instantiation, such as Node
+// given Node[int], or nil if expr isn't an instantiation.
+func indexedType(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return e.X
+	case *ast.IndexListExpr:
+		return e.X
+	}
+	return nil
+}
+
+// removedErrorMsg describes a function's error result being removed.
+const removedErrorMsg = "removed error return (callers with error handling will fail to compile)"
+
+// isError returns true if the before expression's type is the error interface.
+func (c DeclChecker) isError(before ast.Expr) bool {
+	return isErrorInterface(c.binfo.TypeOf(before))
+}
+
+// isErrorInterface returns true if typ is the error interface.
+func isErrorInterface(typ types.Type) bool {
+	return typ != nil && types.Identical(typ, types.Universe.Lookup("error").Type())
+}
+
+// isConcreteError returns true if typ is not an interface, but implements
+// error.
+func isConcreteError(typ types.Type) bool {
+	errIface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return typ != nil && !types.IsInterface(typ) && types.Implements(typ, errIface)
+}
+
 type diffResult struct {
 	added,
 	removed []*ast.Field
@@ -277,7 +1533,17 @@ func (d diffResult) Removed() bool  { return len(d.removed) > 0 }
 func (d diffResult) Modified() bool { return len(d.modified) > 0 }
 
 // No RemovedPos because the removed element's position will not match the after fileset
-func (d diffResult) AddedPos() token.Pos    { return d.added[len(d.added)-1].Pos() }
+func (d diffResult) AddedPos() token.Pos {
+	// added fields are unordered, so use the last by position
+	var pos token.Pos
+	for _, field := range d.added {
+		if field.Pos() > pos {
+			pos = field.Pos()
+		}
+	}
+	return pos
+}
+
 func (d diffResult) ModifiedPos() token.Pos { return d.modified[len(d.modified)-1][1].Pos() }
 
 // RemoveVariadicCompatible removes changes and returns a short msg describing
@@ -302,23 +1568,53 @@ func (d *diffResult) RemoveVariadicCompatible(chkr DeclChecker) (msg string) {
 			d.modified = nil
 			return "change parameter to variadic"
 		}
+
+		bvariadic, bok := btype.(*ast.Ellipsis)
+		if ok && bok && elemWidened(chkr.binfo.TypeOf(bvariadic.Elt), chkr.ainfo.TypeOf(variadic.Elt), chkr.qualifier) {
+			// callers' arguments are assignable to the wider element type
+			d.modified = nil
+			return fmt.Sprintf("variadic parameter element type widened: %s → %s",
+				types.ExprString(bvariadic), types.ExprString(variadic))
+		}
 	}
 	return ""
 }
 
+// elemWidened returns true if after is an interface, other than a constraint,
+// which before implements, such as string and interface{}, so values of type
+// before are assignable to after.
+func elemWidened(before, after types.Type, q types.Qualifier) bool {
+	if before == nil || after == nil || types.TypeString(before, q) == types.TypeString(after, q) {
+		return false
+	}
+	iface, ok := after.Underlying().(*types.Interface)
+	if !ok || !iface.IsMethodSet() {
+		return false
+	}
+	return implementsByName(before, iface, q)
+}
+
 func (d *diffResult) RemoveInterfaceCompatible(chkr DeclChecker) (msg string, err error) {
 	var compatible []int
 	for i, mod := range d.modified {
 		before, after := mod[0].Type, mod[1].Type
 		btype, atype := chkr.binfo.TypeOf(before), chkr.ainfo.TypeOf(after)
+		if isTypeParamOrLit(btype, before) || isTypeParamOrLit(atype, after) {
+			// not a declared interface whose methods can be compared
+			continue
+		}
 		if btype != nil && atype != nil && types.IsInterface(btype) && types.IsInterface(atype) {
-			bint, err := exprInterfaceType(chkr.binfo.Uses, before)
-			if err != nil {
-				return msg, err
-			}
-			aint, err := exprInterfaceType(chkr.ainfo.Uses, after)
-			if err != nil {
-				return msg, err
+			bint, berr := exprInterfaceType(chkr.binfo.Uses, chkr.bpkg, before)
+			aint, aerr := exprInterfaceType(chkr.ainfo.Uses, chkr.apkg, after)
+			if berr != nil || aerr != nil {
+				if chkr.conservative {
+					// leave as modified, as compatibility is unknown
+					continue
+				}
+				if berr != nil {
+					return msg, berr
+				}
+				return msg, aerr
 			}
 
 			change, err := chkr.checkInterface(bint, aint, allowRemoval)
@@ -335,6 +1631,16 @@ func (d *diffResult) RemoveInterfaceCompatible(chkr DeclChecker) (msg string, er
 	return msg, nil
 }
 
+// isTypeParamOrLit returns true if typ is a type parameter, or expr is an
+// interface literal, such as interface{}.
+func isTypeParamOrLit(typ types.Type, expr ast.Expr) bool {
+	if _, ok := typ.(*types.TypeParam); ok {
+		return true
+	}
+	_, ok := expr.(*ast.InterfaceType)
+	return ok
+}
+
 func (d *diffResult) removeModified(rmi []int) {
 	sort.Ints(rmi)
 	for rm := len(rmi) - 1; rm >= 0; rm-- {
@@ -420,12 +1726,12 @@ func fieldKey(keyOn keyOn, field *ast.Field, pos int) string {
 func nameToString(expr ast.Expr) string {
 	switch etype := expr.(type) {
 	case *ast.StarExpr:
-		switch estar := etype.X.(type) {
-		case *ast.SelectorExpr:
-			return fmt.Sprintf("*%s.%s", estar.X, estar.Sel)
-		case *ast.Ident:
-			return "*" + estar.Name
-		}
+		return "*" + nameToString(etype.X)
+	case *ast.IndexExpr:
+		// embedded generic instantiation, named by its generic type
+		return nameToString(etype.X)
+	case *ast.IndexListExpr:
+		return nameToString(etype.X)
 	case *ast.SelectorExpr:
 		return fmt.Sprintf("%s.%s", etype.X, etype.Sel)
 	case *ast.Ident:
@@ -436,17 +1742,47 @@ func nameToString(expr ast.Expr) string {
 
 // exprEqual compares two ast.Expr to determine if they are equal
 func (c DeclChecker) exprEqual(before, after ast.Expr) bool {
+	if isAliasExpr(c.binfo, before) || isAliasExpr(c.ainfo, after) {
+		// an alias is identical to its target, such as int given type
+		// MyInt = int, so compare the targets regardless of how they're written
+		btype, atype := c.binfo.TypeOf(before), c.ainfo.TypeOf(after)
+		if btype != nil && atype != nil {
+			return types.TypeString(types.Unalias(btype), c.qualifier) == types.TypeString(types.Unalias(atype), c.qualifier)
+		}
+	}
 	if reflect.TypeOf(before) != reflect.TypeOf(after) {
 		return false
 	}
 
 	switch before.(type) {
 	case *ast.ChanType:
-		change, _ := c.checkChan(before.(*ast.ChanType), after.(*ast.ChanType))
+		change, err := c.checkChan(before.(*ast.ChanType), after.(*ast.ChanType))
+		if err != nil && c.conservative {
+			return false
+		}
 		return change.Change != Breaking
 	case *ast.FuncType:
-		change, _ := c.checkFunc(before.(*ast.FuncType), after.(*ast.FuncType))
+		change, err := c.checkFunc(before.(*ast.FuncType), after.(*ast.FuncType))
+		if err != nil && c.conservative {
+			return false
+		}
 		return change.Change != Breaking
+	case *ast.IndexExpr:
+		// instantiated generic type with a single type argument
+		bindex, aindex := before.(*ast.IndexExpr), after.(*ast.IndexExpr)
+		return c.exprEqual(bindex.X, aindex.X) && c.exprEqual(bindex.Index, aindex.Index)
+	case *ast.IndexListExpr:
+		// instantiated generic type with multiple type arguments
+		bindex, aindex := before.(*ast.IndexListExpr), after.(*ast.IndexListExpr)
+		if len(bindex.Indices) != len(aindex.Indices) || !c.exprEqual(bindex.X, aindex.X) {
+			return false
+		}
+		for i := range bindex.Indices {
+			if !c.exprEqual(bindex.Indices[i], aindex.Indices[i]) {
+				return false
+			}
+		}
+		return true
 	}
 
 	// types.Identical returns false for any custom types when comparing
@@ -460,17 +1796,47 @@ func (c DeclChecker) exprEqual(before, after ast.Expr) bool {
 	btype := c.binfo.TypeOf(before)
 	atype := c.ainfo.TypeOf(after)
 	if btype == nil || atype == nil {
+		if c.conservative && (btype != nil || atype != nil) {
+			// only one type is known, so they cannot be compared
+			return false
+		}
 		// Maybe nil when using exprInterfaceType which converts ast to string
 		// and back to ast, without type checker knowing.
 		return types.ExprString(before) == types.ExprString(after)
 	}
-	return types.TypeString(btype, nil) == types.TypeString(atype, nil)
+	bparam, bok := btype.(*types.TypeParam)
+	aparam, aok := atype.(*types.TypeParam)
+	if bok && aok {
+		// type parameters are compared by position, as they may be renamed,
+		// such as T becoming E
+		return bparam.Index() == aparam.Index()
+	}
+	return types.TypeString(btype, c.qualifier) == types.TypeString(atype, c.qualifier)
 }
 
-// exprInterfaceType returns a *ast.InterfaceType given an interface type using
-// the worst possible method. It's used to determine whether two interfaces
-// are compatible based on function parameters/results.
-func exprInterfaceType(uses map[*ast.Ident]types.Object, expr ast.Expr) (*ast.InterfaceType, error) {
+// isAliasExpr returns true if expr refers to a type alias, such as MyInt given
+// type MyInt = int, or the predeclared byte.
+func isAliasExpr(info *types.Info, expr ast.Expr) bool {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return false
+	}
+	obj, ok := info.Uses[ident].(*types.TypeName)
+	return ok && obj.IsAlias()
+}
+
+// exprInterfaceType returns a *ast.InterfaceType given an interface type,
+// with a method for each method in the interface's method set, including those
+// of any embedded interfaces. It's used to determine whether two interfaces
+// are compatible based on function parameters/results. Types in the method
+// signatures are qualified by import path, such as example.com/v2/a.T, except
+// those declared in pkg, so types of packages with the same name differ.
+func exprInterfaceType(uses map[*ast.Ident]types.Object, pkg *types.Package, expr ast.Expr) (*ast.InterfaceType, error) {
 	var sel *ast.Ident
 	switch etype := expr.(type) {
 	case *ast.StarExpr:
@@ -492,22 +1858,47 @@ func exprInterfaceType(uses map[*ast.Ident]types.Object, expr ast.Expr) (*ast.In
 	if !ok {
 		return nil, errors.New("could not find interface in uses")
 	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an interface", obj.Name())
+	}
 
-	// obj is: *types.TypeName, string: type io.Writer interface{Write(p []byte) (n int, err error)}
-
-	// Remove the package name from the source in order to parse valid program,
-	// this could be io (for io.Writer) or golang.org/x/net/context, if it's in
-	// universe scope, it's nil
-	src := obj.String()
-	if obj.Pkg() != nil {
-		src = strings.Replace(src, fmt.Sprintf("type %s.", obj.Pkg().Path()), "type ", 1)
+	// Print each method's signature and parse them back, as though they were
+	// declared in the checked package. Import paths aren't identifiers, so
+	// packages are printed as placeholders, replaced by their paths once parsed
+	placeholders := make(map[string]string) // import path to placeholder
+	qualifier := func(other *types.Package) string {
+		if pkg != nil && other.Path() == pkg.Path() {
+			return ""
+		}
+		if _, ok := placeholders[other.Path()]; !ok {
+			placeholders[other.Path()] = fmt.Sprintf("apicompat_pkg%d", len(placeholders))
+		}
+		return placeholders[other.Path()]
+	}
+	var methods []string
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		methods = append(methods, m.Name()+strings.TrimPrefix(types.TypeString(m.Type(), qualifier), "func"))
 	}
-	src = fmt.Sprintf("package expr\n%s", src)
+	src := fmt.Sprintf("package expr\ntype %s interface{%s}", obj.Name(), strings.Join(methods, "; "))
 
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, "", src, 0)
 	if err != nil {
 		return nil, fmt.Errorf("%s parsing: %s", err, src)
 	}
+	paths := make(map[string]string, len(placeholders)) // placeholder to import path
+	for path, placeholder := range placeholders {
+		paths[placeholder] = path
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && paths[x.Name] != "" {
+				x.Name = paths[x.Name]
+			}
+		}
+		return true
+	})
 	return file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.InterfaceType), nil
 }
@@ -0,0 +1,138 @@
+package apicompat
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// newInfo returns an empty types.Info.
+func newInfo() *types.Info {
+	return &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+}
+
+// parseDecl parses a single declaration without type checking it.
+func parseDecl(t *testing.T, src string) ast.Decl {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Decls[0]
+}
+
+// TestConservative tests changes which cannot be classified due to missing
+// type information are only treated as breaking when conservative.
+func TestConservative(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(d DeclChecker) (DeclChange, error)
+	}{
+		{
+			name: "exprEqual with one unknown type",
+			check: func(d DeclChecker) (DeclChange, error) {
+				before, after := ast.NewIdent("int"), ast.NewIdent("int")
+				d.binfo.Types[before] = types.TypeAndValue{Type: types.Typ[types.Int]}
+				if !d.exprEqual(before, after) {
+					return breaking("not equal", 0), nil
+				}
+				return none(), nil
+			},
+		},
+		{
+			name: "checkInterface with unresolvable embedded interface",
+			check: func(d DeclChecker) (DeclChange, error) {
+				before := parseDecl(t, "type I interface{ io.Reader }").(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type
+				after := parseDecl(t, "type I interface{ io.Reader }").(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type
+				return d.checkInterface(before.(*ast.InterfaceType), after.(*ast.InterfaceType), disallowRemoval)
+			},
+		},
+		{
+			name: "value spec with unknown object",
+			check: func(d DeclChecker) (DeclChange, error) {
+				return d.Check(parseDecl(t, "var V int"), parseDecl(t, "var V int"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		d := NewDeclChecker(newInfo(), newInfo())
+		change, err := test.check(*d)
+		if err == nil && change.Change == Breaking {
+			t.Errorf("%s: expected error or no breaking change when not conservative, got: %#v", test.name, change)
+		}
+
+		d = NewDeclChecker(newInfo(), newInfo())
+		d.conservative = true
+		change, err = test.check(*d)
+		if err != nil {
+			t.Errorf("%s: unexpected error when conservative: %v", test.name, err)
+		}
+		if change.Change != Breaking {
+			t.Errorf("%s: expected breaking change when conservative, got: %#v", test.name, change)
+		}
+	}
+}
+
+// TestExprEqualTypeArgs tests instantiated generic types are compared by their
+// base type and each type argument.
+func TestExprEqualTypeArgs(t *testing.T) {
+	tests := []struct {
+		before, after string
+		exp           bool
+	}{
+		{"List[int]", "List[int]", true},
+		{"List[int]", "List[string]", false},
+		{"List[int]", "Other[int]", false},
+		{"Map[string, int]", "Map[string, int]", true},
+		{"Map[string, int]", "Map[string, uint]", false},
+		{"Map[string, int]", "Map[int, int]", false},
+		{"Map[string, int]", "List[string]", false},
+	}
+	for _, test := range tests {
+		before, err := parser.ParseExpr(test.before)
+		if err != nil {
+			t.Fatal(err)
+		}
+		after, err := parser.ParseExpr(test.after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewDeclChecker(newInfo(), newInfo())
+		if got := d.exprEqual(before, after); got != test.exp {
+			t.Errorf("before: %q after: %q exp %v got %v", test.before, test.after, test.exp, got)
+		}
+	}
+}
+
+// TestExprInterfaceTypePaths tests the methods of a resolved interface are
+// qualified by import path, so types of packages with the same name differ.
+func TestExprInterfaceTypePaths(t *testing.T) {
+	var exprs []string
+	for _, path := range []string{"example.com/v1/a", "example.com/v2/a"} {
+		apkg := types.NewPackage(path, "a")
+		named := types.NewNamed(types.NewTypeName(token.NoPos, apkg, "T", nil), types.NewStruct(nil, nil), nil)
+		sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(types.NewVar(token.NoPos, nil, "a", named)), nil, false)
+		iface := types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, apkg, "M", sig)}, nil).Complete()
+
+		ident := ast.NewIdent("I")
+		uses := map[*ast.Ident]types.Object{ident: types.NewTypeName(token.NoPos, apkg, "I", iface)}
+		resolved, err := exprInterfaceType(uses, types.NewPackage("example.com/lib", "lib"), ident)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		exprs = append(exprs, types.ExprString(resolved.Methods.List[0].Type))
+	}
+
+	exp := []string{"func(a example.com/v1/a.T)", "func(a example.com/v2/a.T)"}
+	for i := range exp {
+		if exprs[i] != exp[i] {
+			t.Errorf("exp %q got %q", exp[i], exprs[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package apicompat
+
+import "encoding/json"
+
+// The statuses of a set of changes, see Status.
+const (
+	StatusCompatible    = "compatible"     // no additions or breaking changes
+	StatusAdditionsOnly = "additions-only" // additions, but no breaking changes
+	StatusBreaking      = "breaking"       // at least one breaking change
+)
+
+// badgeColors are the shields.io colors of each status.
+var badgeColors = map[string]string{
+	StatusCompatible:    "brightgreen",
+	StatusAdditionsOnly: "yellowgreen",
+	StatusBreaking:      "red",
+}
+
+// Status returns the overall status of changes, such as for a README badge:
+// StatusBreaking if any change is breaking, StatusAdditionsOnly if any
+// declarations or members were added, otherwise StatusCompatible.
+func Status(changes []Change) string {
+	status := StatusCompatible
+	for _, c := range changes {
+		switch {
+		case c.Change == Breaking:
+			return StatusBreaking
+		case isAddition(c):
+			status = StatusAdditionsOnly
+		}
+	}
+	return status
+}
+
+// badge is a shields.io endpoint badge, see https://shields.io/endpoint.
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// BadgeJSON returns a shields.io endpoint badge of the changes' status, such
+// as {"schemaVersion":1,"label":"abi","message":"breaking","color":"red"}.
+func BadgeJSON(changes []Change) []byte {
+	status := Status(changes)
+	b, _ := json.Marshal(badge{SchemaVersion: 1, Label: "abi", Message: status, Color: badgeColors[status]})
+	return b
+}
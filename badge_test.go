@@ -0,0 +1,30 @@
+package apicompat
+
+import "testing"
+
+// TestBadge tests the status and badge of changes.
+func TestBadge(t *testing.T) {
+	var (
+		added    = Change{ID: "A", Change: NonBreaking, Msg: "declaration added", Kind: Addition}
+		other    = Change{ID: "B", Change: NonBreaking, Msg: "compatible interface change"}
+		breaking = Change{ID: "C", Change: Breaking, Msg: "declaration removed", Kind: Removal}
+	)
+	tests := []struct {
+		changes []Change
+		status  string
+		badge   string
+	}{
+		{nil, StatusCompatible, `{"schemaVersion":1,"label":"abi","message":"compatible","color":"brightgreen"}`},
+		{[]Change{other}, StatusCompatible, `{"schemaVersion":1,"label":"abi","message":"compatible","color":"brightgreen"}`},
+		{[]Change{other, added}, StatusAdditionsOnly, `{"schemaVersion":1,"label":"abi","message":"additions-only","color":"yellowgreen"}`},
+		{[]Change{added, breaking, other}, StatusBreaking, `{"schemaVersion":1,"label":"abi","message":"breaking","color":"red"}`},
+	}
+	for _, test := range tests {
+		if status := Status(test.changes); status != test.status {
+			t.Errorf("changes: %v exp status %q got %q", test.changes, test.status, status)
+		}
+		if badge := string(BadgeJSON(test.changes)); badge != test.badge {
+			t.Errorf("changes: %v exp badge %s got %s", test.changes, test.badge, badge)
+		}
+	}
+}
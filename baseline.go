@@ -0,0 +1,238 @@
+package apicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// baselineVersion is the version of the baseline format, it must be increased
+// when the format changes in an incompatible way.
+const baselineVersion = 1
+
+// baseline is the serialised form of the packages at a revision.
+type baseline struct {
+	Version  int
+	Revision string
+	Packages []baselinePkg
+}
+
+// baselinePkg is a single package within a baseline.
+type baselinePkg struct {
+	ImportPath string
+	Files      []baselineFile
+}
+
+// baselineFile is a package's file without function bodies or the comments
+// within them. Line directives map declarations back to their original position.
+type baselineFile struct {
+	Name string
+	Src  string
+}
+
+// baselinePrinter prints files with line directives to their source position.
+var baselinePrinter = printer.Config{Mode: printer.SourcePos | printer.TabIndent, Tabwidth: 8}
+
+// ExportBaseline returns the API of an import path at a revision serialised as
+// a baseline, which can later be compared against with CheckAgainstBaseline
+// without access to the revision. If the revision is blank, the VCS's default
+// after revision is used.
+//
+// All declarations, including unexported, are kept so that the baseline can be
+// type checked, as are comments, such as doc comments and build constraints,
+// but function bodies and the comments within them are removed.
+func (c *Checker) ExportBaseline(rel string, recurse bool, rev string) ([]byte, error) {
+	if rev == "" {
+		_, rev = c.vcs.DefaultRevision()
+	}
+	if err := c.setPath(rel, recurse, rev); err != nil {
+		return nil, err
+	}
+
+	c.logf("Exporting baseline revision: %s path: %s recurse: %v\n", rev, c.path, c.recurse)
+
+	paths, err := c.pkgPaths(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	// comments are always exported, as the options checking against the
+	// baseline may require them
+	parseComments := c.parseComments
+	c.parseComments = true
+	defer func() { c.parseComments = parseComments }()
+
+	b := baseline{Version: baselineVersion, Revision: rev}
+	for _, path := range paths {
+		p, files, err := c.parseFiles(rev, path)
+		if err != nil {
+			if err == errSkipPackage {
+				continue
+			}
+			// skip errors if we're recursing and the error is no buildable sources
+			if !c.recurse || !strings.Contains(err.Error(), "no buildable") {
+				return nil, err
+			}
+			continue
+		}
+
+		bpkg := baselinePkg{ImportPath: p.importPath}
+		for _, file := range files {
+			var bodies []*ast.BlockStmt
+			for _, decl := range file.Decls {
+				if fdecl, ok := decl.(*ast.FuncDecl); ok && fdecl.Body != nil {
+					bodies = append(bodies, fdecl.Body)
+					fdecl.Body = nil
+				}
+			}
+			file.Comments = commentsOutside(file.Comments, bodies)
+
+			var buf bytes.Buffer
+			if err := baselinePrinter.Fprint(&buf, p.fset, file); err != nil {
+				return nil, err
+			}
+			bpkg.Files = append(bpkg.Files, baselineFile{
+				Name: p.fset.Position(file.Pos()).Filename,
+				Src:  buf.String(),
+			})
+		}
+		b.Packages = append(b.Packages, bpkg)
+	}
+	return json.Marshal(b)
+}
+
+// commentsOutside returns the comment groups not within any of the blocks.
+func commentsOutside(comments []*ast.CommentGroup, blocks []*ast.BlockStmt) []*ast.CommentGroup {
+	var kept []*ast.CommentGroup
+	for _, cg := range comments {
+		inside := false
+		for _, block := range blocks {
+			if cg.Pos() >= block.Pos() && cg.End() <= block.End() {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			kept = append(kept, cg)
+		}
+	}
+	return kept
+}
+
+// CheckAgainstBaseline checks an import path at the after revision for
+// changes against a baseline previously returned by ExportBaseline. If the
+// revision is blank, the VCS's default after revision is used. If the
+// Checker's deadline passes, the changes so far are returned with ErrPartial.
+func (c *Checker) CheckAgainstBaseline(rel string, recurse bool, afterRev string, baseline []byte) ([]Change, error) {
+	if afterRev == "" {
+		_, afterRev = c.vcs.DefaultRevision()
+	}
+	if err := c.setPath(rel, recurse, afterRev); err != nil {
+		return nil, err
+	}
+
+	c.logf("import path: %q baseline after: %q recursive: %v\n", c.path, afterRev, c.recurse)
+
+	var err error
+	if c.b, err = parseBaseline(baseline, c.apiFiles, c.parseMode()); err != nil {
+		return nil, err
+	}
+	if c.a, err = c.parse(afterRev); err != nil {
+		return nil, err
+	}
+
+	changes, err := c.compare()
+	if err != nil && err != ErrPartial {
+		return nil, err
+	}
+	c.logf("Changes detected: %v\n", len(changes))
+
+	return changes, err
+}
+
+// parseBaseline parses, with mode, and type checks the packages in a serialised
+// baseline, keeping only declarations in apiFiles, if set.
+func parseBaseline(data []byte, apiFiles []string, mode parser.Mode) (map[string]pkg, error) {
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("could not decode baseline: %v", err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d, expected %d", b.Version, baselineVersion)
+	}
+
+	pkgs := make(map[string]pkg)
+	for _, bpkg := range b.Packages {
+		var (
+			p     = pkg{importPath: bpkg.ImportPath, fset: token.NewFileSet()}
+			files []*ast.File
+		)
+		for _, bfile := range bpkg.Files {
+			file, err := parser.ParseFile(p.fset, bfile.Name, bfile.Src, mode)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse baseline file %q: %s", bfile.Name, err)
+			}
+			removeLineDirectives(file)
+			files = append(files, file)
+		}
+
+		p, err := checkFiles(p, files, importer.Default(), apiFiles)
+		if err != nil {
+			return nil, err
+		}
+		pkgs[p.importPath] = p
+	}
+	return pkgs, nil
+}
+
+// removeLineDirectives removes the line directives printed by ExportBaseline
+// from a baseline file's comments, which would otherwise be part of the doc
+// comment of the declaration they precede.
+func removeLineDirectives(file *ast.File) {
+	var comments []*ast.CommentGroup
+	for _, cg := range file.Comments {
+		var list []*ast.Comment
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, "//line ") {
+				list = append(list, c)
+			}
+		}
+		if cg.List = list; len(list) > 0 {
+			comments = append(comments, cg)
+		}
+	}
+	file.Comments = comments
+
+	// nonEmpty returns nil if a group contained only line directives
+	nonEmpty := func(cg *ast.CommentGroup) *ast.CommentGroup {
+		if cg != nil && len(cg.List) == 0 {
+			return nil
+		}
+		return cg
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.File:
+			n.Doc = nonEmpty(n.Doc)
+		case *ast.FuncDecl:
+			n.Doc = nonEmpty(n.Doc)
+		case *ast.GenDecl:
+			n.Doc = nonEmpty(n.Doc)
+		case *ast.ImportSpec:
+			n.Doc, n.Comment = nonEmpty(n.Doc), nonEmpty(n.Comment)
+		case *ast.ValueSpec:
+			n.Doc, n.Comment = nonEmpty(n.Doc), nonEmpty(n.Comment)
+		case *ast.TypeSpec:
+			n.Doc, n.Comment = nonEmpty(n.Doc), nonEmpty(n.Comment)
+		case *ast.Field:
+			n.Doc, n.Comment = nonEmpty(n.Doc), nonEmpty(n.Comment)
+		}
+		return true
+	})
+}
@@ -0,0 +1,111 @@
+package apicompat
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestBaseline tests that checking against an exported baseline produces the
+// same changes as checking against the baseline's revision.
+func TestBaseline(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/lib",
+		map[string]string{
+			"lib.go": "package lib\n\nimport \"io\"\n\nconst A int = 1\n\ntype s struct{ r io.Reader }\n\n" +
+				"func F(a int) s {\n\treturn s{}\n}\n\nfunc Removed() {}\n",
+		},
+		map[string]string{
+			"lib.go": "package lib\n\nimport \"io\"\n\nconst A uint = 1\n\ntype s struct{ r io.Reader }\n\n" +
+				"func F(a int, b int) s {\n\treturn s{}\n}\n\nfunc Added(string) {}\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/lib")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := New(SetVCS(git)).Check(".", false, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error checking revisions: %v", err)
+	}
+
+	baseline, err := New(SetVCS(git)).ExportBaseline(".", false, "HEAD~1")
+	if err != nil {
+		t.Fatalf("unexpected error exporting baseline: %v", err)
+	}
+
+	changes, err := New(SetVCS(git)).CheckAgainstBaseline(".", false, "HEAD", baseline)
+	if err != nil {
+		t.Fatalf("unexpected error checking baseline: %v", err)
+	}
+
+	if len(changes) != 4 || len(changes) != len(exp) {
+		t.Fatalf("exp 4 changes, got %d from revisions and %d from baseline", len(exp), len(changes))
+	}
+	for i := range exp {
+		// Compare the printed changes, as declarations are from different parses
+		if exp[i].String() != changes[i].String() {
+			t.Errorf("change %d from revisions:\n%s\ndoes not match baseline:\n%s", i, exp[i], changes[i])
+		}
+		exp[i].Before, exp[i].After, changes[i].Before, changes[i].After = nil, nil, nil, nil
+		if !reflect.DeepEqual(exp[i], changes[i]) {
+			t.Errorf("change %d from revisions:\n%#v\ndoes not match baseline:\n%#v", i, exp[i], changes[i])
+		}
+	}
+
+	if _, err := New(SetVCS(git)).CheckAgainstBaseline(".", false, "HEAD", []byte(`{"Version":0}`)); err == nil {
+		t.Errorf("expected error for unsupported baseline version")
+	}
+}
+
+// TestBaselineComments tests that doc comments are kept in a baseline, so
+// options using them produce the same changes as checking against the
+// baseline's revision, but comments within function bodies are removed.
+func TestBaselineComments(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/lib",
+		map[string]string{
+			"lib.go": "//go:build !ignore\n\npackage lib\n\n// F is safe for concurrent use.\nfunc F() {\n\t// Internal: not a doc comment.\n}\n\n" +
+				"// Internal: G is for tests.\nfunc G(int) {}\n",
+		},
+		map[string]string{
+			"lib.go": "//go:build !ignore\n\npackage lib\n\n// F does things.\nfunc F() {}\n\nfunc G(uint) {}\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/lib")()
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []func(*Checker){SetVCS(git), SetInternalMarker("Internal:"), SetTrackConcurrencyDocs(true)}
+
+	exp, err := New(opts...).Check(".", false, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error checking revisions: %v", err)
+	}
+
+	baseline, err := New(SetVCS(git)).ExportBaseline(".", false, "HEAD~1")
+	if err != nil {
+		t.Fatalf("unexpected error exporting baseline: %v", err)
+	}
+	if strings.Contains(string(baseline), "not a doc comment") {
+		t.Errorf("baseline contains comment within function body: %s", baseline)
+	}
+
+	changes, err := New(opts...).CheckAgainstBaseline(".", false, "HEAD", baseline)
+	if err != nil {
+		t.Fatalf("unexpected error checking baseline: %v", err)
+	}
+
+	if len(changes) != 1 || len(changes) != len(exp) {
+		t.Fatalf("exp 1 change, got %d from revisions and %d from baseline: %v", len(exp), len(changes), changes)
+	}
+	if exp[0].String() != changes[0].String() {
+		t.Errorf("change from revisions:\n%s\ndoes not match baseline:\n%s", exp[0], changes[0])
+	}
+}
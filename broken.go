@@ -0,0 +1,76 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// brokenChanges returns a change for each declaration removed from another
+// checked package which caused the after package, apkg, to fail type
+// checking. removed maps a package's import path to the IDs of its removed
+// declarations. If any type error cannot be attributed to a removal, the type
+// error is returned, as the package is broken for another reason.
+func (c Checker) brokenChanges(apkg pkg, removed map[string][]string) ([]Change, error) {
+	var changes []Change
+	for _, terr := range apkg.typeErr.Errs {
+		change, ok := c.brokenChange(apkg, terr, removed)
+		if !ok {
+			return nil, apkg.typeErr
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// brokenChange returns the change for the removed declaration which caused
+// the type error terr in apkg, if any. The declaration's package is matched by
+// import path, as apkg may import it by another name.
+func (c Checker) brokenChange(apkg pkg, terr types.Error, removed map[string][]string) (Change, bool) {
+	rpkg, id, ok := undefinedImport(apkg.typeErr.pkg, terr)
+	if !ok || rpkg == apkg.importPath {
+		return Change{}, false
+	}
+	bpkg := c.b[rpkg]
+	if bpkg.types == nil {
+		return Change{}, false
+	}
+	for _, rid := range removed[rpkg] {
+		if rid != id {
+			continue
+		}
+		ref := bpkg.types.Name() + "." + id
+		return Change{
+			Pkg:      rpkg,
+			ID:       id,
+			Change:   Breaking,
+			Msg:      fmt.Sprintf("%s removed, breaks package %s", ref, apkg.importPath),
+			Pos:      pos(terr.Fset, terr.Pos),
+			Category: categoryDeclarationRemoved,
+		}, true
+	}
+	return Change{}, false
+}
+
+// undefinedImport returns the import path and ID of the package level
+// declaration a type error, such as "undefined: foo.Bar", refers to, by
+// resolving the name foo in the scope of the erroneous file of the partially
+// type checked package p.
+func undefinedImport(p *types.Package, terr types.Error) (path, id string, ok bool) {
+	ref := strings.TrimPrefix(terr.Msg, "undefined: ")
+	dot := strings.Index(ref, ".")
+	if p == nil || ref == terr.Msg || dot < 0 {
+		return "", "", false
+	}
+	name, id := ref[:dot], ref[dot+1:]
+	for i := 0; i < p.Scope().NumChildren(); i++ {
+		file := p.Scope().Child(i)
+		if !file.Contains(terr.Pos) {
+			continue
+		}
+		if pname, ok := file.Lookup(name).(*types.PkgName); ok {
+			return pname.Imported().Path(), id, true
+		}
+	}
+	return "", "", false
+}
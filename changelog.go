@@ -0,0 +1,43 @@
+package apicompat
+
+// Additions returns the changes which add declarations or members, such as
+// for a changelog's "Added" section.
+func Additions(changes []Change) []Change {
+	return filterChanges(changes, isAddition)
+}
+
+// Removals returns the changes which remove packages, declarations or members,
+// such as for a changelog's "Removed" section.
+func Removals(changes []Change) []Change {
+	return filterChanges(changes, isRemoval)
+}
+
+// Modifications returns the changes which are neither additions nor removals,
+// such as for a changelog's "Changed" section.
+func Modifications(changes []Change) []Change {
+	return filterChanges(changes, func(c Change) bool {
+		return !isAddition(c) && !isRemoval(c)
+	})
+}
+
+// filterChanges returns the changes for which keep returns true.
+func filterChanges(changes []Change, keep func(Change) bool) []Change {
+	var filtered []Change
+	for _, c := range changes {
+		if keep(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// isAddition returns true if the change added a declaration or members.
+func isAddition(c Change) bool {
+	return c.Kind == Addition
+}
+
+// isRemoval returns true if the change removed a package, declaration or
+// members.
+func isRemoval(c Change) bool {
+	return c.Kind == Removal
+}
@@ -0,0 +1,40 @@
+package apicompat
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestChangelog tests changes are partitioned into additions, removals and
+// modifications, regardless of options changing their messages.
+func TestChangelog(t *testing.T) {
+	const (
+		before = "package lib\ntype S struct{ A, B int }\ntype T struct{ A int }\nfunc F(int) {}\nfunc NewT() {}\nfunc Removed() {}\n"
+		after  = "package lib\ntype S struct{ A int }\ntype T struct{ A, B int }\nfunc F(uint) {}\nfunc Added() {}\n"
+	)
+	ids := func(changes []Change) []string {
+		var ids []string
+		for _, c := range changes {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		filter func([]Change) []Change
+		exp    []string // IDs of changes
+	}{
+		{"additions", Additions, []string{"Added", "T"}},
+		{"removals", Removals, []string{"NewT", "Removed", "S"}},
+		{"modifications", Modifications, []string{"F"}},
+	}
+	for _, strict := range []bool{false, true} {
+		changes := checkStrVCS(t, before, after, SetStrictConsumer(strict))
+		for _, test := range tests {
+			if got := ids(test.filter(changes)); !reflect.DeepEqual(got, test.exp) {
+				t.Errorf("strict: %v %s: exp %v got %v", strict, test.name, test.exp, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package apicompat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkout is a temporary checkout of the files within a directory at a
+// revision, laid out as a GOPATH, so go/build reads the checked out packages
+// from the file system, see SetMaterialize and buildContext.
+type checkout struct {
+	gopath string // temporary GOPATH containing the checkout
+	dir    string // directory within gopath the files were checked out to
+}
+
+// contains returns true if path is within the checkout's GOPATH.
+func (co *checkout) contains(path string) bool {
+	rel, err := filepath.Rel(co.gopath, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// checkout writes the files within root at revision rev, including those in
+// subdirectories, to a temporary GOPATH, at root's import path importPath. The
+// caller must remove the returned checkout's GOPATH.
+func (c Checker) checkout(rev, root, importPath string) (*checkout, error) {
+	gopath, err := ioutil.TempDir("", "apicompat")
+	if err != nil {
+		return nil, err
+	}
+	co := &checkout{gopath: gopath, dir: filepath.Join(gopath, "src", filepath.FromSlash(importPath))}
+	c.logf("Checking out revision: %s path: %s to: %s\n", rev, root, co.dir)
+	err = os.MkdirAll(co.dir, 0755)
+	if err == nil {
+		_, err = c.copyDir(rev, root, co.dir, true)
+	}
+	if err != nil {
+		os.RemoveAll(gopath)
+		return nil, err
+	}
+	return co, nil
+}
+
+// copyDir writes the files in src at revision rev, as read by the Checker's
+// go/build context, to dst, recursing into subdirectories if recurse is set.
+// The names of the files written to dst, excluding subdirectories, are
+// returned.
+func (c Checker) copyDir(rev, src, dst string, recurse bool) ([]string, error) {
+	ctx := c.buildContext(rev)
+	files, err := ctx.ReadDir(src)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory %q at revision %q: %s", src, rev, err)
+	}
+	var names []string
+	for _, file := range files {
+		path := filepath.Join(src, file.Name())
+		if file.IsDir() {
+			if !recurse {
+				continue
+			}
+			if err := os.Mkdir(filepath.Join(dst, file.Name()), 0755); err != nil {
+				return nil, err
+			}
+			if _, err := c.copyDir(rev, path, filepath.Join(dst, file.Name()), true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		r, err := ctx.OpenFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read file %q at revision %q: %s", path, rev, err)
+		}
+		contents, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read file %q at revision %q: %s", path, rev, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dst, file.Name()), contents, 0644); err != nil {
+			return nil, err
+		}
+		names = append(names, file.Name())
+	}
+	return names, nil
+}
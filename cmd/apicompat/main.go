@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/bradleyfalzon/apicompat"
 )
@@ -12,6 +13,7 @@ const (
 	exitCodeNoError       = 0
 	exitCodeInternalError = 1
 	exitCodeBreaking      = 2
+	exitCodePartial       = 3
 )
 
 func main() {
@@ -20,7 +22,23 @@ func main() {
 	after := flag.String("after", "", "Compare revision after, leave unset for the VCS default or . to bypass VCS and use filesystem version")
 	excludeFile := flag.String("exclude-file", "", "Exclude files based on regexp pattern")
 	excludeDir := flag.String("exclude-dir", "", "Exclude directory based on regexp pattern")
+	versionFile := flag.String("version-file", "", "Compare against the tag of the version in this file, relative to the repository root, if before is unset")
+	followDeps := flag.Bool("follow-deps", false, "Also compare dependencies within the same module")
+	baseline := flag.Bool("baseline-on-missing", false, "Succeed without changes if the before revision doesn't exist, such as on the first commit")
+	generate := flag.Bool("generate", false, "Run go generate in a temporary checkout of each revision before checking")
+	materialize := flag.Bool("materialize", false, "Check a temporary checkout of each revision instead of reading each file from git")
+	frozen := flag.Bool("frozen", false, "Report added declarations as breaking, such as during an API freeze")
+	frozenAllow := flag.String("frozen-allow", "", "Comma separated glob patterns of declaration IDs which may be added while frozen")
+	sourceURL := flag.String("source-url", "", "Show a link to each change from a template, such as https://github.com/org/repo/blob/{rev}/{file}#L{line}")
+	fieldOrder := flag.Bool("breaking-field-order", true, "Report structs' fields being reordered as breaking, set to false if structs are only initialized by field name")
+	strictConsumer := flag.Bool("strict-consumer", false, "Report every change, including additions, as breaking, such as to review each upgrade of a dependency")
+	timeout := flag.Duration("timeout", 0, "Stop comparing after this duration, not including parsing, such as 2s, and show the changes found so far, exiting with 3 if none are breaking")
 	allChanges := flag.Bool("all", false, "Show all changes, not just breaking")
+	group := flag.Bool("group", false, "Group changes by severity with counts")
+	compact := flag.Bool("compact", false, "Output one tab separated line per change of position, severity, ID and message")
+	hash := flag.Bool("hash", false, "Output a hash of the shown changes, such as to detect unchanged results in CI")
+	markdown := flag.Bool("markdown", false, "Output a Markdown table of changes, such as for a pull request comment")
+	sarif := flag.Bool("sarif", false, "Output changes as a SARIF 2.1.0 log, such as for code scanning")
 	verbose := flag.Bool("v", false, "Enable verbose logging")
 	flag.Parse()
 	path := flag.Arg(0)
@@ -47,22 +65,91 @@ func main() {
 	if *excludeDir != "" {
 		args = append(args, apicompat.SetExcludeDir(*excludeDir))
 	}
+	if *versionFile != "" {
+		args = append(args, apicompat.SetBeforeFromVersionFile(*versionFile))
+	}
+	if *followDeps {
+		args = append(args, apicompat.SetFollowDeps(true))
+	}
+	if *generate {
+		args = append(args, apicompat.SetGenerateBefore(true))
+	}
+	if *materialize {
+		args = append(args, apicompat.SetMaterialize(true))
+	}
+	if *baseline {
+		args = append(args, apicompat.SetBaselineOnMissing(true))
+	}
+	if *frozen {
+		args = append(args, apicompat.SetFrozen(true))
+	}
+	if *frozenAllow != "" {
+		args = append(args, apicompat.SetFrozenAllow(strings.Split(*frozenAllow, ",")))
+	}
+
+	if !*fieldOrder {
+		args = append(args, apicompat.SetBreakingFieldOrder(false))
+	}
+	if *strictConsumer {
+		args = append(args, apicompat.SetStrictConsumer(true))
+	}
+	if *sourceURL != "" {
+		args = append(args, apicompat.SetSourceURLTemplate(*sourceURL))
+	}
+	if *timeout > 0 {
+		args = append(args, apicompat.SetTimeout(*timeout))
+	}
 
 	checker := apicompat.New(args...)
 	changes, err := checker.Check(rel, rec, *before, *after)
+	if err == apicompat.ErrBaselineEstablished {
+		fmt.Println(err)
+		os.Exit(exitCodeNoError)
+	}
+	partial := err == apicompat.ErrPartial
+	if partial {
+		fmt.Fprintln(os.Stderr, err)
+		err = nil
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(exitCodeInternalError)
 	}
 
 	exitCode := exitCodeNoError
+	if partial {
+		// unknown whether the changes not found are breaking
+		exitCode = exitCodePartial
+	}
+	var shown []apicompat.Change
 	for _, change := range changes {
 		switch {
 		case change.Change == apicompat.Breaking:
 			exitCode = exitCodeBreaking
-			fmt.Print(change)
+			shown = append(shown, change)
 		case *allChanges:
+			shown = append(shown, change)
+		}
+	}
+	switch {
+	case *hash:
+		fmt.Println(apicompat.ChangesHash(shown))
+	case *sarif:
+		fmt.Println(string(apicompat.SARIF(shown)))
+	case *markdown:
+		fmt.Print(apicompat.MarkdownReport(shown))
+	case *group:
+		fmt.Print(apicompat.Report(shown))
+	case *compact:
+		for _, change := range shown {
+			fmt.Println(change.Line())
+		}
+	default:
+		for _, change := range shown {
 			fmt.Print(change)
+			if change.URL != "" {
+				fmt.Println(change.URL)
+			}
 		}
 	}
 	os.Exit(exitCode)
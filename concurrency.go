@@ -0,0 +1,77 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// defaultConcurrencyDocPattern matches the default doc comment sentences which
+// describe whether a function or type is safe for concurrent use.
+var defaultConcurrencyDocPattern = `(?i)\b(safe|unsafe)\b.*\b(concurrent|concurrently|goroutines?|threads?)\b|\b(thread|goroutine|concurrency)-safe\b`
+
+// concurrencyDocChange returns a change if the sentences of the before and
+// after declarations' doc comments matching the Checker's concurrency doc
+// pattern differ, such as a function no longer documenting it's safe for
+// concurrent use. Only functions, methods and types are checked.
+func (c Checker) concurrencyDocChange(bDecl, aDecl ast.Decl) (DeclChange, bool) {
+	bdoc, adoc := declDoc(bDecl), declDoc(aDecl)
+	if bdoc == nil && adoc == nil {
+		return DeclChange{}, false
+	}
+	bsentences := c.concurrencySentences(bdoc)
+	asentences := c.concurrencySentences(adoc)
+	if bsentences == asentences {
+		return DeclChange{}, false
+	}
+
+	docPos := aDecl.Pos()
+	if adoc != nil {
+		docPos = adoc.Pos()
+	}
+
+	var msg string
+	switch {
+	case bsentences == "":
+		msg = fmt.Sprintf("concurrency documentation added: %q", asentences)
+	case asentences == "":
+		msg = fmt.Sprintf("concurrency documentation removed: %q", bsentences)
+	default:
+		msg = fmt.Sprintf("concurrency documentation changed: %q → %q", bsentences, asentences)
+	}
+	return nonBreaking(msg, docPos).withCategory(categoryConcurrencyDocChanged), true
+}
+
+// concurrencySentences returns the sentences of doc matching the Checker's
+// concurrency doc pattern, joined by a space. Sentences may span multiple
+// lines.
+func (c Checker) concurrencySentences(doc *ast.CommentGroup) string {
+	text := strings.Join(strings.Fields(doc.Text()), " ")
+	var matched []string
+	for _, sentence := range strings.SplitAfter(text, ". ") {
+		sentence = strings.TrimSpace(sentence)
+		if c.concurrencyDocs.MatchString(sentence) {
+			matched = append(matched, sentence)
+		}
+	}
+	return strings.Join(matched, " ")
+}
+
+// declDoc returns the doc comment of a function, method or type declaration,
+// or nil if there's no doc comment or decl declares something else.
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		s, ok := d.Specs[0].(*ast.TypeSpec)
+		if !ok {
+			return nil
+		}
+		if s.Doc != nil {
+			return s.Doc
+		}
+		return d.Doc
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package apicompat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// pkgConfigFile is the name of a package's configuration file, in the
+// package's directory, see parseConfig.
+const pkgConfigFile = ".apicompat.toml"
+
+// parseConfig parses a package's configuration file, returning the options to
+// apply when comparing the package. The file is a subset of TOML, with a
+// key = value pair per line, and comments starting with #. The keys are:
+//
+//	perspective = "caller"       # or "implementer", see SetInterfacePerspective
+//	include = ["Server.*"]       # see SetIncludeIDs
+//	exclude = ["Internal*"]      # see SetExcludeIDs
+//	frozen = true                # see SetFrozen
+//	frozen_allow = ["Option*"]   # see SetFrozenAllow
+//
+// The options override the Checker's, except exclude patterns, which are added
+// to the Checker's, so a package can't include declarations excluded by the
+// caller.
+//
+// name is the file's name, used in errors.
+func parseConfig(name string, r io.Reader) ([]func(*Checker), error) {
+	var (
+		options []func(*Checker)
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		i := strings.IndexByte(text, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: expected key = value", name, line)
+		}
+		key, value := strings.TrimSpace(text[:i]), stripComment(strings.TrimSpace(text[i+1:]))
+
+		var err error
+		switch key {
+		case "perspective":
+			var s string
+			if s, err = configString(value); err != nil {
+				break
+			}
+			switch s {
+			case "caller":
+				options = append(options, SetInterfacePerspective(PerspectiveCaller))
+			case "implementer":
+				options = append(options, SetInterfacePerspective(PerspectiveImplementer))
+			default:
+				err = fmt.Errorf("unknown perspective %q", s)
+			}
+		case "include", "exclude", "frozen_allow":
+			var patterns []string
+			if patterns, err = configGlobs(value); err != nil {
+				break
+			}
+			switch key {
+			case "include":
+				options = append(options, SetIncludeIDs(patterns))
+			case "exclude":
+				options = append(options, addExcludeIDs(patterns))
+			case "frozen_allow":
+				options = append(options, SetFrozenAllow(patterns))
+			}
+		case "frozen":
+			var frozen bool
+			if frozen, err = strconv.ParseBool(value); err != nil {
+				err = fmt.Errorf("expected true or false, got %s", value)
+				break
+			}
+			options = append(options, SetFrozen(frozen))
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return options, nil
+}
+
+// addExcludeIDs returns an option adding to the Checker's exclude patterns,
+// see SetExcludeIDs.
+func addExcludeIDs(patterns []string) func(*Checker) {
+	return func(c *Checker) {
+		c.excludeIDs = append(c.excludeIDs[:len(c.excludeIDs):len(c.excludeIDs)], patterns...)
+	}
+}
+
+// stripComment removes a trailing comment from a value, such as "a" # comment,
+// ignoring # within quoted strings.
+func stripComment(value string) string {
+	var quoted bool
+	for i, r := range value {
+		switch {
+		case r == '"' && (i == 0 || value[i-1] != '\\'):
+			quoted = !quoted
+		case r == '#' && !quoted:
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// configString parses a quoted string value, such as "caller".
+func configString(value string) (string, error) {
+	s, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		return "", fmt.Errorf("expected quoted string, got %s", value)
+	}
+	return s, nil
+}
+
+// configGlobs parses an array of quoted glob patterns, such as ["A*", "B"].
+func configGlobs(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("expected array of quoted strings, got %s", value)
+	}
+	var patterns []string
+	for _, elem := range strings.Split(value[1:len(value)-1], ",") {
+		elem = strings.TrimSpace(elem)
+		if elem == "" {
+			// empty array, or trailing comma
+			continue
+		}
+		pattern, err := configString(elem)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %v", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
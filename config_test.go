@@ -0,0 +1,58 @@
+package apicompat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		config string
+		exp    Checker
+		err    string // expected error, if not empty
+	}{
+		{config: "", exp: Checker{}},
+		{
+			config: "# comment\n\nperspective = \"caller\"\nfrozen = true # trailing\n" +
+				"include = [\"A*\", \"B\",]\nexclude = []\nfrozen_allow = [\"With#Hash\"]\n",
+			exp: Checker{
+				perspective: PerspectiveCaller,
+				frozen:      true,
+				includeIDs:  []string{"A*", "B"},
+				frozenAllow: []string{"With#Hash"},
+			},
+		},
+		{config: "perspective = \"implementer\"\n", exp: Checker{perspective: PerspectiveImplementer}},
+		{config: "perspective = \"other\"\n", err: `test.toml:1: unknown perspective "other"`},
+		{config: "perspective = caller\n", err: "test.toml:1: expected quoted string, got caller"},
+		{config: "\nunknown = true\n", err: `test.toml:2: unknown key "unknown"`},
+		{config: "frozen\n", err: "test.toml:1: expected key = value"},
+		{config: "frozen = yes\n", err: "test.toml:1: expected true or false, got yes"},
+		{config: "exclude = \"A\"\n", err: `test.toml:1: expected array of quoted strings, got "A"`},
+		{config: "exclude = [\"[\"]\n", err: `test.toml:1: invalid glob pattern "["`},
+	}
+	for _, test := range tests {
+		options, err := parseConfig("test.toml", strings.NewReader(test.config))
+		switch {
+		case test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)):
+			t.Errorf("config: %q exp error %q got: %v", test.config, test.err, err)
+			continue
+		case test.err != "":
+			continue
+		case err != nil:
+			t.Errorf("config: %q unexpected error: %v", test.config, err)
+			continue
+		}
+
+		var c Checker
+		for _, option := range options {
+			option(&c)
+		}
+		if c.perspective != test.exp.perspective || c.frozen != test.exp.frozen ||
+			strings.Join(c.includeIDs, ",") != strings.Join(test.exp.includeIDs, ",") ||
+			strings.Join(c.excludeIDs, ",") != strings.Join(test.exp.excludeIDs, ",") ||
+			strings.Join(c.frozenAllow, ",") != strings.Join(test.exp.frozenAllow, ",") {
+			t.Errorf("config: %q exp options %+v got %+v", test.config, test.exp, c)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// constraintsTightened returns a description of each of a generic type's type
+// parameters whose constraint was tightened, such as any becoming comparable,
+// or an empty string if none were. A tightened constraint breaks callers
+// instantiating the type with type arguments no longer satisfying it, and so
+// any use of its methods. Loosened constraints aren't breaking.
+func (c DeclChecker) constraintsTightened(before, after *types.TypeParamList) string {
+	tightened, _ := c.constraintsChanged(typeParamList(before), typeParamList(after))
+	return tightened
+}
+
+// constraintsLoosened returns a description of each of a generic type's type
+// parameters whose constraint was loosened, such as comparable becoming any,
+// or an empty string if none were, see constraintsTightened.
+func (c DeclChecker) constraintsLoosened(before, after *types.TypeParamList) string {
+	_, loosened := c.constraintsChanged(typeParamList(before), typeParamList(after))
+	return loosened
+}
+
+// funcConstraintsChanged returns descriptions of a generic function's type
+// parameters whose constraints were tightened, which is breaking as callers'
+// type arguments may no longer satisfy them, and of those whose constraints
+// were loosened, which isn't, see constraintsChanged.
+func (c DeclChecker) funcConstraintsChanged(before, after *ast.FuncType) (tightened, loosened string) {
+	return c.constraintsChanged(funcTypeParams(c.binfo, before), funcTypeParams(c.ainfo, after))
+}
+
+// constraintsChanged returns descriptions of the type parameters whose
+// constraints were tightened, and of those whose constraints were loosened.
+// Type parameters are compared by position, and only if there's the same
+// number of them, as other changes are reported by genericMigration,
+// typeParamsChange or arityChange.
+func (c DeclChecker) constraintsChanged(before, after []*types.TypeParam) (tightened, loosened string) {
+	if len(before) == 0 || len(before) != len(after) {
+		return "", ""
+	}
+	var tmsgs, lmsgs []string
+	for i := range before {
+		bconstraint, aconstraint := before[i].Constraint(), after[i].Constraint()
+		if c.isDeclaredConstraint(bconstraint, aconstraint) {
+			// reported once by the constraint's own declaration, which
+			// lists its users, see constraintUsers
+			continue
+		}
+		switch {
+		case constraintTightened(bconstraint, c.bpkg, aconstraint, c.apkg):
+			tmsgs = append(tmsgs, c.constraintMsg(after[i], "tightened", bconstraint, aconstraint))
+		case constraintTightened(aconstraint, c.apkg, bconstraint, c.bpkg):
+			lmsgs = append(lmsgs, c.constraintMsg(after[i], "loosened", bconstraint, aconstraint))
+		}
+	}
+	return strings.Join(tmsgs, "; "), strings.Join(lmsgs, "; ")
+}
+
+// constraintMsg describes a type parameter's constraint changing, such as
+// "type parameter T constraint tightened: any → comparable", or, if the
+// constraint is the same named interface whose definition changed, such as
+// one from another package, how it changed, such as "type parameter T
+// constraint tightened: cmp.Ordered type set removed ~float64".
+func (c DeclChecker) constraintMsg(tparam *types.TypeParam, verb string, before, after types.Type) string {
+	bstr, astr := types.TypeString(before, types.RelativeTo(c.bpkg)), types.TypeString(after, types.RelativeTo(c.apkg))
+	if bstr == astr {
+		return fmt.Sprintf("type parameter %s constraint %s: %s %s", tparam.Obj().Name(), verb, astr,
+			strings.Join(constraintDiff(before, c.bpkg, after, c.apkg), ", "))
+	}
+	return fmt.Sprintf("type parameter %s constraint %s: %s → %s", tparam.Obj().Name(), verb, bstr, astr)
+}
+
+// constraintDiff describes the differences between two constraints, such as
+// "now comparable" or "type set removed ~float64".
+func constraintDiff(before types.Type, bpkg *types.Package, after types.Type, apkg *types.Package) []string {
+	bi, bok := before.Underlying().(*types.Interface)
+	ai, aok := after.Underlying().(*types.Interface)
+	if !bok || !aok {
+		return nil
+	}
+	var diffs []string
+	switch {
+	case ai.IsComparable() && !bi.IsComparable():
+		diffs = append(diffs, "now comparable")
+	case !ai.IsComparable() && bi.IsComparable():
+		diffs = append(diffs, "no longer comparable")
+	}
+	if added := missingMethods(ai, bi, bpkg); len(added) > 0 {
+		diffs = append(diffs, "added methods "+strings.Join(added, ", "))
+	}
+	if removed := missingMethods(bi, ai, apkg); len(removed) > 0 {
+		diffs = append(diffs, "removed methods "+strings.Join(removed, ", "))
+	}
+	bterms, bok := typeTerms(bi, bpkg)
+	aterms, aok := typeTerms(ai, apkg)
+	if !bok || !aok {
+		return diffs
+	}
+	removed, added := typeTermsDiff(bterms, aterms)
+	switch {
+	case len(bterms) == 0 && len(aterms) > 0:
+		diffs = append(diffs, "type set now restricted to "+strings.Join(added, " | "))
+	case len(bterms) > 0 && len(aterms) == 0:
+		diffs = append(diffs, "type set no longer restricted to "+strings.Join(removed, " | "))
+	default:
+		if len(removed) > 0 {
+			diffs = append(diffs, "type set removed "+strings.Join(removed, " | "))
+		}
+		if len(added) > 0 {
+			diffs = append(diffs, "type set added "+strings.Join(added, " | "))
+		}
+	}
+	return diffs
+}
+
+// missingMethods returns the names of iface's methods which other, of pkg,
+// doesn't have, sorted.
+func missingMethods(iface, other *types.Interface, pkg *types.Package) []string {
+	var names []string
+	for i := 0; i < iface.NumMethods(); i++ {
+		if obj, _, _ := types.LookupFieldOrMethod(other, false, pkg, iface.Method(i).Name()); obj == nil {
+			names = append(names, iface.Method(i).Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isDeclaredConstraint returns true if before and after are the same exported
+// named constraint declared by the package being compared, such as Number
+// given func Sum[T Number](), whose changes are reported by its declaration.
+func (c DeclChecker) isDeclaredConstraint(before, after types.Type) bool {
+	bnamed, bok := before.(*types.Named)
+	anamed, aok := after.(*types.Named)
+	if !bok || !aok || c.bpkg == nil || c.apkg == nil {
+		return false
+	}
+	bobj, aobj := bnamed.Obj(), anamed.Obj()
+	return bobj.Name() == aobj.Name() && aobj.Exported() && bobj.Pkg() == c.bpkg && aobj.Pkg() == c.apkg
+}
+
+// constraintUsers returns the exported generic functions and types of the
+// after package with a type parameter constrained by the named interface,
+// sorted, such as Sum and Vector[T] given Number.
+func (c DeclChecker) constraintUsers(name string) []string {
+	if c.apkg == nil {
+		return nil
+	}
+	scope := c.apkg.Scope()
+	constraint, ok := scope.Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	uses := func(tparams *types.TypeParamList) bool {
+		for i := 0; i < tparams.Len(); i++ {
+			if named, ok := tparams.At(i).Constraint().(*types.Named); ok && named.Obj() == constraint {
+				return true
+			}
+		}
+		return false
+	}
+	var users []string
+	for _, id := range scope.Names() {
+		switch obj := scope.Lookup(id).(type) {
+		case *types.Func:
+			if obj.Exported() && uses(obj.Type().(*types.Signature).TypeParams()) {
+				users = append(users, id)
+			}
+		case *types.TypeName:
+			if named, ok := obj.Type().(*types.Named); ok && obj.Exported() && !obj.IsAlias() && uses(named.TypeParams()) {
+				users = append(users, id)
+			}
+		}
+	}
+	return users
+}
+
+// typeParamList returns the type parameters in list, which may be nil.
+func typeParamList(list *types.TypeParamList) []*types.TypeParam {
+	tparams := make([]*types.TypeParam, list.Len())
+	for i := range tparams {
+		tparams[i] = list.At(i)
+	}
+	return tparams
+}
+
+// funcTypeParams returns a function's type parameters, in order.
+func funcTypeParams(info *types.Info, fn *ast.FuncType) []*types.TypeParam {
+	if info == nil || fn.TypeParams == nil {
+		return nil
+	}
+	var tparams []*types.TypeParam
+	for _, field := range fn.TypeParams.List {
+		for _, name := range field.Names {
+			obj := info.Defs[name]
+			if obj == nil {
+				return nil
+			}
+			tparam, ok := obj.Type().(*types.TypeParam)
+			if !ok {
+				return nil
+			}
+			tparams = append(tparams, tparam)
+		}
+	}
+	return tparams
+}
+
+// constraintTightened returns true if some type satisfying the before
+// constraint may not satisfy the after constraint, because after newly
+// requires comparable types, methods not required before, or narrowed its
+// type set, such that a term of before isn't included by any term of after.
+func constraintTightened(before types.Type, bpkg *types.Package, after types.Type, apkg *types.Package) bool {
+	bi, bok := before.Underlying().(*types.Interface)
+	ai, aok := after.Underlying().(*types.Interface)
+	if !bok || !aok {
+		return false
+	}
+	if ai.IsComparable() && !bi.IsComparable() {
+		return true
+	}
+	for i := 0; i < ai.NumMethods(); i++ {
+		if obj, _, _ := types.LookupFieldOrMethod(bi, false, bpkg, ai.Method(i).Name()); obj == nil {
+			return true
+		}
+	}
+	bterms, bok := typeTerms(bi, bpkg)
+	aterms, aok := typeTerms(ai, apkg)
+	if !bok || !aok || len(aterms) == 0 {
+		return false
+	}
+	if len(bterms) == 0 {
+		return true
+	}
+	removed, _ := typeTermsDiff(bterms, aterms)
+	return len(removed) > 0
+}
+
+// typeParams returns the type parameters of the generic type named by ident,
+// such as a type declaration's name or a method's receiver type.
+func typeParams(info *types.Info, ident *ast.Ident) *types.TypeParamList {
+	if ident == nil {
+		return nil
+	}
+	obj := info.ObjectOf(ident)
+	if obj == nil {
+		return nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+	return named.Origin().TypeParams()
+}
@@ -0,0 +1,75 @@
+package apicompat
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// ParseError is returned when a package's file cannot be parsed.
+type ParseError struct {
+	Rev  string // revision of the file
+	File string // name of the file, within the package's directory
+	Err  error  // error from go/parser
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("could not parse file %q at revision %q: %s", e.File, e.Rev, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// BuildError is returned when go/build cannot import a package, such as when
+// it has no buildable Go files at a revision.
+type BuildError struct {
+	Rev        string // revision of the package
+	ImportPath string // import path, or relative directory, of the package
+	Err        error  // error from go/build
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("go/build error: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// TypeError is returned when a package fails to type check.
+type TypeError struct {
+	Rev        string        // revision of the package
+	ImportPath string        // import path of the package
+	Errs       []types.Error // type errors, of which there's at least one
+
+	pkg *types.Package // partially type checked package, to resolve the errors
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("go/types error: %v", e.Errs[0])
+}
+
+// DiffError is returned when a declaration cannot be compared between
+// revisions, such as when the type of an expression cannot be determined.
+type DiffError struct {
+	Pkg    string   // import path of the package
+	Before ast.Decl // declaration at the before revision
+	After  ast.Decl // declaration at the after revision
+	Err    error    // error comparing the declarations
+
+	bfset, afset *token.FileSet // used to print the declarations
+}
+
+// Error returns the error, followed by the before and after declarations' ASTs
+// to help diagnose it.
+func (e *DiffError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "error comparing declarations: %s\n", e.Err)
+	_ = ast.Fprint(&buf, e.bfset, e.Before, ast.NotNilFilter)
+	_ = ast.Fprint(&buf, e.afset, e.After, ast.NotNilFilter)
+	return buf.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *DiffError) Unwrap() error { return e.Err }
@@ -0,0 +1,62 @@
+package apicompat
+
+import (
+	"errors"
+	"go/build"
+	"testing"
+)
+
+// TestErrors tests each failure mode of Check returns its error type.
+func TestErrors(t *testing.T) {
+	const valid = "package lib\nfunc F() {}\n"
+	tests := []struct {
+		before, after string
+		check         func(err error) bool // returns true if err is as expected
+	}{
+		{
+			before: valid,
+			after:  "package lib\nfunc (\n",
+			check: func(err error) bool {
+				var perr *ParseError
+				return errors.As(err, &perr) && perr.Rev == "rev2" && perr.File == "abitest.go"
+			},
+		},
+		{
+			before: "",
+			after:  valid,
+			check: func(err error) bool {
+				var berr *BuildError
+				var nogo *build.NoGoError
+				return errors.As(err, &berr) && berr.Rev == "rev1" && errors.As(err, &nogo)
+			},
+		},
+		{
+			before: valid,
+			after:  "package lib\nvar V undefined\n",
+			check: func(err error) bool {
+				var terr *TypeError
+				return errors.As(err, &terr) && terr.Rev == "rev2" && len(terr.Errs) == 1
+			},
+		},
+		{
+			before: "package lib\ntype I interface{ M() }\nfunc F((I)) {}\n",
+			after:  "package lib\ntype I interface{ M() }\ntype J interface{ M(); N() }\nfunc F((J)) {}\n",
+			check: func(err error) bool {
+				var derr *DiffError
+				return errors.As(err, &derr) && derr.Pkg != "" && derr.Before != nil && derr.After != nil
+			},
+		},
+	}
+	for _, test := range tests {
+		var vcs StrVCS
+		if test.before != "" {
+			vcs.SetFile("rev1", "abitest.go", []byte(test.before))
+		}
+		vcs.SetFile("rev2", "abitest.go", []byte(test.after))
+
+		_, err := New(SetVCS(vcs)).Check("", false, "rev1", "rev2")
+		if err == nil || !test.check(err) {
+			t.Errorf("before: %q after: %q unexpected error: %#v", test.before, test.after, err)
+		}
+	}
+}
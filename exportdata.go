@@ -0,0 +1,141 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// CheckAgainstExportData compares the exported API of the import path's
+// source at a revision against the API in the compiler's export data for the
+// same package, as built from the file system by the go tool. If the revision
+// is blank, the VCS's default after revision is used.
+//
+// Source and export data should have the same API, differences indicate the
+// source that's checked differs from what's compiled, such as files being
+// included or excluded by build tags or cgo.
+func (c *Checker) CheckAgainstExportData(rel string, recurse bool, rev string) ([]Change, error) {
+	if rev == "" {
+		_, rev = c.vcs.DefaultRevision()
+	}
+	if err := c.setPath(rel, recurse, rev); err != nil {
+		return nil, err
+	}
+
+	c.logf("import path: %q revision: %q against export data recursive: %v\n", c.path, rev, c.recurse)
+
+	pkgs, err := c.parse(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, p := range pkgs {
+		if p.dep || p.types == nil {
+			continue
+		}
+		fset := token.NewFileSet()
+		exp, err := importer.ForCompiler(fset, "gc", lookupExportData).Import(p.importPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not import export data for %q: %v", p.importPath, err)
+		}
+		changes = append(changes, compareExportData(p, fset, exp)...)
+	}
+	c.sortChanges(changes)
+
+	c.logf("Changes detected: %v\n", len(changes))
+	return changes, nil
+}
+
+// lookupExportData returns a reader for the export data of the package at
+// import path, building it if required.
+func lookupExportData(path string) (io.ReadCloser, error) {
+	cmd := exec.Command("go", "list", "-export", "-f", "{{.Export}}", path)
+	out, err := cmd.Output()
+	if err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("error running %v: %v output: %q", cmd.Args, err, eerr.Stderr)
+		}
+		return nil, fmt.Errorf("error running %v: %v", cmd.Args, err)
+	}
+	export := strings.TrimSpace(string(out))
+	if export == "" {
+		return nil, fmt.Errorf("no export data for %q", path)
+	}
+	return os.Open(export)
+}
+
+// compareExportData compares the exported objects of a parsed package against
+// the package imported from export data, using fset for export data positions.
+func compareExportData(p pkg, fset *token.FileSet, exp *types.Package) []Change {
+	// Qualify by path, but not the package itself, as the source and export
+	// data are different packages with the same path
+	qualifier := func(other *types.Package) string {
+		if other.Path() == p.importPath {
+			return ""
+		}
+		return other.Path()
+	}
+
+	srcObjs, expObjs := exportedObjects(p.types), exportedObjects(exp)
+
+	var changes []Change
+	for id, obj := range srcObjs {
+		var before ast.Decl
+		if decl, ok := p.decls[id]; ok {
+			before = decl
+		}
+
+		eobj, ok := expObjs[id]
+		if !ok {
+			changes = append(changes, Change{Pkg: p.importPath, ID: id, Change: Breaking, Msg: "declaration not in export data", Pos: pos(p.fset, obj.Pos()), Before: before})
+			continue
+		}
+
+		if types.ObjectString(obj, qualifier) != types.ObjectString(eobj, qualifier) {
+			msg := fmt.Sprintf("declaration differs from export data: %s", types.ObjectString(eobj, qualifier))
+			changes = append(changes, Change{Pkg: p.importPath, ID: id, Change: Breaking, Msg: msg, Pos: pos(p.fset, obj.Pos()), Before: before})
+		}
+	}
+
+	for id, eobj := range expObjs {
+		if _, ok := srcObjs[id]; !ok {
+			msg := fmt.Sprintf("declaration only in export data: %s", types.ObjectString(eobj, qualifier))
+			changes = append(changes, Change{Pkg: p.importPath, ID: id, Change: NonBreaking, Msg: msg, Pos: pos(fset, eobj.Pos())})
+		}
+	}
+	return changes
+}
+
+// exportedObjects returns a package's exported package level objects, and
+// exported methods of its named types, keyed by the same identifiers as
+// pkgDecls, such as Type.Method.
+func exportedObjects(pkg *types.Package) map[string]types.Object {
+	objs := make(map[string]types.Object)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		objs[name] = obj
+
+		if _, ok := obj.(*types.TypeName); !ok {
+			continue
+		}
+		if named, ok := obj.Type().(*types.Named); ok {
+			for i := 0; i < named.NumMethods(); i++ {
+				if m := named.Method(i); m.Exported() {
+					objs[name+"."+m.Name()] = m
+				}
+			}
+		}
+	}
+	return objs
+}
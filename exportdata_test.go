@@ -0,0 +1,53 @@
+package apicompat
+
+import (
+	"os"
+	"testing"
+)
+
+// TestCheckAgainstExportData tests a file included by a build tag only known to
+// the compiler is detected as a difference between the source and export data.
+func TestCheckAgainstExportData(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/lib",
+		map[string]string{
+			"go.mod":   "module example.com/lib\n",
+			"lib.go":   "package lib\n\ntype T struct{}\n\nfunc (T) M() {}\n\nfunc F(a int) {}\n",
+			"extra.go": "//go:build extra\n\npackage lib\n\nfunc Extra() {}\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/lib")()
+
+	oldFlags := os.Getenv("GOFLAGS")
+	defer os.Setenv("GOFLAGS", oldFlags)
+
+	tests := []struct {
+		flags string
+		exp   []string // IDs of changes
+	}{
+		{"", nil},
+		{"-tags=extra", []string{"Extra"}},
+	}
+	for _, test := range tests {
+		if err := os.Setenv("GOFLAGS", test.flags); err != nil {
+			t.Fatal(err)
+		}
+
+		git, err := NewGit(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes, err := New(SetVCS(git)).CheckAgainstExportData(".", false, "HEAD")
+		if err != nil {
+			t.Fatalf("flags: %q unexpected error: %v", test.flags, err)
+		}
+		if len(changes) != len(test.exp) {
+			t.Fatalf("flags: %q exp %d changes got %d: %v", test.flags, len(test.exp), len(changes), changes)
+		}
+		for i, id := range test.exp {
+			if changes[i].ID != id || changes[i].Change != NonBreaking {
+				t.Errorf("flags: %q unexpected change: %#v", test.flags, changes[i])
+			}
+		}
+	}
+}
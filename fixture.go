@@ -0,0 +1,34 @@
+package apicompat
+
+// The revisions of a fixture's files, see RunFixture.
+const (
+	fixtureBefore = "before"
+	fixtureAfter  = "after"
+)
+
+// RunFixture checks a single package's before and after files, each keyed by
+// file name such as lib.go, with the given options, and returns the changes.
+// It doesn't require a VCS or GOPATH, so can be used to validate the Checker's
+// classifications, or a custom classifier, against a corpus of packages. The
+// files must declare a package at both revisions.
+func RunFixture(before, after map[string][]byte, options ...func(*Checker)) ([]Change, error) {
+	var vcs StrVCS
+	for name, src := range before {
+		vcs.SetFile(fixtureBefore, name, src)
+	}
+	for name, src := range after {
+		vcs.SetFile(fixtureAfter, name, src)
+	}
+
+	c := New(append([]func(*Checker){SetVCS(vcs)}, options...)...)
+	c.path, c.recurse = cwd, false
+
+	var err error
+	if c.b, err = c.parse(fixtureBefore); err != nil {
+		return nil, err
+	}
+	if c.a, err = c.parse(fixtureAfter); err != nil {
+		return nil, err
+	}
+	return c.compare()
+}
@@ -0,0 +1,143 @@
+package apicompat
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// corpusOptions are the options of fixtures in testdata/corpus, keyed by the
+// fixture's directory name, fixtures without options use the defaults.
+var corpusOptions = map[string][]func(*Checker){
+	"zerovalue":    {SetTrackZeroValue(true)},
+	"wire":         {SetTrackWireFormat(true)},
+	"layout-386":   {SetSizes(types.SizesFor("gc", "386"))},
+	"layout-amd64": {SetSizes(types.SizesFor("gc", "amd64"))},
+}
+
+// TestCorpus tests the changes of each fixture in testdata/corpus, a directory
+// containing before.go and after.go, against the expected changes in its
+// exp.txt, with the fixture's corpusOptions. Add a directory to extend the
+// corpus.
+func TestCorpus(t *testing.T) {
+	dirs, err := filepath.Glob("testdata/corpus/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatal("no fixtures in testdata/corpus")
+	}
+	for _, dir := range dirs {
+		read := func(name string) []byte {
+			contents, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("cannot load fixture: %v", err)
+			}
+			return contents
+		}
+		before := map[string][]byte{"corpus.go": read("before.go")}
+		after := map[string][]byte{"corpus.go": read("after.go")}
+
+		changes, err := RunFixture(before, after, corpusOptions[filepath.Base(dir)]...)
+		if err != nil {
+			t.Errorf("fixture %s: unexpected error: %v", dir, err)
+			continue
+		}
+		var buf bytes.Buffer
+		for _, change := range changes {
+			fmt.Fprintf(&buf, "%s: %s: %s\n", change.ID, change.Change, change.Msg)
+		}
+
+		// Overwrite the expected changes with go test -run TestCorpus -args update
+		if len(os.Args) > 1 && os.Args[1] == "update" {
+			if err := ioutil.WriteFile(filepath.Join(dir, "exp.txt"), buf.Bytes(), os.FileMode(0644)); err != nil {
+				t.Fatal("could not write exp data:", err)
+			}
+		}
+		if exp := read("exp.txt"); !bytes.Equal(exp, buf.Bytes()) {
+			t.Errorf("fixture %s: exp changes:\n%s\ngot:\n%s", dir, exp, buf.Bytes())
+		}
+	}
+}
+
+// TestRunFixtureOptions tests options, such as a classifier, are applied to
+// fixtures.
+func TestRunFixtureOptions(t *testing.T) {
+	before := map[string][]byte{"lib.go": []byte("package lib\ntype T struct{ A int }\n")}
+	after := map[string][]byte{"lib.go": []byte("package lib\ntype T struct{ A, B int }\n")}
+
+	strict := SetClassifier(func(before, after ast.Decl, change DeclChange) DeclChange {
+		if change.Msg == "members added" {
+			change.Change = Breaking
+		}
+		return change
+	})
+	changes, err := RunFixture(before, after, strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Change != Breaking {
+		t.Errorf("exp members added classified as breaking, got: %v", changes)
+	}
+}
+
+// TestStableID tests a declaration's StableID is the same regardless of the
+// source's layout, and is qualified by package.
+func TestStableID(t *testing.T) {
+	before := map[string][]byte{"lib.go": []byte("package lib\n\ntype Server struct{}\n\nfunc (Server) Serve(int) {}\n\nfunc Removed() {}\n")}
+	layouts := []map[string][]byte{
+		{"lib.go": []byte("package lib\n\ntype Server struct{}\n\nfunc (Server) Serve(uint) {}\n")},
+		{"lib.go": []byte("package lib\n\n// Server serves.\ntype Server struct {\n}\n\n\n\nfunc (s Server) Serve(\n\tport uint,\n) {\n}\n")},
+		{
+			"lib.go":    []byte("package lib\n\n// Added to move Server\nconst Other = 1\n"),
+			"server.go": []byte("package lib\n\ntype Server struct{}\n\nfunc (Server) Serve(uint) {}\n"),
+		},
+	}
+
+	var exp []string
+	for i, after := range layouts {
+		changes, err := RunFixture(before, after)
+		if err != nil {
+			t.Fatalf("layout %d: unexpected error: %v", i, err)
+		}
+		var ids []string
+		for _, change := range changes {
+			if change.ID == "Other" {
+				continue
+			}
+			if id := change.StableID(); id != change.Pkg+"."+change.ID || change.Pkg == "" {
+				t.Errorf("layout %d: unexpected StableID %q of change %#v", i, id, change)
+			}
+			ids = append(ids, change.StableID())
+		}
+		if i == 0 {
+			exp = ids
+			continue
+		}
+		if !reflect.DeepEqual(ids, exp) {
+			t.Errorf("layout %d: exp StableIDs %q got %q", i, exp, ids)
+		}
+	}
+	if len(exp) != 2 {
+		t.Errorf("exp 2 changes got %q", exp)
+	}
+
+	for _, test := range []struct {
+		change Change
+		exp    string
+	}{
+		{Change{Pkg: "example.com/lib", ID: "Server.Serve"}, "example.com/lib.Server.Serve"},
+		{Change{Pkg: "example.com/lib"}, "example.com/lib"},
+		{Change{ID: "Server"}, "Server"},
+	} {
+		if got := test.change.StableID(); got != test.exp {
+			t.Errorf("change %#v exp StableID %q got %q", test.change, test.exp, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package apicompat
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// directive is a go:generate directive within a package's file.
+type directive struct {
+	pos  string // position of the directive, such as rev:file.go:10
+	args []string
+}
+
+// generateDirectives returns the go:generate directives in a file's contents.
+// Lines are split from contents directly, so lines of any length, such as of
+// generated tables, don't stop the search.
+func generateDirectives(filename string, contents []byte) []directive {
+	var directives []directive
+	for i, line := range bytes.Split(contents, []byte("\n")) {
+		text := string(bytes.TrimSuffix(line, []byte("\r")))
+		if !strings.HasPrefix(text, "//go:generate ") {
+			continue
+		}
+		directives = append(directives, directive{
+			pos:  fmt.Sprintf("%s:%d", filename, i+1),
+			args: strings.Fields(strings.TrimPrefix(text, "//go:generate ")),
+		})
+	}
+	return directives
+}
+
+// references returns true if any of the directive's arguments reference ref,
+// either alone or within a comma separated list, optionally following a flag
+// such as -type=ref,Other.
+func (d directive) references(ref string) bool {
+	for _, arg := range d.args {
+		if i := strings.IndexByte(arg, '='); i >= 0 {
+			arg = arg[i+1:]
+		}
+		for _, r := range strings.Split(arg, ",") {
+			if r == ref {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// referencesDecl returns true if the directive, in the package pkg,
+// references the declaration id of the package with import path rpkg, see
+// generateChanges.
+func (d directive) referencesDecl(pkg, rpkg, id string) bool {
+	if rpkg == pkg {
+		return d.references(id)
+	}
+	return d.references(rpkg+"."+id) || d.references(rpkg) && d.references(id)
+}
+
+// generateChanges returns a change for each go:generate directive in the
+// after packages that references a removed declaration. removed maps a
+// package's import path to the IDs of its removed declarations. Declarations
+// of other packages are referenced by import path, either qualified, such as
+// example.com/lib/dep.Iface, or as separate arguments, such as mockgen's
+// example.com/lib/dep Iface, as package names may be ambiguous.
+func (c Checker) generateChanges(removed map[string][]string) []Change {
+	var changes []Change
+	for pkgName, apkg := range c.a {
+		for _, d := range apkg.generate {
+			for rpkg, ids := range removed {
+				for _, id := range ids {
+					if !d.referencesDecl(pkgName, rpkg, id) {
+						continue
+					}
+					changes = append(changes, Change{
+						Pkg:        pkgName,
+						ID:         id,
+						Change:     NonBreaking,
+						Msg:        fmt.Sprintf("go:generate directive references removed declaration: %s", strings.Join(d.args, " ")),
+						Pos:        d.pos,
+						Confidence: Low,
+						Category:   categoryGenerateReference,
+					})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// generatePackage writes the files of the package in dir at revision rev to a
+// temporary directory, and runs go generate on the package's non-test files
+// there, returning the directory, which the caller must remove. The checkout is
+// scoped to the package, so generators can't reference files outside of it.
+func (c Checker) generatePackage(rev, dir string) (string, error) {
+	gen, err := ioutil.TempDir("", "apicompat")
+	if err != nil {
+		return "", err
+	}
+	names, err := c.copyDir(rev, dir, gen, false)
+	if err != nil {
+		os.RemoveAll(gen)
+		return "", err
+	}
+
+	var goFiles []string
+	for _, name := range names {
+		if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			goFiles = append(goFiles, name)
+		}
+	}
+	if len(goFiles) == 0 {
+		// nothing to generate, go/build will report the missing sources
+		return gen, nil
+	}
+
+	c.logf("Generating package: %s revision: %s\n", dir, rev)
+	cmd := exec.Command("go", append([]string{"generate"}, goFiles...)...)
+	cmd.Dir = gen
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(gen)
+		return "", fmt.Errorf("go generate failed for %q at revision %q: %v output: %s", dir, rev, err, out)
+	}
+	return gen, nil
+}
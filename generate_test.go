@@ -0,0 +1,45 @@
+package apicompat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGenerateDirectives tests directives are found after lines longer than
+// bufio.Scanner's default limit, such as generated tables.
+func TestGenerateDirectives(t *testing.T) {
+	contents := "package lib\n\n//go:generate stringer -type=A\nvar table = \"" + strings.Repeat("x", 1<<20) + "\"\r\n//go:generate stringer -type=B\n"
+	exp := []directive{
+		{pos: "rev2:lib.go:3", args: []string{"stringer", "-type=A"}},
+		{pos: "rev2:lib.go:5", args: []string{"stringer", "-type=B"}},
+	}
+	if got := generateDirectives("rev2:lib.go", []byte(contents)); !reflect.DeepEqual(got, exp) {
+		t.Errorf("exp %v got %v", exp, got)
+	}
+}
+
+// TestDirectiveReferencesDecl tests declarations of other packages are only
+// referenced by their import path, not their ambiguous package name.
+func TestDirectiveReferencesDecl(t *testing.T) {
+	tests := []struct {
+		args      string
+		pkg, rpkg string
+		exp       bool
+	}{
+		{"stringer -type=Kind,Other", "example.com/lib", "example.com/lib", true},
+		{"stringer -type=Other", "example.com/lib", "example.com/lib", false},
+		{"mockgen example.com/a/dep Kind", "example.com/lib", "example.com/a/dep", true},
+		{"gen -iface=example.com/a/dep.Kind", "example.com/lib", "example.com/a/dep", true},
+		// same package name, different import path
+		{"mockgen example.com/b/dep Kind", "example.com/lib", "example.com/a/dep", false},
+		{"gen -iface=dep.Kind", "example.com/lib", "example.com/a/dep", false},
+		{"stringer -type=Kind", "example.com/lib", "example.com/a/dep", false},
+	}
+	for _, test := range tests {
+		d := directive{args: strings.Fields(test.args)}
+		if got := d.referencesDecl(test.pkg, test.rpkg, "Kind"); got != test.exp {
+			t.Errorf("args: %q pkg: %q rpkg: %q exp %v got %v", test.args, test.pkg, test.rpkg, test.exp, got)
+		}
+	}
+}
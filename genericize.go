@@ -0,0 +1,319 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// genericizedChange returns a change if a non-generic function was made
+// generic, such as Sort(s []int) becoming Sort[T cmp.Ordered](s []T), and each
+// parameter and result at the before revision can be unified with its generic
+// counterpart. If the inferred type arguments satisfy their constraints,
+// existing calls, such as Sort([]int{}), still compile, so it's non-breaking,
+// otherwise it's breaking. An interface parameter becoming a type parameter,
+// such as Do(h Handler) becoming Do[H Handler](h H), is breaking as calls
+// passing nil no longer compile. An empty interface parameter becoming an
+// unconstrained type parameter, such as Print(v any) becoming
+// Print[T any](v T), is non-breaking, as only calls passing nil, which are
+// uncommon, break, see isAnyToTypeParam. Inference is best-effort, so changes are
+// reported with Medium confidence.
+func (c DeclChecker) genericizedChange(before, after *ast.FuncType) (DeclChange, bool) {
+	if before.TypeParams.NumFields() > 0 || after.TypeParams.NumFields() == 0 {
+		return none(), false
+	}
+	bfields := append(stripNames(before.Params.List), resultFields(before)...)
+	afields := append(stripNames(after.Params.List), resultFields(after)...)
+	if len(before.Params.List) != len(after.Params.List) || len(bfields) != len(afields) {
+		return none(), false
+	}
+
+	var tparams []*types.TypeParam
+	for _, field := range after.TypeParams.List {
+		for _, name := range field.Names {
+			tparam, ok := c.ainfo.Defs[name].Type().(*types.TypeParam)
+			if !ok {
+				return none(), false
+			}
+			tparams = append(tparams, tparam)
+		}
+	}
+
+	u := unifier{bound: make(map[*types.TypeParam]types.Type), q: c.qualifier}
+	var (
+		ifaceParams  []string                          // interface parameters which became type parameters
+		anyParams    []string                          // any parameters which became unconstrained type parameters
+		anyTParams   = make(map[*types.TypeParam]bool) // type parameters of anyParams
+		sharedParams []string                          // any parameters which became type parameters used elsewhere
+	)
+	for i := range bfields {
+		btype, atype := bfields[i].Type, afields[i].Type
+		if i < len(before.Params.List) && isInterfaceToTypeParam(c.binfo.TypeOf(btype), c.ainfo.TypeOf(atype)) {
+			param := fmt.Sprintf("%s → %s", types.ExprString(btype), types.ExprString(atype))
+			switch tparam, ok := isAnyToTypeParam(c.binfo.TypeOf(btype), c.ainfo.TypeOf(atype)); {
+			case ok && c.typeParamUses(after, tparam) == 1:
+				anyParams = append(anyParams, param)
+				anyTParams[tparam] = true
+			case ok:
+				sharedParams = append(sharedParams, param)
+			default:
+				ifaceParams = append(ifaceParams, param)
+			}
+		}
+		bellipsis, bok := btype.(*ast.Ellipsis)
+		aellipsis, aok := atype.(*ast.Ellipsis)
+		if bok != aok {
+			return none(), false
+		}
+		if bok {
+			btype, atype = bellipsis.Elt, aellipsis.Elt
+		}
+		if !u.unify(c.ainfo.TypeOf(atype), c.binfo.TypeOf(btype)) {
+			return none(), false
+		}
+	}
+
+	var inferred []string
+	for _, tparam := range tparams {
+		if _, ok := u.bound[tparam]; !ok && !u.inferCore(tparam) {
+			// cannot be inferred, see inferenceChange
+			return none(), false
+		}
+		if anyTParams[tparam] {
+			// inferred as each call's argument type, which satisfies any
+			continue
+		}
+		bound := types.TypeString(u.bound[tparam], types.RelativeTo(c.bpkg))
+		inferred = append(inferred, fmt.Sprintf("%s as %s", tparam.Obj().Name(), bound))
+		if !u.satisfies(u.bound[tparam], tparam.Constraint()) {
+			msg := fmt.Sprintf("parameters made generic, existing calls no longer compile as %s doesn't satisfy %s's constraint %s",
+				bound, tparam.Obj().Name(), types.TypeString(tparam.Constraint(), types.RelativeTo(c.apkg)))
+			return breaking(msg, after.Pos()).withConfidence(Medium).withCategory(categoryTypeParametersChanged), true
+		}
+	}
+	if len(sharedParams) > 0 {
+		msg := fmt.Sprintf("any parameters made generic (%s), but their type parameters are also used by other parameters or results, so calls passing arguments of differing types, or using results as interfaces, no longer compile",
+			strings.Join(sharedParams, ", "))
+		return breaking(msg, after.Pos()).withConfidence(Medium).withCategory(categoryTypeParametersChanged), true
+	}
+	if len(ifaceParams) > 0 {
+		// untyped nil has no type to infer the type parameter from
+		msg := fmt.Sprintf("interface parameters made generic (%s), calls passing implementations still compile, but calls passing nil no longer compile",
+			strings.Join(ifaceParams, ", "))
+		return breaking(msg, after.Pos()).withConfidence(Medium).withCategory(categoryTypeParametersChanged), true
+	}
+	if len(anyParams) > 0 {
+		msg := fmt.Sprintf("any parameters made generic (%s), existing calls, other than those passing nil, still compile, inferring each argument's type",
+			strings.Join(anyParams, ", "))
+		if len(inferred) > 0 {
+			msg += ", and " + strings.Join(inferred, ", ")
+		}
+		return nonBreaking(msg, after.Pos()).withConfidence(Medium).withCategory(categoryTypeParametersChanged), true
+	}
+	msg := "parameters made generic, existing calls still compile, inferring " + strings.Join(inferred, ", ")
+	return nonBreaking(msg, after.Pos()).withConfidence(Medium).withCategory(categoryTypeParametersChanged), true
+}
+
+// isAnyToTypeParam returns the type parameter if before is the empty
+// interface, such as any or interface{}, and after is an unconstrained type
+// parameter, such as v T given Print[T any](v T). If the type parameter is
+// used only once, each call infers it from its own argument, so any argument,
+// other than untyped nil, still compiles. If it's used more than once, such as
+// in Equal[T any](a, b T) or a result, its uses must have the same type.
+func isAnyToTypeParam(before, after types.Type) (*types.TypeParam, bool) {
+	biface, ok := before.Underlying().(*types.Interface)
+	if !ok || biface.NumMethods() > 0 || biface.NumEmbeddeds() > 0 {
+		return nil, false
+	}
+	tparam, ok := after.(*types.TypeParam)
+	if !ok {
+		return nil, false
+	}
+	aiface, ok := tparam.Constraint().Underlying().(*types.Interface)
+	if !ok || !aiface.IsMethodSet() || aiface.IsComparable() || aiface.NumMethods() > 0 {
+		return nil, false
+	}
+	return tparam, true
+}
+
+// typeParamUses returns the number of times tparam is used by fn, in its
+// parameters, results and other type parameters' constraints.
+func (c DeclChecker) typeParamUses(fn *ast.FuncType, tparam *types.TypeParam) int {
+	var uses int
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && c.ainfo.Uses[ident] == tparam.Obj() {
+			uses++
+		}
+		return true
+	})
+	return uses
+}
+
+// isInterfaceToTypeParam returns true if before is an interface, other than a
+// constraint, and after is a type parameter, such as Handler becoming H given
+// [H Handler]. Calls passing implementations infer their type, but calls
+// passing nil can't be inferred.
+func isInterfaceToTypeParam(before, after types.Type) bool {
+	if before == nil || after == nil {
+		return false
+	}
+	if _, ok := after.(*types.TypeParam); !ok {
+		return false
+	}
+	iface, ok := before.Underlying().(*types.Interface)
+	return ok && iface.IsMethodSet()
+}
+
+// resultFields returns a function's results without their names.
+func resultFields(fn *ast.FuncType) []*ast.Field {
+	if fn.Results == nil {
+		return nil
+	}
+	return stripNames(fn.Results.List)
+}
+
+// unifier infers type parameters' types by unifying a generic type from the
+// after revision with a non-generic type from the before revision. As the
+// types are from different type checkers, types without type parameters are
+// compared by their qualified names.
+type unifier struct {
+	bound map[*types.TypeParam]types.Type // type parameter to inferred type
+	q     types.Qualifier                 // qualifies types compared as strings, see DeclChecker
+}
+
+// unify returns true if generic can be unified with typ, binding generic's type
+// parameters to the corresponding parts of typ.
+func (u unifier) unify(generic, typ types.Type) bool {
+	if generic == nil || typ == nil {
+		return false
+	}
+	generic, typ = types.Unalias(generic), types.Unalias(typ)
+	if tparam, ok := generic.(*types.TypeParam); ok {
+		if bound, ok := u.bound[tparam]; ok {
+			return types.TypeString(bound, u.q) == types.TypeString(typ, u.q)
+		}
+		u.bound[tparam] = typ
+		return true
+	}
+	if _, ok := generic.(*types.Named); !ok {
+		// inexact unification, a named type's underlying type may match a
+		// type literal, such as type Ints []int with []T
+		typ = typ.Underlying()
+	}
+
+	switch g := generic.(type) {
+	case *types.Slice:
+		t, ok := typ.(*types.Slice)
+		return ok && u.unify(g.Elem(), t.Elem())
+	case *types.Pointer:
+		t, ok := typ.(*types.Pointer)
+		return ok && u.unify(g.Elem(), t.Elem())
+	case *types.Array:
+		t, ok := typ.(*types.Array)
+		return ok && g.Len() == t.Len() && u.unify(g.Elem(), t.Elem())
+	case *types.Map:
+		t, ok := typ.(*types.Map)
+		return ok && u.unify(g.Key(), t.Key()) && u.unify(g.Elem(), t.Elem())
+	case *types.Chan:
+		t, ok := typ.(*types.Chan)
+		return ok && g.Dir() == t.Dir() && u.unify(g.Elem(), t.Elem())
+	case *types.Named:
+		t, ok := typ.(*types.Named)
+		if !ok || g.TypeArgs().Len() != t.TypeArgs().Len() {
+			return false
+		}
+		if types.TypeString(g.Origin(), u.q) != types.TypeString(t.Origin(), u.q) {
+			return false
+		}
+		for i := 0; i < g.TypeArgs().Len(); i++ {
+			if !u.unify(g.TypeArgs().At(i), t.TypeArgs().At(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	// no type parameters to bind, such as a basic type
+	return types.TypeString(generic, u.q) == types.TypeString(typ, u.q)
+}
+
+// inferCore binds tparam by unifying the core type of its constraint, such as
+// ~[]E, with the type bound to another type parameter whose constraint
+// references tparam, such as E given [S ~[]E, E any](s S). Returns false if
+// tparam couldn't be bound.
+func (u unifier) inferCore(tparam *types.TypeParam) bool {
+	for other, bound := range u.bound {
+		iface, ok := other.Constraint().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		for i := 0; i < iface.NumEmbeddeds(); i++ {
+			union, ok := iface.EmbeddedType(i).(*types.Union)
+			if !ok || union.Len() != 1 {
+				continue
+			}
+			term := union.Term(0)
+			target := bound
+			if term.Tilde() {
+				target = bound.Underlying()
+			}
+			if u.unify(term.Type(), target) {
+				if _, ok := u.bound[tparam]; ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// satisfies returns true if typ satisfies the constraint, by implementing its
+// methods, being comparable if required, and matching a term of each of its
+// type set elements, such as ~int | ~float64.
+func (u unifier) satisfies(typ, constraint types.Type) bool {
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	if !implementsByName(typ, iface, u.q) {
+		return false
+	}
+	if iface.IsComparable() && !types.Comparable(typ) {
+		return false
+	}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		embedded := iface.EmbeddedType(i)
+		if union, ok := embedded.(*types.Union); ok {
+			if !u.matchesTerm(typ, union) {
+				return false
+			}
+			continue
+		}
+		if _, ok := embedded.Underlying().(*types.Interface); ok {
+			if !u.satisfies(typ, embedded) {
+				return false
+			}
+			continue
+		}
+		// a single type, such as interface{ int }
+		if types.TypeString(embedded, u.q) != types.TypeString(typ, u.q) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTerm returns true if typ matches one of union's terms. Terms with a
+// tilde, such as ~int, match typ's underlying type, others must be identical.
+func (u unifier) matchesTerm(typ types.Type, union *types.Union) bool {
+	for i := 0; i < union.Len(); i++ {
+		term := union.Term(i)
+		if term.Tilde() && u.unify(term.Type(), typ.Underlying()) {
+			return true
+		}
+		if !term.Tilde() && types.TypeString(term.Type(), u.q) == types.TypeString(typ, u.q) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package apicompat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+// ChangesHash returns a hash of changes, such as for a CI system to skip work
+// when the changes are the same as a previous run's. Only the stable fields,
+// Pkg, ID, Change and Msg, are hashed, so the hash doesn't depend on the
+// changes' order, positions or declarations.
+func ChangesHash(changes []Change) string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		// quoting delimits each field, so fields containing spaces are unambiguous
+		lines[i] = strconv.Quote(c.Pkg) + " " + strconv.Quote(c.ID) + " " + strconv.Quote(c.Change) + " " + strconv.Quote(c.Msg) + "\n"
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,50 @@
+package apicompat
+
+import "testing"
+
+// TestChangesHash tests the hash is independent of the changes' order and
+// unstable fields, but depends on each stable field.
+func TestChangesHash(t *testing.T) {
+	changes := []Change{
+		{Pkg: "a", ID: "A", Change: Breaking, Msg: "declaration removed", Pos: "rev1:a.go:1"},
+		{Pkg: "a", ID: "B", Change: NonBreaking, Msg: "declaration added", Pos: "rev2:a.go:2"},
+		{Pkg: "b", ID: "C", Change: Breaking, Msg: "changed type", Pos: "rev2:b.go:3"},
+	}
+	hash := ChangesHash(changes)
+
+	reordered := []Change{changes[2], changes[0], changes[1]}
+	if got := ChangesHash(reordered); got != hash {
+		t.Errorf("exp same hash for reordered changes %v got %v", hash, got)
+	}
+
+	moved := append([]Change(nil), changes...)
+	moved[0].Pos = "rev1:a.go:10"
+	moved[0].Usage = 3
+	if got := ChangesHash(moved); got != hash {
+		t.Errorf("exp same hash for changed position and usage %v got %v", hash, got)
+	}
+
+	for _, modify := range []func(*Change){
+		func(c *Change) { c.Pkg = "c" },
+		func(c *Change) { c.ID = "D" },
+		func(c *Change) { c.Change = NonBreaking },
+		func(c *Change) { c.Msg = "members added" },
+	} {
+		modified := append([]Change(nil), changes...)
+		modify(&modified[0])
+		if got := ChangesHash(modified); got == hash {
+			t.Errorf("exp different hash for modified change %v", modified[0])
+		}
+	}
+
+	if got := ChangesHash(changes[:2]); got == hash {
+		t.Errorf("exp different hash for fewer changes")
+	}
+	if ChangesHash(nil) != ChangesHash([]Change{}) {
+		t.Errorf("exp same hash for nil and empty changes")
+	}
+	// fields are delimited, so moving text between fields changes the hash
+	if ChangesHash([]Change{{Pkg: "a b", ID: "c"}}) == ChangesHash([]Change{{Pkg: "a", ID: "b c"}}) {
+		t.Errorf("exp different hash for text moved between fields")
+	}
+}
@@ -0,0 +1,101 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// inferenceChange returns a breaking change if a generic function's type
+// parameter, which could previously be inferred from the function's
+// parameters, or didn't exist, can no longer be inferred, requiring callers to
+// instantiate the function explicitly. Type parameters are compared by
+// position, as they may be renamed.
+func inferenceChange(before, after *ast.FuncType) (DeclChange, bool) {
+	binferable := inferable(before)
+	var names []string
+	for i, ok := range inferable(after) {
+		if ok || (i < len(binferable) && !binferable[i]) {
+			continue
+		}
+		names = append(names, fieldNames(after.TypeParams)[i])
+	}
+	switch len(names) {
+	case 0:
+		return none(), false
+	case 1:
+		msg := fmt.Sprintf("type parameter %s can no longer be inferred, callers must instantiate explicitly", names[0])
+		return breaking(msg, after.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), true
+	}
+	msg := fmt.Sprintf("type parameters %s can no longer be inferred, callers must instantiate explicitly", strings.Join(names, ", "))
+	return breaking(msg, after.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), true
+}
+
+// arityChange returns a breaking change if a generic function's number of type
+// parameters changed, such as Map[T any] becoming Map[T, U any]. Callers
+// instantiating the function explicitly break, and inferred type arguments
+// may differ even if the parameters are otherwise compatible. Functions
+// becoming generic, or no longer generic, aren't arity changes.
+func arityChange(before, after *ast.FuncType) (DeclChange, bool) {
+	bparams, aparams := fieldNames(before.TypeParams), fieldNames(after.TypeParams)
+	if len(bparams) == 0 || len(aparams) == 0 || len(bparams) == len(aparams) {
+		return none(), false
+	}
+	msg := fmt.Sprintf("type parameter count changed from %d to %d, [%s] → [%s], inference behaviour altered",
+		len(bparams), len(aparams), strings.Join(bparams, ", "), strings.Join(aparams, ", "))
+	return breaking(msg, after.TypeParams.Pos()).withCategory(categoryTypeParametersChanged), true
+}
+
+// inferable returns whether each of a function's type parameters can be
+// inferred from its parameters, either directly or via the constraint of
+// another inferable type parameter, such as E given [S ~[]E, E any](s S).
+func inferable(fn *ast.FuncType) []bool {
+	names := fieldNames(fn.TypeParams)
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	inferred := make([]bool, len(names))
+	var mark func(ast.Node) bool
+	mark = func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			if i, ok := index[ident.Name]; ok && !inferred[i] {
+				inferred[i] = true
+				// a type parameter's constraint may infer others
+				ast.Inspect(constraint(fn.TypeParams, i), mark)
+			}
+		}
+		return true
+	}
+	if fn.Params != nil {
+		for _, field := range fn.Params.List {
+			ast.Inspect(field.Type, mark)
+		}
+	}
+	return inferred
+}
+
+// constraint returns the constraint of the i-th type parameter in tparams.
+func constraint(tparams *ast.FieldList, i int) ast.Expr {
+	for _, field := range tparams.List {
+		if i < len(field.Names) {
+			return field.Type
+		}
+		i -= len(field.Names)
+	}
+	return nil
+}
+
+// ungenericizedChange returns a breaking change if a generic function's type
+// parameters were removed, such as Sum[T Number](s []T) becoming
+// Sum(s []int). Callers instantiating the function explicitly break, even if
+// inferred calls still compile.
+func ungenericizedChange(before, after *ast.FuncType) (DeclChange, bool) {
+	bparams := fieldNames(before.TypeParams)
+	if len(bparams) == 0 || after.TypeParams.NumFields() > 0 {
+		return none(), false
+	}
+	msg := fmt.Sprintf("type parameters [%s] removed, function is no longer generic", strings.Join(bparams, ", "))
+	return breaking(msg, after.Pos()).withCategory(categoryTypeParametersChanged), true
+}
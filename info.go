@@ -0,0 +1,45 @@
+package apicompat
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// ComparePackage is a parsed and type checked package, such as one loaded by
+// golang.org/x/tools/go/packages, to be compared with CompareInfo.
+type ComparePackage struct {
+	ImportPath string
+	Fset       *token.FileSet
+	Decls      map[string]ast.Decl // declarations as returned by PackageDecls
+	Info       *types.Info         // requires at least Types, Defs and Uses
+	Types      *types.Package      // optional, checks requiring it are skipped if nil
+}
+
+// PackageDecls returns the declarations in a package's files, keyed by their
+// identifier, such as Type.Method, for use in a ComparePackage. Files are
+// modified, function bodies are removed and field lists are expanded, so they
+// should be type checked first.
+func PackageDecls(files []*ast.File) map[string]ast.Decl {
+	return pkgDecls(nil, files, nil)
+}
+
+// CompareInfo compares two packages which have already been parsed and type
+// checked, without using a VCS, and returns the changes sorted by ID.
+func CompareInfo(before, after ComparePackage) ([]Change, error) {
+	c := New()
+	c.b = map[string]pkg{before.ImportPath: comparePkg(before)}
+	c.a = map[string]pkg{after.ImportPath: comparePkg(after)}
+	return c.compare()
+}
+
+// comparePkg returns the internal form of a ComparePackage.
+func comparePkg(p ComparePackage) pkg {
+	return pkg{
+		importPath: p.ImportPath,
+		fset:       p.Fset,
+		decls:      p.Decls,
+		info:       p.Info,
+		types:      p.Types,
+	}
+}
@@ -0,0 +1,66 @@
+package apicompat
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// loadPackage parses and type checks src, as a caller of CompareInfo would.
+func loadPackage(t *testing.T, src string) ComparePackage {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "lib.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	tpkg, err := (&types.Config{}).Check("example.com/lib", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ComparePackage{
+		ImportPath: "example.com/lib",
+		Fset:       fset,
+		Decls:      PackageDecls([]*ast.File{file}),
+		Info:       info,
+		Types:      tpkg,
+	}
+}
+
+// TestCompareInfo tests comparing packages which are already type checked.
+func TestCompareInfo(t *testing.T) {
+	before := loadPackage(t, "package lib\n\nfunc F(a int) {}\n\nfunc Removed() {}\n")
+	after := loadPackage(t, "package lib\n\nfunc F(a uint) {}\n\nfunc Added(a string) {}\n")
+
+	changes, err := CompareInfo(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []struct {
+		id     string
+		change string
+		msg    string
+	}{
+		{"Added", NonBreaking, "declaration added"},
+		{"F", Breaking, "parameter types changed"},
+		{"Removed", Breaking, "declaration removed"},
+	}
+	if len(changes) != len(exp) {
+		t.Fatalf("exp %d changes got %d: %v", len(exp), len(changes), changes)
+	}
+	for i, e := range exp {
+		if changes[i].ID != e.id || changes[i].Change != e.change || changes[i].Msg != e.msg {
+			t.Errorf("exp %v got: %#v", e, changes[i])
+		}
+	}
+	if changes[1].Pos != "lib.go:3" {
+		t.Errorf("unexpected position: %q", changes[1].Pos)
+	}
+}
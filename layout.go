@@ -0,0 +1,46 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// layoutChanges returns a description of each difference in the memory layout
+// of two structs using sizes, such as their size, alignment and the offset and
+// size of each field by position. Returns nil if either type isn't a struct.
+func layoutChanges(sizes types.Sizes, before, after types.Type) []string {
+	bstruct, ok := before.(*types.Struct)
+	if !ok {
+		return nil
+	}
+	astruct, ok := after.(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	var msgs []string
+	if b, a := sizes.Sizeof(bstruct), sizes.Sizeof(astruct); b != a {
+		msgs = append(msgs, fmt.Sprintf("size %d → %d", b, a))
+	}
+	if b, a := sizes.Alignof(bstruct), sizes.Alignof(astruct); b != a {
+		msgs = append(msgs, fmt.Sprintf("alignment %d → %d", b, a))
+	}
+
+	boffsets, aoffsets := sizes.Offsetsof(structFields(bstruct)), sizes.Offsetsof(structFields(astruct))
+	for i := 0; i < len(boffsets) && i < len(aoffsets); i++ {
+		bsize, asize := sizes.Sizeof(bstruct.Field(i).Type()), sizes.Sizeof(astruct.Field(i).Type())
+		if boffsets[i] != aoffsets[i] || bsize != asize {
+			msgs = append(msgs, fmt.Sprintf("field %d offset %d size %d → offset %d size %d", i+1, boffsets[i], bsize, aoffsets[i], asize))
+		}
+	}
+	return msgs
+}
+
+// structFields returns the fields of a struct.
+func structFields(s *types.Struct) []*types.Var {
+	fields := make([]*types.Var, s.NumFields())
+	for i := range fields {
+		fields[i] = s.Field(i)
+	}
+	return fields
+}
@@ -0,0 +1,84 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// majorPattern matches a major version suffix of a module's import path.
+var majorPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// CheckMajorVersions checks the package at import path basePath at major
+// version oldMajor against newMajor, such as "" or v1 for example.com/mod, and
+// v2 for example.com/mod/v2, where the module keeps each major version in a
+// subdirectory. As the import paths differ, the packages are compared as though
+// they had the same path, including types referring to the new major version,
+// and the changes' Pkg is the import path at the new major version. Both are
+// checked at the VCS's default after revision.
+func (c *Checker) CheckMajorVersions(basePath, oldMajor, newMajor string) ([]Change, error) {
+	oldPath, err := majorVersionPath(basePath, oldMajor)
+	if err != nil {
+		return nil, err
+	}
+	newPath, err := majorVersionPath(basePath, newMajor)
+	if err != nil {
+		return nil, err
+	}
+	_, rev := c.vcs.DefaultRevision()
+
+	c.logf("import path: %q against: %q revision: %q\n", oldPath, newPath, rev)
+
+	// the subdirectory of the new major version would be a package of the old
+	// when recursing, so only the packages themselves are compared
+	c.recurse = false
+	c.path = oldPath
+	if c.b, err = c.parse(rev); err != nil {
+		return nil, err
+	}
+	c.path = newPath
+	after, err := c.parse(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	// correlate the new major version's packages with the old
+	c.a = make(map[string]pkg, len(after))
+	for importPath, p := range after {
+		c.a[replacePathPrefix(importPath, newPath, oldPath)] = p
+	}
+	c.qualifier = func(p *types.Package) string {
+		return replacePathPrefix(p.Path(), newPath, oldPath)
+	}
+	changes, err := c.compare()
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		changes[i].Pkg = replacePathPrefix(changes[i].Pkg, oldPath, newPath)
+	}
+	return changes, nil
+}
+
+// majorVersionPath returns the import path of the package at basePath at a
+// major version, such as example.com/mod/v2 for v2. Major versions 0 and 1 have
+// no suffix.
+func majorVersionPath(basePath, major string) (string, error) {
+	switch {
+	case major == "" || major == "v0" || major == "v1":
+		return basePath, nil
+	case majorPattern.MatchString(major):
+		return basePath + "/" + major, nil
+	}
+	return "", fmt.Errorf("invalid major version %q, expected a version such as v2", major)
+}
+
+// replacePathPrefix replaces the import path prefix from in path with to, if
+// path is from or a package within it.
+func replacePathPrefix(path, from, to string) string {
+	if path == from || strings.HasPrefix(path, from+"/") {
+		return to + path[len(from):]
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+package apicompat
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// markdownSeverities are the severity column of each type of change in a
+// Markdown report, see MarkdownReport.
+var markdownSeverities = map[string]string{
+	Breaking:    "⚠️ Breaking",
+	NonBreaking: "✅ Non-breaking",
+}
+
+// MarkdownReport returns a Markdown summary of changes, such as for a pull
+// request comment, of a count of breaking and non-breaking changes followed
+// by a table of the changes with columns of severity, symbol, message and
+// location. Breaking changes precede non-breaking changes, each sorted by
+// package and then ID, other changes are omitted. The location links to the
+// change's URL if it has one, see SetSourceURLTemplate.
+func MarkdownReport(changes []Change) string {
+	filtered := filterChanges(changes, func(c Change) bool {
+		return c.Change == Breaking || c.Change == NonBreaking
+	})
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].Change != filtered[j].Change {
+			return filtered[i].Change == Breaking
+		}
+		if filtered[i].Pkg != filtered[j].Pkg {
+			return filtered[i].Pkg < filtered[j].Pkg
+		}
+		return filtered[i].ID < filtered[j].ID
+	})
+
+	var nbreaking int
+	for _, c := range filtered {
+		if c.Change == Breaking {
+			nbreaking++
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "**%d breaking, %d non-breaking**\n", nbreaking, len(filtered)-nbreaking)
+	if len(filtered) == 0 {
+		return buf.String()
+	}
+	buf.WriteString("\n| Severity | Symbol | Change | Location |\n| --- | --- | --- | --- |\n")
+	for _, c := range filtered {
+		fmt.Fprintf(&buf, "| %s | %s | %s | %s |\n",
+			markdownSeverities[c.Change], markdownCode(c.StableID()), markdownEscape(c.Msg), markdownLocation(c))
+	}
+	return buf.String()
+}
+
+// markdownLocation returns a change's file and line, such as pkg/file.go:10,
+// linked to its URL if it has one, or an empty string if it has no file.
+func markdownLocation(c Change) string {
+	p := parsePos(c.Pos)
+	if p.file == "" {
+		return ""
+	}
+	loc := p.file
+	if p.line > 0 {
+		loc += ":" + strconv.Itoa(p.line)
+	}
+	if c.URL == "" {
+		return markdownCode(loc)
+	}
+	return fmt.Sprintf("[%s](%s)", markdownCode(loc), strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(c.URL))
+}
+
+// markdownEscape escapes characters with special meaning in Markdown, or
+// which would end a table's cell, such as | and newlines, so s is shown as is.
+func markdownEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '`', '*', '_', '[', ']', '<', '>', '#', '|', '~':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n', '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// markdownCode returns s as a code span, such as `pkg.ID`, escaping any | so
+// it doesn't end a table's cell. Code spans can't otherwise be escaped, so a
+// code span containing a backtick is delimited by double backticks.
+func markdownCode(s string) string {
+	s = strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
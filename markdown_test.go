@@ -0,0 +1,33 @@
+package apicompat
+
+import "testing"
+
+// TestMarkdownReport tests changes are summarised as a Markdown table, with
+// breaking changes first, and special characters escaped.
+func TestMarkdownReport(t *testing.T) {
+	changes := []Change{
+		{Pkg: "example.com/b", ID: "A", Change: Breaking, Msg: "declaration removed", Pos: "rev1:b.go:1"},
+		{Pkg: "example.com/a", ID: "Z", Change: NonBreaking, Msg: "declaration added", Pos: "rev2:pkg/a.go:3",
+			URL: "https://github.com/org/repo/blob/rev2/pkg/a.go#L3"},
+		{Pkg: "example.com/a", ID: "F", Change: Breaking, Msg: "return value 1 changed: *int → []int | x_y", Pos: "a.go:2"},
+		{Pkg: "example.com/a", ID: "T", Change: None, Msg: "unchanged", Pos: "a.go:4"},
+		{Pkg: "example.com/c", Change: Breaking, Msg: "package removed\nfor <reasons>"},
+	}
+
+	const exp = "**3 breaking, 1 non-breaking**\n" +
+		"\n" +
+		"| Severity | Symbol | Change | Location |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| ⚠️ Breaking | `example.com/a.F` | return value 1 changed: \\*int → \\[\\]int \\| x\\_y | `a.go:2` |\n" +
+		"| ⚠️ Breaking | `example.com/b.A` | declaration removed | `b.go:1` |\n" +
+		"| ⚠️ Breaking | `example.com/c` | package removed for \\<reasons\\> |  |\n" +
+		"| ✅ Non-breaking | `example.com/a.Z` | declaration added | [`pkg/a.go:3`](https://github.com/org/repo/blob/rev2/pkg/a.go#L3) |\n"
+	if got := MarkdownReport(changes); got != exp {
+		t.Errorf("unexpected report\nexp:\n%s\ngot:\n%s", exp, got)
+	}
+
+	const expEmpty = "**0 breaking, 0 non-breaking**\n"
+	if got := MarkdownReport(nil); got != expEmpty {
+		t.Errorf("unexpected empty report\nexp: %q\ngot: %q", expEmpty, got)
+	}
+}
@@ -0,0 +1,83 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// defaultNotableInterfaces are the default interfaces which alter the runtime
+// behaviour of a type once satisfied, such as how it's formatted or encoded.
+var defaultNotableInterfaces = map[string]*types.Interface{
+	"error":                   types.Universe.Lookup("error").Type().Underlying().(*types.Interface),
+	"fmt.Stringer":            newInterface("String", nil, types.Typ[types.String]),
+	"encoding/json.Marshaler": newInterface("MarshalJSON", nil, types.NewSlice(types.Typ[types.Byte]), types.Universe.Lookup("error").Type()),
+}
+
+// newInterface returns an interface with a single method with the given
+// parameters and results.
+func newInterface(method string, params []types.Type, results ...types.Type) *types.Interface {
+	tuple := func(typs []types.Type) *types.Tuple {
+		var vars []*types.Var
+		for _, typ := range typs {
+			vars = append(vars, types.NewVar(token.NoPos, nil, "", typ))
+		}
+		return types.NewTuple(vars...)
+	}
+	sig := types.NewSignatureType(nil, nil, nil, tuple(params), tuple(results), false)
+	return types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, nil, method, sig)}, nil).Complete()
+}
+
+// notableChanges returns a change for each exported type in both before and
+// after which newly satisfies one of the Checker's notable interfaces, either
+// by value or by pointer.
+func (c Checker) notableChanges() []Change {
+	var names []string
+	for name := range c.notable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []Change
+	for pkgName, apkg := range c.a {
+		bpkg, ok := c.b[pkgName]
+		if !ok || apkg.dep || apkg.types == nil || bpkg.types == nil {
+			continue
+		}
+		c := c.withPkgOptions(apkg)
+		// types which are interfaces or generic, whose behaviour is
+		// unspecified until instantiated, never implement an interface
+		implements := func(typ types.Type, iface *types.Interface) bool {
+			return isConcrete(typ) && (implementsByName(typ, iface, c.qualifier) || implementsByName(types.NewPointer(typ), iface, c.qualifier))
+		}
+		ascope, bscope := apkg.types.Scope(), bpkg.types.Scope()
+		for _, id := range ascope.Names() {
+			aobj, ok := ascope.Lookup(id).(*types.TypeName)
+			if !ok || !aobj.Exported() || aobj.IsAlias() || !c.isIncluded(id) || c.isInternalID(apkg.decls, id) {
+				// aliases don't declare methods, their target does
+				continue
+			}
+			bobj, ok := bscope.Lookup(id).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			for _, name := range names {
+				iface := c.notable[name]
+				if implements(bobj.Type(), iface) || !implements(aobj.Type(), iface) {
+					continue
+				}
+				changes = append(changes, Change{
+					Pkg:        pkgName,
+					ID:         id,
+					Change:     NonBreaking,
+					Msg:        fmt.Sprintf("now implements %s, which may change runtime behaviour", name),
+					Pos:        pos(apkg.fset, aobj.Pos()),
+					Confidence: Medium,
+					Category:   categoryInterfaceImplemented,
+				})
+			}
+		}
+	}
+	return changes
+}
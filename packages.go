@@ -0,0 +1,362 @@
+package apicompat
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CheckPackages compares the exported API of two type checked packages, such
+// as those loaded by golang.org/x/tools/go/packages for apidiff, and returns the
+// changes. Unlike Check, the comparison uses only type information, so a
+// package without source, such as from export data, can be compared, but
+// changes have no position or declarations, and checks requiring source, such
+// as of struct tags or doc comments, aren't performed.
+//
+// Changes are described as Check describes them, except:
+//   - changes to function signatures are described only as parameter types
+//     changed or by each result's change, as the more specific descriptions,
+//     such as of variadic or options parameters, depend on the source
+//   - type sets narrowing are described without their terms
+//   - type parameters' constraints loosening, and methods moving between a
+//     type and its embedded type, aren't reported
+//
+// As with Check, types no longer implementing an exported interface of the
+// package or a notable interface, such as when a method moves to a pointer
+// receiver, are reported, see SetNotableInterfaces.
+func CheckPackages(before, after *types.Package) ([]Change, error) {
+	if before == nil || after == nil {
+		return nil, errors.New("before and after packages must not be nil")
+	}
+
+	d := DeclChecker{bpkg: before, apkg: after}
+	bobjs, aobjs := exportedObjects(before), exportedObjects(after)
+
+	var changes []Change
+	for id, bobj := range bobjs {
+		aobj, ok := aobjs[id]
+		if !ok {
+			changes = append(changes, Change{Pkg: after.Path(), ID: id, Change: Breaking, Msg: "declaration removed", Kind: Removal, Category: categoryDeclarationRemoved})
+			continue
+		}
+		if change := d.checkObjects(bobj, aobj); change.Change != None {
+			changes = append(changes, Change{Pkg: after.Path(), ID: id, Change: change.Change, Msg: change.Msg, Confidence: change.Confidence, Kind: change.Kind, Category: change.Category})
+		}
+	}
+	for id := range aobjs {
+		if _, ok := bobjs[id]; !ok {
+			changes = append(changes, Change{Pkg: after.Path(), ID: id, Change: NonBreaking, Msg: "declaration added", Kind: Addition, Category: categoryDeclarationAdded})
+		}
+	}
+	changes = append(changes, unimplementedChanges(before, after, bobjs, aobjs)...)
+	sort.Sort(byID(changes))
+	return changes, nil
+}
+
+// unimplementedChanges returns the types equivalent of satisfactionChanges,
+// for the exported types in both bobjs and aobjs, checked against the default
+// notable interfaces and before's exported interfaces.
+func unimplementedChanges(before, after *types.Package, bobjs, aobjs map[string]types.Object) []Change {
+	ifaces := make(map[string]*types.Interface)
+	for name, iface := range defaultNotableInterfaces {
+		ifaces[name] = iface
+	}
+	for id, iface := range exportedInterfaces(before) {
+		ifaces[id] = iface
+	}
+	var changes []Change
+	for id, aobj := range aobjs {
+		aname, ok := aobj.(*types.TypeName)
+		if !ok || aname.IsAlias() || !isConcrete(aname.Type()) {
+			continue
+		}
+		bname, ok := bobjs[id].(*types.TypeName)
+		if !ok || bname.IsAlias() || !isConcrete(bname.Type()) {
+			continue
+		}
+		for _, msg := range unimplementedMsgs(id, bname.Type(), aname.Type(), ifaces, nil) {
+			changes = append(changes, Change{Pkg: after.Path(), ID: id, Change: Breaking, Msg: msg, Category: categoryInterfaceUnimplemented})
+		}
+	}
+	return changes
+}
+
+// checkObjects compares two exported objects with the same ID, the types
+// equivalent of Check. The returned change has no position.
+func (c DeclChecker) checkObjects(before, after types.Object) DeclChange {
+	if reflect.TypeOf(before) != reflect.TypeOf(after) {
+		// such as a var becoming a func
+		return breaking("changed declaration", 0).withCategory(categoryTypeChanged)
+	}
+
+	switch b := before.(type) {
+	case *types.Const:
+		a := after.(*types.Const)
+		if !c.typesEqual(b.Type(), a.Type()) {
+			return breaking("changed type", 0).withCategory(categoryTypeChanged)
+		}
+		if change, ok := c.enumValueChange(b, a); ok {
+			return change
+		}
+		if change, ok := c.stringValueChange(b, a); ok {
+			return change
+		}
+		if change, ok := c.constValueChange(b, a); ok {
+			return change
+		}
+	case *types.Var:
+		if !c.typesEqual(b.Type(), after.Type()) {
+			return breaking("changed type", 0).withCategory(categoryTypeChanged)
+		}
+	case *types.Func:
+		return c.checkSignatures(b.Type().(*types.Signature), after.Type().(*types.Signature))
+	case *types.TypeName:
+		return c.checkTypeNames(b, after.(*types.TypeName))
+	}
+	return none()
+}
+
+// checkSignatures compares the signatures of two functions or methods.
+// Adding results to a function without results is compatible, see checkFunc.
+func (c DeclChecker) checkSignatures(before, after *types.Signature) DeclChange {
+	if before.TypeParams().Len() != after.TypeParams().Len() {
+		return breaking("type parameters changed", 0).withCategory(categoryTypeParametersChanged)
+	}
+	if msg := c.constraintsTightened(before.TypeParams(), after.TypeParams()); msg != "" {
+		return breaking(msg, 0).withCategory(categoryTypeParametersChanged)
+	}
+	if before.Variadic() != after.Variadic() || !c.tuplesEqual(before.Params(), after.Params()) {
+		return breaking("parameter types changed", 0).withCategory(categoryParametersChanged)
+	}
+	if before.Results().Len() == 0 {
+		return none()
+	}
+	if before.Results().Len() != after.Results().Len() {
+		return breaking("return parameters changed", 0).withCategory(categoryResultsChanged)
+	}
+	var msgs []string
+	for i := 0; i < before.Results().Len(); i++ {
+		btype, atype := before.Results().At(i).Type(), after.Results().At(i).Type()
+		if !c.typesEqual(btype, atype) {
+			msgs = append(msgs, resultChangedMsg(i, types.TypeString(btype, types.RelativeTo(c.bpkg)), types.TypeString(atype, types.RelativeTo(c.apkg))))
+		}
+	}
+	if len(msgs) > 0 {
+		return breaking(strings.Join(msgs, "; "), 0).withCategory(categoryResultsChanged)
+	}
+	return none()
+}
+
+// checkTypeNames compares two type declarations, including aliases, but not
+// their methods, which are compared separately.
+func (c DeclChecker) checkTypeNames(before, after *types.TypeName) DeclChange {
+	switch {
+	case before.IsAlias() && !after.IsAlias():
+		return breaking("alias changed to a defined type", 0).withCategory(categoryAliasChanged)
+	case !before.IsAlias() && after.IsAlias():
+		return breaking("defined type changed to an alias", 0).withCategory(categoryAliasChanged)
+	case after.IsAlias():
+		btype, atype := types.Unalias(before.Type()), types.Unalias(after.Type())
+		if c.typesEqual(btype, atype) {
+			return none()
+		}
+		return breaking(fmt.Sprintf("alias changed its target type: %s → %s",
+			types.TypeString(btype, types.RelativeTo(c.bpkg)), types.TypeString(atype, types.RelativeTo(c.apkg))), 0).withCategory(categoryAliasChanged)
+	}
+
+	bnamed, bok := before.Type().(*types.Named)
+	anamed, aok := after.Type().(*types.Named)
+	if !bok || !aok {
+		return none()
+	}
+	bparams, aparams := bnamed.TypeParams(), anamed.TypeParams()
+	switch {
+	case bparams.Len() == 0 && aparams.Len() > 0:
+		return breaking(fmt.Sprintf("type %s became generic", after.Name()), 0).withCategory(categoryTypeParametersChanged)
+	case bparams.Len() > 0 && aparams.Len() == 0:
+		return breaking(fmt.Sprintf("type %s is no longer generic", after.Name()), 0).withCategory(categoryTypeParametersChanged)
+	case bparams.Len() != aparams.Len():
+		return breaking("type parameters changed", 0).withCategory(categoryTypeParametersChanged)
+	}
+	if msg := c.constraintsTightened(bparams, aparams); msg != "" {
+		return breaking(msg, 0).withCategory(categoryTypeParametersChanged)
+	}
+
+	bunder, aunder := bnamed.Underlying(), anamed.Underlying()
+	if reflect.TypeOf(bunder) != reflect.TypeOf(aunder) {
+		// such as a struct becoming an interface
+		return breaking("changed type of value spec", 0).withCategory(categoryTypeChanged)
+	}
+	switch b := bunder.(type) {
+	case *types.Struct:
+		return c.checkStructTypes(b, aunder.(*types.Struct))
+	case *types.Interface:
+		return c.checkInterfaceTypes(b, aunder.(*types.Interface))
+	}
+	if !c.typesEqual(bunder, aunder) {
+		return breaking("changed type", 0).withCategory(categoryTypeChanged)
+	}
+	return none()
+}
+
+// checkStructTypes compares the exported and embedded fields of two structs,
+// describing changes as checkStruct does.
+func (c DeclChecker) checkStructTypes(before, after *types.Struct) DeclChange {
+	bfields, afields := exportedFields(before), exportedFields(after)
+	var modified []string
+	for _, bfield := range bfields {
+		afield := lookupField(afields, bfield.Name())
+		if afield == nil {
+			return breaking("members removed", 0).withKind(Removal).withCategory(categoryMembersRemoved)
+		}
+		if !c.typesEqual(bfield.Type(), afield.Type()) {
+			_, named := bfield.Type().(*types.Named)
+			_, anonymous := afield.Type().(*types.Struct)
+			modified = append(modified, fieldChangedMsg(structFieldKey(bfield, c.bpkg),
+				types.TypeString(bfield.Type(), types.RelativeTo(c.bpkg)), types.TypeString(afield.Type(), types.RelativeTo(c.apkg)),
+				named && anonymous && isStruct(bfield.Type())))
+		}
+	}
+	if len(modified) > 0 {
+		return breaking("members changed types: "+strings.Join(modified, "; "), 0).withCategory(categoryMembersChanged)
+	}
+	added := len(afields) > len(bfields)
+	if !added {
+		var bnames, anames []string
+		for _, field := range bfields {
+			bnames = append(bnames, structFieldKey(field, c.bpkg))
+		}
+		for _, field := range afields {
+			anames = append(anames, structFieldKey(field, c.apkg))
+		}
+		if change, ok := c.fieldsReordered(bnames, anames); ok {
+			return change
+		}
+	}
+	if isEmptyStruct(before) && !isZeroSize(c.sizes, after) {
+		if added {
+			return nonBreaking("members added: "+noLongerZeroSizeMsg, 0).withKind(Addition).withCategory(categoryMembersAdded)
+		}
+		return nonBreaking(noLongerZeroSizeMsg, 0).withCategory(categoryTypeChanged)
+	}
+	if added {
+		return nonBreaking("members added", 0).withKind(Addition).withCategory(categoryMembersAdded)
+	}
+	return none()
+}
+
+// exportedFields returns a struct's exported and embedded fields in order.
+func exportedFields(s *types.Struct) []*types.Var {
+	var fields []*types.Var
+	for i := 0; i < s.NumFields(); i++ {
+		if field := s.Field(i); field.Exported() || field.Embedded() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// lookupField returns the field with the given name, or nil if there's none.
+func lookupField(fields []*types.Var, name string) *types.Var {
+	for _, field := range fields {
+		if field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// isStruct returns true if typ's underlying type is a struct.
+func isStruct(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Struct)
+	return ok
+}
+
+// structFieldKey returns the name of a struct's field declared in pkg, or for
+// an embedded field, its type's name qualified by package name, such as
+// *io.Reader, as fieldKey names the field.
+func structFieldKey(field *types.Var, pkg *types.Package) string {
+	if !field.Embedded() {
+		return field.Name()
+	}
+	typ, star := field.Type(), ""
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ, star = ptr.Elem(), "*"
+	}
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || pkg != nil && named.Obj().Pkg().Path() == pkg.Path() {
+		return star + field.Name()
+	}
+	return star + named.Obj().Pkg().Name() + "." + named.Obj().Name()
+}
+
+// checkInterfaceTypes compares the method sets of two interfaces, including
+// embedded interfaces' methods, describing changes as checkInterface does for
+// an interface type declaration. Changes to the method set are breaking, as
+// added methods break implementations, unless only the interface's package
+// implements it, see SetInterfacePerspective, and removed methods break
+// callers.
+func (c DeclChecker) checkInterfaceTypes(before, after *types.Interface) DeclChange {
+	var (
+		added, removed, modified int
+		methods                  []string // describes each modified method, see methodsChangedMsgs
+	)
+	for i := 0; i < before.NumMethods(); i++ {
+		bmethod := before.Method(i)
+		obj, _, _ := types.LookupFieldOrMethod(after, false, bmethod.Pkg(), bmethod.Name())
+		switch amethod, ok := obj.(*types.Func); {
+		case !ok:
+			removed++
+		case !c.typesEqual(bmethod.Type(), amethod.Type()):
+			modified++
+			if change := c.checkSignatures(bmethod.Type().(*types.Signature), amethod.Type().(*types.Signature)); change.Change == Breaking {
+				methods = append(methods, methodChangedMsg(bmethod.Name(), change.Msg))
+			}
+		}
+	}
+	for i := 0; i < after.NumMethods(); i++ {
+		amethod := after.Method(i)
+		if obj, _, _ := types.LookupFieldOrMethod(before, false, amethod.Pkg(), amethod.Name()); obj == nil {
+			added++
+		}
+	}
+	relation := methodSetRelation(removed, added, modified, before.NumMethods())
+	switch {
+	case added > 0 && removed > 0:
+		return breaking("members added and removed: "+relation, 0).withCategory(categoryMembersAddedRemoved)
+	case added > 0:
+		if c.perspective == PerspectiveCaller {
+			return nonBreaking("members added: "+relation, 0).withKind(Addition).withCategory(categoryMembersAdded)
+		}
+		return breaking("members added: "+relation, 0).withKind(Addition).withCategory(categoryMembersAdded)
+	case modified > 0:
+		msg := "members changed types: " + relation
+		if len(methods) > 0 {
+			msg += "; " + strings.Join(methods, "; ")
+		}
+		return breaking(msg, 0).withCategory(categoryMembersChanged)
+	case removed > 0:
+		return breaking("members removed: "+relation, 0).withKind(Removal).withCategory(categoryMembersRemoved)
+	}
+	if constraintTightened(before, c.bpkg, after, c.apkg) {
+		return breaking("type set narrowed", 0).withCategory(categoryTypeSetChanged)
+	}
+	return none()
+}
+
+// tuplesEqual returns true if before and after have the same types, ignoring
+// names.
+func (c DeclChecker) tuplesEqual(before, after *types.Tuple) bool {
+	if before.Len() != after.Len() {
+		return false
+	}
+	for i := 0; i < before.Len(); i++ {
+		if !c.typesEqual(before.At(i).Type(), after.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package apicompat
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// checkTypes type checks src as the package example.com/lib, as loaded by
+// go/packages, which isn't available to these tests.
+func checkTypes(t *testing.T, src string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "lib.go", src, 0)
+	if err != nil {
+		t.Fatalf("could not parse: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("example.com/lib", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("could not type check: %v\n%s", err, src)
+	}
+	return pkg
+}
+
+func TestCheckPackages(t *testing.T) {
+	const (
+		before = "package lib\n\nimport \"io\"\n\n" +
+			"const Mode = \"fast\"\n" +
+			"var V int\n" +
+			"func Removed() {}\n" +
+			"func Params(a int) {}\n" +
+			"func Results() (int, error) { return 0, nil }\n" +
+			"type S struct{ A int; B string }\n" +
+			"type Grow struct{ A int }\n" +
+			"type I interface{ io.Reader }\n" +
+			"type Alias = int\n" +
+			"type G[T any] struct{}\n" +
+			"type T struct{}\n" +
+			"func (T) M(a int) {}\n" +
+			"type P struct{}\n" +
+			"func (P) String() string { return \"\" }\n" +
+			"type R struct{ A, B int; io.Reader }\n"
+		after = "package lib\n\nimport \"io\"\n\n" +
+			"const Mode = \"slow\"\n" +
+			"var V uint\n" +
+			"func Added() {}\n" +
+			"func Params(a string) {}\n" +
+			"func Results() (int64, error) { return 0, nil }\n" +
+			"type S struct{ A int64; B string }\n" +
+			"type Grow struct{ A, B int }\n" +
+			"type I interface{ io.ReadCloser }\n" +
+			"type Alias = string\n" +
+			"type G[T comparable] struct{}\n" +
+			"type T struct{}\n" +
+			"func (T) M(a int) {}\n" +
+			"type P struct{}\n" +
+			"func (*P) String() string { return \"\" }\n" +
+			"type R struct{ io.Reader; B, A int }\n"
+	)
+	changes, err := CheckPackages(checkTypes(t, before), checkTypes(t, after))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := []Change{
+		{ID: "Added", Change: NonBreaking, Msg: "declaration added"},
+		{ID: "Alias", Change: Breaking, Msg: "alias changed its target type: int → string"},
+		{ID: "G", Change: Breaking, Msg: "type parameter T constraint tightened: any → comparable"},
+		{ID: "Grow", Change: NonBreaking, Msg: "members added"},
+		{ID: "I", Change: Breaking, Msg: "members added: after is a superset of before"},
+		{ID: "Mode", Change: NonBreaking, Msg: `string constant Mode changed value "fast" → "slow"`},
+		{ID: "P", Change: Breaking, Msg: "P no longer implements fmt.Stringer, only *P does"},
+		{ID: "Params", Change: Breaking, Msg: "parameter types changed"},
+		{ID: "R", Change: Breaking, Msg: "struct fields reordered: A, B, io.Reader → io.Reader, B, A"},
+		{ID: "Removed", Change: Breaking, Msg: "declaration removed"},
+		{ID: "Results", Change: Breaking, Msg: "return value 1 changed: int → int64"},
+		{ID: "S", Change: Breaking, Msg: "members changed types: field A: int → int64"},
+		{ID: "V", Change: Breaking, Msg: "changed type"},
+	}
+	if len(changes) != len(exp) {
+		t.Fatalf("exp %d changes got %d: %v", len(exp), len(changes), changes)
+	}
+	for i, e := range exp {
+		c := changes[i]
+		if c.Pkg != "example.com/lib" || c.ID != e.ID || c.Change != e.Change || c.Msg != e.Msg {
+			t.Errorf("change %d\nexp: %s %s %s\ngot: %s %s %s %s", i, e.ID, e.Change, e.Msg, c.Pkg, c.ID, c.Change, c.Msg)
+		}
+	}
+
+	if _, err := CheckPackages(nil, checkTypes(t, after)); err == nil {
+		t.Error("expected error for nil package")
+	}
+}
@@ -0,0 +1,89 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// provenanceChange returns a change if the method id, such as T.M, is declared
+// directly on T at one revision, but promoted from a type embedded in T at the
+// other, with the same signature. The method sets of T and *T are unchanged, so
+// it's non-breaking, but the method's documentation and method values, such as
+// comparing T.M, may differ.
+func (c Checker) provenanceChange(pkgName, id string) (Change, bool) {
+	bpkg, apkg := c.b[pkgName], c.a[pkgName]
+	dot := strings.IndexByte(id, '.')
+	if dot < 0 || bpkg.types == nil || apkg.types == nil {
+		return Change{}, false
+	}
+	typeName, method := id[:dot], id[dot+1:]
+	bobj, ok := bpkg.types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return Change{}, false
+	}
+	aobj, ok := apkg.types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return Change{}, false
+	}
+	bsel, asel, ok := sameMethod(bobj.Type(), aobj.Type(), method, c.qualifier)
+	if !ok {
+		return Change{}, false
+	}
+
+	bpromoted, apromoted := len(bsel.Index()) > 1, len(asel.Index()) > 1
+	var msg string
+	switch {
+	case bpromoted && !apromoted:
+		msg = fmt.Sprintf("method %s now declared directly, was promoted from embedded %s, method set unchanged",
+			method, embeddedName(bobj.Type(), bsel))
+	case !bpromoted && apromoted:
+		msg = fmt.Sprintf("method %s now promoted from embedded %s, was declared directly, method set unchanged",
+			method, embeddedName(aobj.Type(), asel))
+	default:
+		return Change{}, false
+	}
+	position := aobj.Pos()
+	if !apromoted {
+		position = asel.Obj().Pos()
+	}
+	return Change{
+		Pkg:      pkgName,
+		ID:       id,
+		Change:   NonBreaking,
+		Msg:      msg,
+		Pos:      pos(apkg.fset, position),
+		Category: categoryMemberProvenance,
+	}, true
+}
+
+// sameMethod returns the selections of the method name in the method sets of
+// *btyp and *atyp, and true if the method is in the same method sets, of the
+// type and its pointer, with the same signature, qualified by q, at both
+// revisions.
+func sameMethod(btyp, atyp types.Type, name string, q types.Qualifier) (bsel, asel *types.Selection, ok bool) {
+	for _, ptr := range []bool{false, true} {
+		bset, aset := btyp, atyp
+		if ptr {
+			bset, aset = types.NewPointer(btyp), types.NewPointer(atyp)
+		}
+		bsel = types.NewMethodSet(bset).Lookup(nil, name)
+		asel = types.NewMethodSet(aset).Lookup(nil, name)
+		if (bsel == nil) != (asel == nil) {
+			return nil, nil, false
+		}
+	}
+	if bsel == nil || types.TypeString(bsel.Type(), q) != types.TypeString(asel.Type(), q) {
+		return nil, nil, false
+	}
+	return bsel, asel, true
+}
+
+// embeddedName returns the name of the field embedded in typ which sel, a
+// promoted method, is promoted from.
+func embeddedName(typ types.Type, sel *types.Selection) string {
+	if s, ok := typ.Underlying().(*types.Struct); ok {
+		return s.Field(sel.Index()[0]).Name()
+	}
+	return "type"
+}
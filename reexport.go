@@ -0,0 +1,97 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// reexport is an exported alias of a declaration in an internal package, such
+// as type Foo = internal.Foo.
+type reexport struct {
+	pkg string // import path of the package declaring the alias
+	id  string // alias name
+	pos string // position of the alias
+}
+
+// reexportChanges returns changes with each change to a declaration in an
+// internal package, or its members, also reported as a change to each alias
+// re-exporting it, whose Origin is the internal declaration. If the Checker
+// collapses re-exports, the internal declaration's change is annotated with
+// its re-exports instead.
+func (c Checker) reexportChanges(changes []Change) []Change {
+	reexports := c.reexports()
+	if len(reexports) == 0 {
+		return changes
+	}
+
+	var annotated []Change
+	for _, change := range changes {
+		name, member := change.ID, ""
+		if i := strings.Index(name, "."); i >= 0 {
+			name, member = name[:i], name[i:]
+		}
+		origin := change.Pkg + "." + name
+		if len(reexports[origin]) == 0 {
+			annotated = append(annotated, change)
+			continue
+		}
+		if c.collapseReexports {
+			var names []string
+			for _, r := range reexports[origin] {
+				names = append(names, r.pkg+"."+r.id)
+			}
+			change.Msg = fmt.Sprintf("%s (re-exported as %s)", change.Msg, strings.Join(names, ", "))
+			annotated = append(annotated, change)
+			continue
+		}
+		annotated = append(annotated, change)
+		for _, r := range reexports[origin] {
+			reexported := change
+			reexported.Pkg, reexported.ID, reexported.Pos = r.pkg, r.id+member, r.pos
+			reexported.Origin = origin + member
+			reexported.Msg = fmt.Sprintf("%s (re-export of %s)", change.Msg, reexported.Origin)
+			annotated = append(annotated, reexported)
+		}
+	}
+	return annotated
+}
+
+// reexports returns the exported aliases in the after packages, excluding
+// dependencies, keyed by the declaration in an internal package they refer
+// to, such as example.com/mod/internal/foo.Foo.
+func (c Checker) reexports() map[string][]reexport {
+	reexports := make(map[string][]reexport)
+	for pkgName, apkg := range c.a {
+		if apkg.dep || apkg.types == nil {
+			continue
+		}
+		c := c.withPkgOptions(apkg)
+		scope := apkg.types.Scope()
+		for _, id := range scope.Names() {
+			obj, ok := scope.Lookup(id).(*types.TypeName)
+			if !ok || !obj.Exported() || !obj.IsAlias() || !c.isIncluded(id) || c.isInternalID(apkg.decls, id) {
+				continue
+			}
+			named, ok := types.Unalias(obj.Type()).(*types.Named)
+			if !ok || named.Obj().Pkg() == nil || !isInternal(named.Obj().Pkg().Path()) {
+				continue
+			}
+			origin := named.Obj().Pkg().Path() + "." + named.Obj().Name()
+			reexports[origin] = append(reexports[origin], reexport{pkg: pkgName, id: id, pos: pos(apkg.fset, obj.Pos())})
+		}
+	}
+	for _, r := range reexports {
+		sort.Slice(r, func(i, j int) bool {
+			return r[i].pkg+"."+r[i].id < r[j].pkg+"."+r[j].id
+		})
+	}
+	return reexports
+}
+
+// isInternal returns true if the import path is of an internal package, or a
+// package within one.
+func isInternal(path string) bool {
+	return strings.Contains("/"+path+"/", "/internal/")
+}
@@ -0,0 +1,227 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// correlateRenames replaces each removed top level function in pkgName which
+// has the same signature as exactly one added function, and vice versa, with a
+// single change describing the function as likely renamed.
+func correlateRenames(d *DeclChecker, pkgName string, apkg pkg, changes []Change) []Change {
+	var removed, added []int // indexes of changes to top level functions
+	for i, c := range changes {
+		if c.Pkg != pkgName {
+			continue
+		}
+		switch {
+		case isFunc(c.Before) && c.After == nil:
+			removed = append(removed, i)
+		case c.Before == nil && isFunc(c.After):
+			added = append(added, i)
+		}
+	}
+
+	// find the added functions with the same signature as each removed
+	matches := make(map[int][]int)
+	for _, ri := range removed {
+		for _, ai := range added {
+			bfunc, afunc := changes[ri].Before.(*ast.FuncDecl), changes[ai].After.(*ast.FuncDecl)
+			if change, err := d.checkFunc(bfunc.Type, afunc.Type); err == nil && change.Change == None {
+				matches[ri] = append(matches[ri], ai)
+				matches[ai] = append(matches[ai], ri)
+			}
+		}
+	}
+
+	drop := make(map[int]bool)
+	for _, ri := range removed {
+		if len(matches[ri]) != 1 || len(matches[matches[ri][0]]) != 1 {
+			// no match or ambiguous
+			continue
+		}
+		ai := matches[ri][0]
+		bfunc, afunc := changes[ri].Before.(*ast.FuncDecl), changes[ai].After.(*ast.FuncDecl)
+		changes[ri] = Change{
+			Pkg:        pkgName,
+			ID:         changes[ri].ID,
+			Change:     Breaking,
+			Msg:        fmt.Sprintf("function %s likely renamed to %s, callers should use %s", bfunc.Name.Name, afunc.Name.Name, afunc.Name.Name),
+			Pos:        pos(apkg.fset, afunc.Pos()),
+			Before:     bfunc,
+			After:      afunc,
+			HighImpact: changes[ri].HighImpact,
+			Confidence: Medium,
+			Kind:       changes[ri].Kind,
+			Category:   categoryDeclarationRenamed,
+		}
+		drop[ai] = true
+	}
+
+	if len(drop) == 0 {
+		return changes
+	}
+	kept := changes[:0]
+	for i, c := range changes {
+		if !drop[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// correlateConsolidations replaces the removed top level functions in pkgName
+// which are each a specialisation of the same added generic function, such as
+// MaxInt and MaxFloat64 becoming Max[T cmp.Ordered], with a single change
+// describing the functions as replaced by the generic function. A removed
+// function is a specialisation if existing calls would compile when calling
+// the generic function instead, see genericizedChange.
+func correlateConsolidations(d *DeclChecker, pkgName string, apkg pkg, changes []Change) []Change {
+	var removed, added []int // indexes of changes to top level functions
+	for i, c := range changes {
+		if c.Pkg != pkgName {
+			continue
+		}
+		switch {
+		case isFunc(c.Before) && c.After == nil:
+			removed = append(removed, i)
+		case c.Before == nil && isFunc(c.After) && c.After.(*ast.FuncDecl).Type.TypeParams.NumFields() > 0:
+			added = append(added, i)
+		}
+	}
+
+	drop := make(map[int]bool)
+	for _, ai := range added {
+		afunc := changes[ai].After.(*ast.FuncDecl)
+		var specialised []int
+		for _, ri := range removed {
+			if drop[ri] {
+				continue
+			}
+			bfunc := changes[ri].Before.(*ast.FuncDecl)
+			if change, ok := d.genericizedChange(bfunc.Type, afunc.Type); ok && change.Change == NonBreaking {
+				specialised = append(specialised, ri)
+			}
+		}
+		if len(specialised) < 2 {
+			// a single function made generic and renamed is a rename
+			continue
+		}
+
+		var (
+			names      []string
+			highImpact bool
+		)
+		for _, ri := range specialised {
+			names = append(names, changes[ri].Before.(*ast.FuncDecl).Name.Name)
+			highImpact = highImpact || changes[ri].HighImpact
+			drop[ri] = true
+		}
+		sort.Strings(names)
+		generic := fmt.Sprintf("%s[%s]", afunc.Name.Name, strings.Join(fieldNames(afunc.Type.TypeParams), ", "))
+		changes[ai] = Change{
+			Pkg:        pkgName,
+			ID:         changes[ai].ID,
+			Change:     Breaking,
+			Msg:        fmt.Sprintf("%s replaced by generic %s, callers should use %s", strings.Join(names, ", "), generic, afunc.Name.Name),
+			Pos:        pos(apkg.fset, afunc.Pos()),
+			After:      afunc,
+			HighImpact: highImpact,
+			Confidence: Medium,
+			Kind:       changes[specialised[0]].Kind,
+			Category:   categoryDeclarationRenamed,
+		}
+	}
+
+	if len(drop) == 0 {
+		return changes
+	}
+	kept := changes[:0]
+	for i, c := range changes {
+		if !drop[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// correlateAddedResults annotates each change to a function or method in
+// pkgName whose added or changed return values are of types added in the same
+// revision, with those types, such as a function newly returning Result, which
+// is also added. Only the function's change is breaking, the added type is the
+// type's own, non-breaking, change.
+func correlateAddedResults(d *DeclChecker, pkgName string, apkg pkg, changes []Change) []Change {
+	added := make(map[string]bool) // IDs of added types
+	for _, c := range changes {
+		if c.Pkg != pkgName || c.Before != nil || !isType(c.After) {
+			continue
+		}
+		added[c.ID] = true
+	}
+	if len(added) == 0 {
+		return changes
+	}
+
+	for i, c := range changes {
+		bfunc, ok := c.Before.(*ast.FuncDecl)
+		if c.Pkg != pkgName || !ok || bfunc.Type.Results == nil {
+			continue
+		}
+		afunc, ok := c.After.(*ast.FuncDecl)
+		if !ok || afunc.Type.Results == nil {
+			continue
+		}
+		r := d.diffFields(keyOnPosition, stripNames(bfunc.Type.Results.List), stripNames(afunc.Type.Results.List))
+		changed := make(map[ast.Expr]bool) // types of the added and modified results
+		for _, field := range r.added {
+			changed[field.Type] = true
+		}
+		for _, modified := range r.modified {
+			changed[modified[1].Type] = true
+		}
+
+		var names []string
+		seen := make(map[string]bool)
+		for _, field := range afunc.Type.Results.List {
+			if !changed[field.Type] {
+				continue
+			}
+			named, ok := derefNamed(apkg.info.TypeOf(field.Type))
+			if !ok || named.Obj().Pkg() != apkg.types || !added[named.Obj().Name()] || seen[named.Obj().Name()] {
+				continue
+			}
+			seen[named.Obj().Name()] = true
+			names = append(names, named.Obj().Name())
+		}
+		if len(names) > 0 {
+			changes[i].Msg = fmt.Sprintf("%s; see also added type %s", c.Msg, strings.Join(names, ", "))
+		}
+	}
+	return changes
+}
+
+// derefNamed returns the named type of typ, or of the type typ points to,
+// such as Result for *Result.
+func derefNamed(typ types.Type) (*types.Named, bool) {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	return named, ok
+}
+
+// isType returns true if decl declares a type.
+func isType(decl ast.Decl) bool {
+	gdecl, ok := decl.(*ast.GenDecl)
+	return ok && len(gdecl.Specs) > 0 && gdecl.Tok == token.TYPE
+}
+
+// isFunc returns true if decl is a top level function, and not a method.
+func isFunc(decl ast.Decl) bool {
+	fdecl, ok := decl.(*ast.FuncDecl)
+	return ok && fdecl.Recv == nil
+}
@@ -0,0 +1,48 @@
+package apicompat
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Report returns a human readable summary of changes, grouped into breaking
+// and non-breaking sections each with a count, followed by a total line.
+// Changes within a section are sorted by package and then ID, empty sections
+// are omitted.
+func Report(changes []Change) string {
+	sections := []struct {
+		title  string
+		change string
+	}{
+		{"Breaking", Breaking},
+		{"Non-breaking", NonBreaking},
+	}
+
+	var (
+		buf    bytes.Buffer
+		counts = make(map[string]int)
+	)
+	for _, section := range sections {
+		filtered := filterChanges(changes, func(c Change) bool {
+			return c.Change == section.change
+		})
+		counts[section.change] = len(filtered)
+		if len(filtered) == 0 {
+			continue
+		}
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if filtered[i].Pkg != filtered[j].Pkg {
+				return filtered[i].Pkg < filtered[j].Pkg
+			}
+			return filtered[i].ID < filtered[j].ID
+		})
+		fmt.Fprintf(&buf, "%s (%d):\n", section.title, len(filtered))
+		for _, c := range filtered {
+			buf.WriteString(c.String())
+		}
+		fmt.Fprintln(&buf)
+	}
+	fmt.Fprintf(&buf, "Total: %d breaking, %d non-breaking\n", counts[Breaking], counts[NonBreaking])
+	return buf.String()
+}
@@ -0,0 +1,81 @@
+package apicompat
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReport tests changes are grouped by severity, sorted within each group
+// and counted.
+func TestReport(t *testing.T) {
+	changes := []Change{
+		{Pkg: "b", ID: "A", Change: Breaking, Msg: "declaration removed", Pos: "rev1:b.go:1"},
+		{Pkg: "a", ID: "Z", Change: NonBreaking, Msg: "declaration added", Pos: "rev2:a.go:3"},
+		{Pkg: "a", ID: "B", Change: Breaking, Msg: "changed type", Pos: "rev2:a.go:2"},
+		{Pkg: "a", ID: "A", Change: NonBreaking, Msg: "members added", Pos: "rev2:a.go:1"},
+		{Pkg: "a", ID: "C", Change: Breaking, Msg: "parameters types changed", Pos: "rev2:a.go:4"},
+	}
+
+	const exp = `Breaking (3):
+rev2:a.go:2: breaking change changed type
+rev2:a.go:4: breaking change parameters types changed
+rev1:b.go:1: breaking change declaration removed
+
+Non-breaking (2):
+rev2:a.go:1: non-breaking change members added
+rev2:a.go:3: non-breaking change declaration added
+
+Total: 3 breaking, 2 non-breaking
+`
+	if got := Report(changes); got != exp {
+		t.Errorf("unexpected report\nexp:\n%s\ngot:\n%s", exp, got)
+	}
+
+	const expEmpty = "Total: 0 breaking, 0 non-breaking\n"
+	if got := Report(nil); got != expEmpty {
+		t.Errorf("unexpected empty report\nexp: %q\ngot: %q", expEmpty, got)
+	}
+}
+
+// TestChangeLine tests changes are formatted as a single line of tab separated
+// columns.
+func TestChangeLine(t *testing.T) {
+	tests := []struct {
+		change Change
+		exp    string
+	}{
+		{
+			Change{Pkg: "example.com/a", ID: "A", Change: Breaking, Msg: "declaration removed", Pos: "rev1:a.go:1"},
+			"rev1:a.go:1\tbreaking change\texample.com/a.A\tdeclaration removed",
+		},
+		{
+			Change{Pkg: "example.com/a", ID: "T.M", Change: NonBreaking, Msg: "declaration added", Pos: "rev2:a.go:3"},
+			"rev2:a.go:3\tnon-breaking change\texample.com/a.T.M\tdeclaration added",
+		},
+		{
+			Change{Pkg: "example.com/a", ID: "F", Change: Breaking, Msg: "return value 1 changed:\tint → uint\n", Pos: "a.go:2"},
+			"a.go:2\tbreaking change\texample.com/a.F\treturn value 1 changed: int → uint ",
+		},
+		{
+			Change{Pkg: "example.com/a", ID: "G", Change: Breaking, Msg: "declaration added", Pos: "rev2:a.go:4", Strict: true},
+			"rev2:a.go:4\tbreaking change\texample.com/a.G\tdeclaration added (breaking for strict consumers)",
+		},
+		{
+			Change{Pkg: "example.com/b", Change: Breaking, Msg: "package removed"},
+			"\tbreaking change\texample.com/b\tpackage removed",
+		},
+		{
+			Change{ID: "V", Msg: "changed"},
+			"\tno change\tV\tchanged",
+		},
+	}
+	for _, test := range tests {
+		got := test.change.Line()
+		if got != test.exp {
+			t.Errorf("exp %q got %q", test.exp, got)
+		}
+		if cols := strings.Split(got, "\t"); len(cols) != 4 {
+			t.Errorf("exp 4 columns got %d: %q", len(cols), got)
+		}
+	}
+}
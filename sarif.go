@@ -0,0 +1,180 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+// sarifVersion and sarifSchema identify the version of SARIF produced by SARIF.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifRule is a category of change, identified by the change's Category.
+type sarifRule struct {
+	id          string
+	description string
+}
+
+// sarifRules is the catalog of change categories, set on changes when they're
+// created, changes without a category belong to sarifOther.
+var sarifRules = []sarifRule{
+	{categoryDeclarationRemoved, "An exported declaration was removed"},
+	{categoryDeclarationAdded, "An exported declaration was added"},
+	{categoryDeclarationRenamed, "An exported declaration was likely renamed or replaced"},
+	{categoryMembersAddedRemoved, "Members of a type were added and removed"},
+	{categoryMembersAdded, "Members of a type were added"},
+	{categoryMembersRemoved, "Members of a type were removed"},
+	{categoryMembersChanged, "Members of a type changed type"},
+	{categoryMemberShadowed, "A member now shadows a promoted member"},
+	{categoryMemberProvenance, "A method moved between a type and its embedded type"},
+	{categoryTypeParametersChanged, "Type parameters were added or changed"},
+	{categoryVariadicChanged, "A variadic parameter changed"},
+	{categoryParametersChanged, "Function parameters changed"},
+	{categoryResultsChanged, "Function results changed"},
+	{categoryTypeSetChanged, "The type set of a constraint changed"},
+	{categoryAliasChanged, "A type alias changed"},
+	{categoryInterfaceImplemented, "A type now implements a notable interface"},
+	{categoryInterfaceUnimplemented, "A type no longer implements an interface"},
+	{categoryInterfaceCompatible, "An interface changed compatibly"},
+	{categoryStructFieldsReordered, "A struct's fields were reordered"},
+	{categoryStructTagsChanged, "Struct tags or wire format changed"},
+	{categoryStructLayoutChanged, "A struct's memory layout changed"},
+	{categoryZeroValueChanged, "A struct's zero value may no longer be usable"},
+	{categoryConstantValueChanged, "A constant changed value"},
+	{categoryConcurrencyDocChanged, "A declaration's concurrency documentation changed"},
+	{categoryGenerateReference, "A go:generate directive references a removed declaration"},
+	{categoryTypeChanged, "A declaration changed type"},
+}
+
+// sarifOther is the rule of changes without a category, or with a category
+// not in sarifRules.
+var sarifOther = sarifRule{id: "other", description: "A declaration changed"}
+
+// sarifRuleOf returns the rule of a change, based on its category.
+func sarifRuleOf(c Change) sarifRule {
+	for _, rule := range sarifRules {
+		if rule.id == c.Category {
+			return rule
+		}
+	}
+	return sarifOther
+}
+
+// The types below are the subset of a SARIF 2.1.0 log used by SARIF, see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string               `json:"name"`
+		InformationURI string               `json:"informationUri"`
+		Rules          []sarifReportingRule `json:"rules"`
+	}
+	sarifReportingRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// SARIF returns a SARIF 2.1.0 log of changes, such as for GitHub code
+// scanning. Each change is a result whose rule is the change's category, see
+// sarifRules, with breaking changes at level error, others at level note. Only
+// the rules of the changes are included.
+func SARIF(changes []Change) []byte {
+	var (
+		rules   = []sarifReportingRule{}
+		results = []sarifResult{}
+		index   = make(map[string]int) // rule id to index in rules
+	)
+	for _, c := range changes {
+		rule := sarifRuleOf(c)
+		i, ok := index[rule.id]
+		if !ok {
+			i = len(rules)
+			index[rule.id] = i
+			rules = append(rules, sarifReportingRule{ID: rule.id, ShortDescription: sarifMessage{rule.description}})
+		}
+		level := "note"
+		if c.Change == Breaking {
+			level = "error"
+		}
+		result := sarifResult{
+			RuleID:    rule.id,
+			RuleIndex: i,
+			Level:     level,
+			Message:   sarifMessage{c.ID + ": " + c.Msg},
+		}
+		if loc, ok := sarifLocationOf(c.Pos); ok {
+			result.Locations = []sarifLocation{loc}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "apicompat",
+				InformationURI: "https://github.com/bradleyfalzon/apicompat",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	b, _ := json.MarshalIndent(log, "", "  ")
+	return b
+}
+
+// sarifLocationOf returns the location of a change's position, such as
+// rev2:pkg/file.go:10, excluding any revision prefix. Returns false if pos
+// has no filename.
+func sarifLocationOf(pos string) (sarifLocation, bool) {
+	p := parsePos(pos)
+	if p.file == "" {
+		return sarifLocation{}, false
+	}
+	var region *sarifRegion
+	if p.line > 0 {
+		region = &sarifRegion{StartLine: p.line}
+	}
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(p.file)},
+		Region:           region,
+	}}, true
+}
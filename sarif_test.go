@@ -0,0 +1,117 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestSARIF tests changes are converted to a SARIF log with the shape
+// required by the SARIF 2.1.0 schema, sharing rules between results.
+func TestSARIF(t *testing.T) {
+	changes := []Change{
+		{Pkg: "a", ID: "A", Change: Breaking, Msg: "declaration removed", Pos: "rev1:a.go:1", Category: categoryDeclarationRemoved},
+		{Pkg: "a", ID: "B", Change: Breaking, Msg: "parameter types changed", Pos: "rev2:pkg/b.go:12", Category: categoryParametersChanged},
+		{Pkg: "a", ID: "C", Change: NonBreaking, Msg: "declaration added", Pos: "c.go:3", Category: categoryDeclarationAdded},
+		{Pkg: "a", ID: "D", Change: Breaking, Msg: "declaration removed", Pos: ":0", Category: categoryDeclarationRemoved},
+		{Pkg: "a", ID: "E", Change: NonBreaking, Msg: "something else"},
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Schema  string `json:"$schema"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(SARIF(changes), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || log.Schema == "" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: version %q, schema %q, %d runs", log.Version, log.Schema, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "apicompat" {
+		t.Errorf("unexpected driver name: %q", run.Tool.Driver.Name)
+	}
+
+	expRules := []string{"declaration-removed", "parameters-changed", "declaration-added", "other"}
+	if len(run.Tool.Driver.Rules) != len(expRules) {
+		t.Fatalf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	for i, rule := range run.Tool.Driver.Rules {
+		if rule.ID != expRules[i] || rule.ShortDescription.Text == "" {
+			t.Errorf("rule %d: exp id %q with a description, got %+v", i, expRules[i], rule)
+		}
+	}
+
+	type location struct {
+		uri  string
+		line int
+	}
+	tests := []struct {
+		ruleID string
+		level  string
+		msg    string
+		loc    *location
+	}{
+		{"declaration-removed", "error", "A: declaration removed", &location{"a.go", 1}},
+		{"parameters-changed", "error", "B: parameter types changed", &location{"pkg/b.go", 12}},
+		{"declaration-added", "note", "C: declaration added", &location{"c.go", 3}},
+		{"declaration-removed", "error", "D: declaration removed", nil},
+		{"other", "note", "E: something else", nil},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("exp %d results, got %d", len(tests), len(run.Results))
+	}
+	for i, test := range tests {
+		result := run.Results[i]
+		if result.RuleID != test.ruleID || run.Tool.Driver.Rules[result.RuleIndex].ID != test.ruleID {
+			t.Errorf("result %d: exp rule %q, got %q at index %d", i, test.ruleID, result.RuleID, result.RuleIndex)
+		}
+		if result.Level != test.level {
+			t.Errorf("result %d: exp level %q, got %q", i, test.level, result.Level)
+		}
+		if result.Message.Text != test.msg {
+			t.Errorf("result %d: exp message %q, got %q", i, test.msg, result.Message.Text)
+		}
+		switch {
+		case test.loc == nil && len(result.Locations) != 0:
+			t.Errorf("result %d: exp no location, got %+v", i, result.Locations)
+		case test.loc != nil && len(result.Locations) != 1:
+			t.Errorf("result %d: exp location %+v, got %+v", i, *test.loc, result.Locations)
+		case test.loc != nil:
+			phys := result.Locations[0].PhysicalLocation
+			if phys.ArtifactLocation.URI != test.loc.uri || phys.Region == nil || phys.Region.StartLine != test.loc.line {
+				t.Errorf("result %d: exp location %+v, got %+v", i, *test.loc, phys)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+)
+
+// satisfactionChanges returns a change for each exported type in both before
+// and after which, by value or by pointer, implemented an interface before,
+// either an exported interface of the same package or one of the Checker's
+// notable interfaces, but no longer implements it, such as when a method moves
+// to a pointer receiver, or an embedded interface loses a method. Callers
+// assigning the type, or a pointer to it, to the interface break.
+//
+// Types are compared against the before interface, so an interface gaining a
+// method is reported as a change to the interface instead.
+func (c Checker) satisfactionChanges() []Change {
+	var changes []Change
+	for pkgName, apkg := range c.a {
+		bpkg, ok := c.b[pkgName]
+		if !ok || apkg.dep || apkg.types == nil || bpkg.types == nil {
+			continue
+		}
+		c := c.withPkgOptions(apkg)
+		ifaces := c.satisfiable(bpkg)
+
+		ascope, bscope := apkg.types.Scope(), bpkg.types.Scope()
+		for _, id := range ascope.Names() {
+			aobj, ok := ascope.Lookup(id).(*types.TypeName)
+			if !ok || !aobj.Exported() || aobj.IsAlias() || !c.isIncluded(id) || c.isInternalID(apkg.decls, id) || !isConcrete(aobj.Type()) {
+				continue
+			}
+			bobj, ok := bscope.Lookup(id).(*types.TypeName)
+			if !ok || bobj.IsAlias() || !isConcrete(bobj.Type()) {
+				continue
+			}
+			for _, msg := range unimplementedMsgs(id, bobj.Type(), aobj.Type(), ifaces, c.qualifier) {
+				changes = append(changes, Change{
+					Pkg:      pkgName,
+					ID:       id,
+					Change:   Breaking,
+					Msg:      msg,
+					Pos:      pos(apkg.fset, aobj.Pos()),
+					Category: categoryInterfaceUnimplemented,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// unimplementedMsgs returns a message, in order of the interfaces' names, for
+// each of ifaces the type id implemented by value or by pointer, as btyp, but
+// no longer implements, as atyp. Methods are compared by name and signature,
+// qualified by q, see implementsByName.
+func unimplementedMsgs(id string, btyp, atyp types.Type, ifaces map[string]*types.Interface, q types.Qualifier) []string {
+	var names []string
+	for name := range ifaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var msgs []string
+	bptr, aptr := types.NewPointer(btyp), types.NewPointer(atyp)
+	for _, name := range names {
+		iface := ifaces[name]
+		bval, aval := implementsByName(btyp, iface, q), implementsByName(atyp, iface, q)
+		switch {
+		case implementsByName(bptr, iface, q) && !implementsByName(aptr, iface, q):
+			if bval {
+				msgs = append(msgs, fmt.Sprintf("%s and *%s no longer implement %s", id, id, name))
+			} else {
+				msgs = append(msgs, fmt.Sprintf("*%s no longer implements %s", id, name))
+			}
+		case bval && !aval:
+			msgs = append(msgs, fmt.Sprintf("%s no longer implements %s, only *%s does", id, name, id))
+		}
+	}
+	return msgs
+}
+
+// satisfiable returns the interfaces types may be checked against, keyed by
+// name: the exported, non-generic, interfaces with methods declared by pkg,
+// and the Checker's notable interfaces.
+func (c Checker) satisfiable(pkg pkg) map[string]*types.Interface {
+	ifaces := make(map[string]*types.Interface, len(c.notable))
+	for name, iface := range c.notable {
+		ifaces[name] = iface
+	}
+	for id, iface := range exportedInterfaces(pkg.types) {
+		if c.isIncluded(id) && !c.isInternalID(pkg.decls, id) {
+			ifaces[id] = iface
+		}
+	}
+	return ifaces
+}
+
+// exportedInterfaces returns the exported, non-generic, interfaces with
+// methods declared by pkg, keyed by name.
+func exportedInterfaces(pkg *types.Package) map[string]*types.Interface {
+	ifaces := make(map[string]*types.Interface)
+	scope := pkg.Scope()
+	for _, id := range scope.Names() {
+		obj, ok := scope.Lookup(id).(*types.TypeName)
+		if !ok || !obj.Exported() {
+			continue
+		}
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			continue
+		}
+		iface, ok := obj.Type().Underlying().(*types.Interface)
+		if !ok || !iface.IsMethodSet() || iface.NumMethods() == 0 {
+			// constraints can't be implemented, and all types implement any
+			continue
+		}
+		ifaces[id] = iface
+	}
+	return ifaces
+}
+
+// isConcrete returns true if typ is neither an interface nor generic, so its
+// method set is known.
+func isConcrete(typ types.Type) bool {
+	if types.IsInterface(typ) {
+		return false
+	}
+	named, ok := typ.(*types.Named)
+	return !ok || named.TypeParams().Len() == 0
+}
@@ -0,0 +1,85 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// shadowChanges returns a change for each exported struct type in both before
+// and after which directly declares a field or method that was previously
+// promoted from an embedded type. Selectors of that name now resolve to the
+// new member, which breaks callers relying on the promoted member.
+func (c Checker) shadowChanges() []Change {
+	var changes []Change
+	for pkgName, apkg := range c.a {
+		bpkg, ok := c.b[pkgName]
+		if !ok || apkg.dep || apkg.types == nil || bpkg.types == nil {
+			continue
+		}
+		c := c.withPkgOptions(apkg)
+		ascope, bscope := apkg.types.Scope(), bpkg.types.Scope()
+		for _, id := range ascope.Names() {
+			aobj, ok := ascope.Lookup(id).(*types.TypeName)
+			if !ok || !aobj.Exported() || aobj.IsAlias() {
+				continue
+			}
+			bobj, ok := bscope.Lookup(id).(*types.TypeName)
+			if !ok || bobj.IsAlias() {
+				continue
+			}
+			bstruct, ok := bobj.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for _, member := range directMembers(aobj.Type()) {
+				if !member.Exported() || !c.isIncluded(id+"."+member.Name()) || c.isInternalID(apkg.decls, id+"."+member.Name()) {
+					continue
+				}
+				bmember, index, _ := types.LookupFieldOrMethod(bobj.Type(), true, bpkg.types, member.Name())
+				if bmember == nil || len(index) < 2 {
+					// not previously promoted
+					continue
+				}
+				if _, _, ok := sameMethod(bobj.Type(), aobj.Type(), member.Name(), c.qualifier); ok {
+					// the same method now declared directly, see provenanceChange
+					continue
+				}
+				changes = append(changes, Change{
+					Pkg:    pkgName,
+					ID:     id + "." + member.Name(),
+					Change: Breaking,
+					Msg: fmt.Sprintf("%s %s shadows %s promoted from embedded %s",
+						memberKind(member), member.Name(), memberKind(bmember), bstruct.Field(index[0]).Name()),
+					Pos:      pos(apkg.fset, member.Pos()),
+					Category: categoryMemberShadowed,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// directMembers returns the fields and methods declared directly on typ,
+// excluding those promoted via embedding.
+func directMembers(typ types.Type) []types.Object {
+	var members []types.Object
+	if s, ok := typ.Underlying().(*types.Struct); ok {
+		for i := 0; i < s.NumFields(); i++ {
+			members = append(members, s.Field(i))
+		}
+	}
+	if named, ok := typ.(*types.Named); ok {
+		for i := 0; i < named.NumMethods(); i++ {
+			members = append(members, named.Method(i))
+		}
+	}
+	return members
+}
+
+// memberKind describes whether obj is a field or method.
+func memberKind(obj types.Object) string {
+	if _, ok := obj.(*types.Func); ok {
+		return "method"
+	}
+	return "field"
+}
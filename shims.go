@@ -0,0 +1,190 @@
+package apicompat
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// SuggestShims returns Go source, grouped by package, which if added to the
+// after revision would restore compatibility for some breaking changes:
+//
+//   - a function likely renamed is restored as a deprecated wrapper of its new
+//     name.
+//   - a function removed when a function with the same parameters, plus a
+//     trailing variadic parameter such as functional options, was added, is
+//     restored as a deprecated wrapper of the added function.
+//   - any other removed function or method is restored as a deprecated stub
+//     which panics, so callers continue to compile.
+func SuggestShims(changes []Change) string {
+	shims := make(map[string][]string) // package to shims
+	for _, c := range changes {
+		if c.Change != Breaking {
+			continue
+		}
+		bfunc, ok := c.Before.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		afunc, _ := c.After.(*ast.FuncDecl)
+		switch {
+		case afunc != nil && bfunc.Name.Name != afunc.Name.Name:
+			shims[c.Pkg] = append(shims[c.Pkg], wrapperShim(bfunc, afunc))
+		case afunc == nil:
+			if afunc = variadicReplacement(bfunc, c.Pkg, changes); afunc != nil {
+				shims[c.Pkg] = append(shims[c.Pkg], wrapperShim(bfunc, afunc))
+				continue
+			}
+			shims[c.Pkg] = append(shims[c.Pkg], stubShim(bfunc))
+		}
+	}
+
+	var pkgs []string
+	for pkg := range shims {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	var buf bytes.Buffer
+	for _, pkg := range pkgs {
+		fmt.Fprintf(&buf, "// Shims for package %s\n\n%s\n", pkg, strings.Join(shims[pkg], "\n"))
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		// return the unformatted source, it's only a suggestion
+		return buf.String()
+	}
+	return string(src)
+}
+
+// variadicReplacement returns a function added to pkg with the same
+// parameters and results as before, plus a trailing variadic parameter, or nil
+// if there's none.
+func variadicReplacement(before *ast.FuncDecl, pkg string, changes []Change) *ast.FuncDecl {
+	if before.Recv != nil {
+		return nil
+	}
+	bparams, bresults := fieldTypes(before.Type.Params), fieldTypes(before.Type.Results)
+	for _, c := range changes {
+		afunc, ok := c.After.(*ast.FuncDecl)
+		if c.Pkg != pkg || c.Before != nil || !ok || afunc.Recv != nil {
+			continue
+		}
+		aparams := fieldTypes(afunc.Type.Params)
+		if len(aparams) != len(bparams)+1 || !strings.HasPrefix(aparams[len(aparams)-1], "...") {
+			continue
+		}
+		if strings.Join(aparams[:len(bparams)], ",") == strings.Join(bparams, ",") &&
+			strings.Join(fieldTypes(afunc.Type.Results), ",") == strings.Join(bresults, ",") {
+			return afunc
+		}
+	}
+	return nil
+}
+
+// wrapperShim returns a deprecated function with before's signature, which
+// calls after with before's parameters.
+func wrapperShim(before, after *ast.FuncDecl) string {
+	sig, args := signature(before)
+	call := fmt.Sprintf("%s(%s)", after.Name.Name, strings.Join(args, ", "))
+	if before.Type.Results != nil && len(before.Type.Results.List) > 0 {
+		call = "return " + call
+	}
+	return fmt.Sprintf("// %s calls %s.\n//\n// Deprecated: use %s.\n%s {\n\t%s\n}\n",
+		before.Name.Name, after.Name.Name, after.Name.Name, sig, call)
+}
+
+// stubShim returns a deprecated function or method with before's signature
+// which panics.
+func stubShim(before *ast.FuncDecl) string {
+	sig, _ := signature(before)
+	return fmt.Sprintf("// %s was removed.\n//\n// Deprecated: %s was removed and panics if called.\n%s {\n\tpanic(%q)\n}\n",
+		before.Name.Name, before.Name.Name, sig, before.Name.Name+" was removed")
+}
+
+// signature returns the declaration of fn without a body, with all parameters
+// named, and the arguments to pass those parameters to another function.
+// Unnamed parameters are named by their position, such as p1, or a later
+// position if the name is already used by fn's signature.
+func signature(fn *ast.FuncDecl) (string, []string) {
+	var (
+		params []string
+		args   []string
+		used   = make(map[string]bool) // identifiers in fn's signature
+	)
+	ast.Inspect(fn.Type, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			used[ident.Name] = true
+		}
+		return true
+	})
+	if fn.Type.Params != nil {
+		for _, field := range fn.Type.Params.List {
+			names := field.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent("_")}
+			}
+			for _, ident := range names {
+				name := ident.Name
+				if name == "_" {
+					for i := len(params); name == "_" || used[name]; i++ {
+						name = fmt.Sprintf("p%d", i)
+					}
+					used[name] = true
+				}
+				params = append(params, name+" "+types.ExprString(field.Type))
+				if _, ok := field.Type.(*ast.Ellipsis); ok {
+					name += "..."
+				}
+				args = append(args, name)
+			}
+		}
+	}
+
+	var recv string
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		recv = fmt.Sprintf("(%s) ", types.ExprString(fn.Recv.List[0].Type))
+	}
+
+	var tparams string
+	if fn.Type.TypeParams != nil {
+		var list []string
+		for _, field := range fn.Type.TypeParams.List {
+			var names []string
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+			list = append(list, strings.Join(names, ", ")+" "+types.ExprString(field.Type))
+		}
+		tparams = "[" + strings.Join(list, ", ") + "]"
+	}
+
+	results := strings.Join(fieldTypes(fn.Type.Results), ", ")
+	if len(fieldTypes(fn.Type.Results)) > 1 {
+		results = "(" + results + ")"
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("func %s%s%s(%s) %s", recv, fn.Name.Name, tparams, strings.Join(params, ", "), results)), args
+}
+
+// fieldTypes returns the type of each field in fields, once per name.
+func fieldTypes(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var typs []string
+	for _, field := range fields.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			typs = append(typs, types.ExprString(field.Type))
+		}
+	}
+	return typs
+}
@@ -0,0 +1,58 @@
+package apicompat
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// TestSuggestShims tests the suggested shims restore the removed declarations
+// and compile when added to the after revision.
+func TestSuggestShims(t *testing.T) {
+	const (
+		before = "package lib\n\nimport \"io\"\n\n" +
+			"func Old(a int, _ string, r io.Reader) error { return nil }\n" +
+			"func Connect(addr string) (int, error) { return 0, nil }\n" +
+			"func Gone(xs ...int) {}\n" +
+			"func Collide(p1 int, _ string) {}\n" +
+			"type T struct{}\n" +
+			"func (*T) Method(int) bool { return false }\n"
+		after = "package lib\n\nimport \"io\"\n\n" +
+			"func New(a int, s string, r io.Reader) error { return nil }\n" +
+			"type Option func()\n" +
+			"func Dial(addr string, opts ...Option) (int, error) { return 0, nil }\n" +
+			"type T struct{}\n"
+	)
+	shims := SuggestShims(checkStrVCS(t, before, after, SetHighImpactPatterns(nil)))
+
+	for _, exp := range []string{
+		"// Deprecated: use New.\nfunc Old(a int, p1 string, r io.Reader) error {\n\treturn New(a, p1, r)\n}\n",
+		"// Deprecated: use Dial.\nfunc Connect(addr string) (int, error) {\n\treturn Dial(addr)\n}\n",
+		"func Gone(xs ...int) {\n\tpanic(\"Gone was removed\")\n}\n",
+		"func Collide(p1 int, p2 string) {\n\tpanic(\"Collide was removed\")\n}\n",
+		"func (*T) Method(p0 int) bool {\n\tpanic(\"Method was removed\")\n}\n",
+	} {
+		if !strings.Contains(shims, exp) {
+			t.Errorf("expected shims to contain:\n%s\ngot:\n%s", exp, shims)
+		}
+	}
+
+	// check the after revision compiles with the shims
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, src := range []string{after, "package lib\n\nimport \"io\"\n\nvar _ io.Reader\n\n" + shims} {
+		file, err := parser.ParseFile(fset, "", src, 0)
+		if err != nil {
+			t.Fatalf("could not parse: %v\n%s", err, src)
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("lib", fset, files, nil); err != nil {
+		t.Errorf("shims do not compile: %v\n%s", err, shims)
+	}
+}
@@ -0,0 +1,73 @@
+package apicompat
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// position is a change's position parsed into its components, see parsePos.
+type position struct {
+	rev  string // revision, or empty for the file system
+	file string // file, relative to the working directory
+	line int    // line, or 0 if unknown
+}
+
+// parsePos parses a change's position, such as rev2:pkg/file.go:10, as
+// created by parseFiles, into its revision, file and line. Positions of the
+// file system revision have no revision prefix, such as pkg/file.go:10.
+func parsePos(pos string) position {
+	var p position
+	if i := strings.LastIndex(pos, ":"); i >= 0 {
+		if line, err := strconv.Atoi(pos[i+1:]); err == nil {
+			p.line = line
+			pos = pos[:i]
+		}
+	}
+	if i := strings.Index(pos, ":"); i >= 0 {
+		p.rev, pos = pos[:i], pos[i+1:]
+	}
+	p.file = pos
+	return p
+}
+
+// sourceURL returns the URL of a change's position from a template, such as
+// https://github.com/org/repo/blob/{rev}/{file}#L{line}, or an empty string if
+// the position has no file, or the template requires a revision and the
+// position is of the file system, as the working copy isn't at any revision.
+// root is the directory files are relative to in the URL, such as the
+// repository's root, or empty to use files relative to the working directory.
+func sourceURL(template, pos, root string) string {
+	p := parsePos(pos)
+	if p.file == "" || p.rev == "" && strings.Contains(template, "{rev}") {
+		return ""
+	}
+	file := p.file
+	if root != "" {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(root, filepath.Join(wd, file)); err == nil {
+				file = rel
+			}
+		}
+	}
+	return strings.NewReplacer(
+		"{rev}", p.rev,
+		"{file}", filepath.ToSlash(file),
+		"{line}", strconv.Itoa(p.line),
+	).Replace(template)
+}
+
+// sourceURLOf returns the URL of a change's position from the Checker's
+// template, see SetSourceURLTemplate. Files are relative to the repository's
+// root if the VCS is git.
+func (c *Checker) sourceURLOf(change Change) string {
+	if c.sourceURLTemplate == "" {
+		return ""
+	}
+	var root string
+	if git, ok := c.vcs.(*Git); ok {
+		root = git.base
+	}
+	return sourceURL(c.sourceURLTemplate, change.Pos, root)
+}
@@ -0,0 +1,65 @@
+package apicompat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testSourceURL = "https://github.com/org/repo/blob/{rev}/{file}#L{line}"
+
+func TestSourceURL(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template, pos, root string
+		exp                 string
+	}{
+		// vcs positions
+		{testSourceURL, "rev2:abitest.go:10", "", "https://github.com/org/repo/blob/rev2/abitest.go#L10"},
+		{testSourceURL, "HEAD~1:pkg/file.go:3", "", "https://github.com/org/repo/blob/HEAD~1/pkg/file.go#L3"},
+		{testSourceURL, "rev2:abitest.go:10", filepath.Dir(wd), "https://github.com/org/repo/blob/rev2/" + filepath.Base(wd) + "/abitest.go#L10"},
+		// file system positions, which have no revision
+		{testSourceURL, "abitest.go:10", "", ""},
+		{"vscode://file/{file}:{line}", "abitest.go:10", "", "vscode://file/abitest.go:10"},
+		// positions without a file
+		{testSourceURL, ":0", "", ""},
+		{testSourceURL, "", "", ""},
+	}
+	for _, test := range tests {
+		if url := sourceURL(test.template, test.pos, test.root); url != test.exp {
+			t.Errorf("template: %q pos: %q root: %q\nexp: %q\ngot: %q", test.template, test.pos, test.root, test.exp, url)
+		}
+	}
+}
+
+// TestSetSourceURLTemplate tests changes have URLs of their positions.
+func TestSetSourceURLTemplate(t *testing.T) {
+	const (
+		before = "package lib\nfunc F() {}\nfunc G() {}\n"
+		after  = "package lib\nfunc F(int) {}\n"
+	)
+	exp := map[string]string{
+		"F": "https://github.com/org/repo/blob/rev2/abitest.go#L2",
+		"G": "https://github.com/org/repo/blob/rev1/abitest.go#L3",
+	}
+
+	changes := checkStrVCS(t, before, after, SetSourceURLTemplate(testSourceURL))
+	if len(changes) != len(exp) {
+		t.Fatalf("exp %d changes got %d: %v", len(exp), len(changes), changes)
+	}
+	for _, change := range changes {
+		if change.URL != exp[change.ID] {
+			t.Errorf("id: %v exp URL %q got %q", change.ID, exp[change.ID], change.URL)
+		}
+	}
+
+	for _, change := range checkStrVCS(t, before, after) {
+		if change.URL != "" {
+			t.Errorf("id: %v exp no URL without a template, got %q", change.ID, change.URL)
+		}
+	}
+}
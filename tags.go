@@ -0,0 +1,102 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultBreakingTagKeys are the default struct tag keys whose removal breaks
+// reflection based field mapping, such as by encoders or ORMs.
+var defaultBreakingTagKeys = []string{"json", "xml", "db", "yaml"}
+
+// tagChanges returns a description of each struct tag key removed from a
+// struct's named fields, whether any of those keys are breaking, and the
+// position of the last changed field. Added or changed keys aren't reported,
+// see wireChanges for changes to serialised keys.
+func (c DeclChecker) tagChanges(before, after []*ast.Field) (msgs []string, isBreaking bool, pos token.Pos) {
+	afields := make(map[string]*ast.Field)
+	for _, afield := range after {
+		if len(afield.Names) > 0 {
+			afields[afield.Names[0].Name] = afield
+		}
+	}
+
+	for _, bfield := range before {
+		if len(bfield.Names) == 0 {
+			continue
+		}
+		afield, ok := afields[bfield.Names[0].Name]
+		if !ok {
+			continue
+		}
+		btags, atags := fieldTags(bfield), fieldTags(afield)
+		for _, key := range tagKeys(btags) {
+			if _, ok := atags[key]; ok {
+				continue
+			}
+			msgs = append(msgs, fmt.Sprintf("field %s: %s:%q", bfield.Names[0].Name, key, btags[key]))
+			isBreaking = isBreaking || c.breakingTagKeys[key]
+			pos = afield.Pos()
+		}
+	}
+	return msgs, isBreaking, pos
+}
+
+// fieldTags returns the values of a field's struct tag keyed by each key, or
+// nil if the field has no, or an invalid, tag.
+func fieldTags(field *ast.Field) map[string]string {
+	if field.Tag == nil {
+		return nil
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return nil
+	}
+
+	// parse the conventional format, as used by reflect.StructTag.Lookup
+	tags := make(map[string]string)
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			break
+		}
+		tags[key] = value
+		tag = tag[i+1:]
+	}
+	return tags
+}
+
+// tagKeys returns the keys of tags in a consistent order.
+func tagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
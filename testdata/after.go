@@ -329,3 +329,606 @@ type s struct{ Member uint }
 func F1() s       { return s{} }
 func F2() *s      { return &s{} }
 func (s) F() uint { return 0 }
+
+// FuncRetPtrToValue detects a result changing from a pointer to a value
+func FuncRetPtrToValue() C1 { return 0 }
+
+// FuncRetValueToPtr detects a result changing from a value to a pointer
+func FuncRetValueToPtr() *bytes.Buffer { return nil }
+
+// GenericStack detects a type and its methods becoming generic
+type GenericStack[T any] struct{ Items []T }
+
+func (s *GenericStack[T]) Push(x T) {}
+
+// StructChangeMembers detects changes to multiple members' types
+type StructChangeMembers struct {
+	Reader  *bytes.Reader
+	Name    []byte
+	Buf     bytes.Buffer
+}
+
+// GenerateRemoved detects removal of a type referenced by a go:generate directive
+//go:generate stringer -type=GenerateRemoved
+
+// NotableStringer detects a type newly satisfying fmt.Stringer
+type NotableStringer struct{}
+
+func (NotableStringer) String() string { return "" }
+
+// NotableError detects a type's pointer newly satisfying error
+type NotableError struct{}
+
+func (*NotableError) Error() string { return "" }
+
+// GenericList and GenericMap are used by StructChangeTypeArgs
+type GenericList[T any] []T
+type GenericMap[K comparable, V any] map[K]V
+
+// StructChangeTypeArgs detects changes to the type arguments of generic fields
+type StructChangeTypeArgs struct {
+	List GenericList[string]
+	Map  GenericMap[string, uint]
+	Same GenericMap[string, bytes.Buffer]
+}
+
+// FuncRetChangeType detects a single result changing type
+func FuncRetChangeType() *bytes.Buffer { return nil }
+
+// FuncRetChangeTypes detects multiple results changing type
+func FuncRetChangeTypes() (int, []byte, *bytes.Reader) { return 0, nil, nil }
+
+// Color is used by ConstEnumChangeValue
+type Color int
+
+// ConstEnumChangeValue detects a typed enum constant changing value
+const (
+	ConstEnumUnused Color = iota
+	ConstEnumChangeValue
+	ConstEnumSameValue  Color = 2 - 1
+	ConstEnumFixedValue Color = 10
+)
+
+// FuncRenamedNew detects a function renamed with the same signature
+func FuncRenamedNew(a int, b string) error { return nil }
+
+// IfaceEmbedReplaced detects an embedded interface replaced by its methods
+type IfaceEmbedReplaced interface {
+	Other()
+	Read(p []byte) (n int, err error)
+}
+
+// IfaceEmbedReplacedIncomplete detects an embedded interface partially
+// replaced by its methods
+type IfaceEmbedReplacedIncomplete interface {
+	Read(p []byte) (n int, err error)
+}
+
+// IfaceEmbedNested detects a nested embedded interface replaced by an embed
+// and a method
+type IfaceEmbedNested interface {
+	io.ReadWriter
+	Close() error
+}
+
+// ResultError is used by FuncRetErrorToConcrete and FuncRetConcreteToError
+type ResultError struct{ Code int }
+
+func (*ResultError) Error() string { return "" }
+
+// FuncRetConcreteToError detects a concrete error result becoming error
+func FuncRetConcreteToError() error { return nil }
+
+// FuncRetErrorToConcrete detects an error result becoming a concrete error
+func FuncRetErrorToConcrete() *ResultError { return nil }
+
+// AliasRetargetIdent detects an alias retargeting another identifier
+type AliasRetargetIdent = uint
+
+// AliasRetargetSelector detects an alias retargeting a type in another package
+type AliasRetargetSelector = bytes.Buffer
+
+// AliasRetargetMap detects an alias retargeting an identical map type
+type AliasRetargetMap = map[string]any
+
+// AliasRetargetGeneric detects an alias retargeting another instantiation
+type AliasRetargetGeneric = GenericList[string]
+
+// AliasToDefined detects an alias becoming a defined type
+type AliasToDefined int
+
+// ShadowInner is embedded by ShadowMethod and ShadowField
+type ShadowInner struct{ Name string }
+
+func (ShadowInner) Close() error { return nil }
+
+// ShadowMethod detects a method shadowing a promoted field
+type ShadowMethod struct{ ShadowInner }
+
+func (ShadowMethod) Name() string { return "" }
+
+// ShadowField detects a field shadowing a promoted method
+type ShadowField struct {
+	ShadowInner
+	Close bool
+}
+
+// FuncVariadicMoved detects a variadic parameter becoming a regular parameter
+// followed by a new variadic
+func FuncVariadicMoved(_ int, _ ...int) {}
+
+// FuncVariadicToParam detects a variadic parameter becoming a regular parameter
+func FuncVariadicToParam(_ int) {}
+
+// FuncVariadicRemoved detects a variadic parameter being removed
+func FuncVariadicRemoved(_ int) {}
+
+// FuncVariadicElem detects a variadic parameter changing its element type
+func FuncVariadicElem(_ ...string) {}
+
+// IfaceSuperset detects an interface gaining methods
+type IfaceSuperset interface {
+	Read() error
+	Write() error
+}
+
+// IfaceSubset detects an interface losing methods
+type IfaceSubset interface {
+	Read() error
+}
+
+// IfaceOverlap detects an interface both gaining and losing methods
+type IfaceOverlap interface {
+	Read() error
+	Close() error
+}
+
+// IfaceDisjoint detects an interface replacing all its methods
+type IfaceDisjoint interface {
+	Write() error
+}
+
+// StructEmptyAddField detects an empty struct gaining a field
+type StructEmptyAddField struct{ A int }
+
+// StructEmptyAddPriv detects an empty struct gaining an unexported field
+type StructEmptyAddPriv struct{ a int }
+
+// NamedInt is used by FuncParamNamedToUnderlying and FuncRetNamedToUnderlying
+type NamedInt int64
+
+// FuncParamNamedToUnderlying detects a parameter changing from a named type to
+// its underlying type
+func FuncParamNamedToUnderlying(_ string, _ int64) {}
+
+// FuncRetNamedToUnderlying detects a result changing from a named type to its
+// underlying type
+func FuncRetNamedToUnderlying() int64 { return 0 }
+
+// GenericFuncInferRemoved detects removing the parameter which inferred T
+func GenericFuncInferRemoved[T any]() T { var v T; return v }
+
+// GenericFuncInferAdded detects adding a type parameter which can't be inferred
+func GenericFuncInferAdded[T, U any](_ T) U { var v U; return v }
+
+// GenericFuncInferConstraint checks E remains inferred via S's constraint
+func GenericFuncInferConstraint[S ~[]E, E any](_ S) E { var v E; return v }
+
+// FuncRetRemoveError detects removing an error result
+func FuncRetRemoveError() {}
+
+// FuncRetRemoveTrailingError detects removing a trailing error result
+func FuncRetRemoveTrailingError() int { return 0 }
+
+// FuncRemRetNonError detects removing a result which isn't an error
+func FuncRemRetNonError() {}
+
+// StructTagRemoveDB detects removing a db struct tag
+type StructTagRemoveDB struct {
+	ID   int    `json:"id"`
+	Name string `db:"full_name"`
+}
+
+// StructTagRemoveOther detects removing a struct tag not used for mapping
+type StructTagRemoveOther struct {
+	ID int
+}
+
+// FuncRetInsertBeforeError detects a result inserted before the trailing error
+func FuncRetInsertBeforeError() (int, bool, error) { return 0, false, nil }
+
+// FuncRetInsertBeforeOnlyError detects a result inserted before a sole error
+func FuncRetInsertBeforeOnlyError() (*bytes.Buffer, error) { return nil, nil }
+
+// ConstraintNarrow detects a constraint's type set narrowing
+type ConstraintNarrow interface{ ~int }
+
+// ConstraintWiden detects a constraint's type set widening
+type ConstraintWiden interface{ ~int | ~float64 }
+
+// ConstraintEmbed detects an embedded constraint's type set narrowing
+type ConstraintEmbed interface {
+	ConstraintNarrow
+	String() string
+}
+
+// ConstraintSame checks an unchanged constraint isn't reported
+type ConstraintSame interface{ string | int }
+
+// FuncRetAddError detects an error result appended to a single result
+func FuncRetAddError() (int, error) { return 0, nil }
+
+// FuncRetAddAfterError detects a result appended after a trailing error
+func FuncRetAddAfterError() (int, error, bool) { return 0, nil, false }
+
+// ResultIface is returned by FuncRetIfaceToImpl
+type ResultIface interface{ Get() int }
+
+type resultImpl struct{}
+
+func (*resultImpl) Get() int { return 0 }
+
+// FuncRetIfaceToImpl detects an interface result becoming a type implementing it
+func FuncRetIfaceToImpl() *resultImpl { return nil }
+
+// FuncRetIfaceToNonImpl detects an interface result becoming a type not implementing it
+func FuncRetIfaceToNonImpl() resultImpl { return resultImpl{} }
+
+// ProvenanceInner is embedded by ProvenanceOuter and ProvenanceDirect
+type ProvenanceInner struct{}
+
+func (ProvenanceInner) Moved() int { return 0 }
+
+// ProvenanceOuter detects a promoted method becoming directly declared
+type ProvenanceOuter struct{ ProvenanceInner }
+
+func (ProvenanceOuter) Moved() int { return 0 }
+
+// ProvenanceDirect detects a directly declared method becoming promoted
+type ProvenanceDirect struct{ ProvenanceInner }
+
+// GenericizeSlice detects a slice parameter made generic, with existing calls inferred
+func GenericizeSlice[T ~int | ~string](s []T) T { var t T; return t }
+
+// GenericizeMap detects a map parameter made generic, with existing calls inferred
+func GenericizeMap[K comparable, V any](m map[K]V) {}
+
+// GenericizeCore detects a parameter made generic with a core type constraint
+func GenericizeCore[S ~[]E, E any](s S) {}
+
+// GenericizeUnsatisfied detects a slice parameter made generic, with a constraint existing calls don't satisfy
+func GenericizeUnsatisfied[T ~int | ~float64](s []T) {}
+
+// FieldAliasInt is an alias of int
+type FieldAliasInt = int
+
+// FieldDefinedInt is a defined type with the underlying type int
+type FieldDefinedInt int
+
+// StructFieldAlias detects a field changing to an alias of its type, which is compatible
+type StructFieldAlias struct{ Field FieldAliasInt }
+
+// StructFieldDefined detects a field changing to a defined type with the same underlying type
+type StructFieldDefined struct{ Field FieldDefinedInt }
+
+// IfaceBuffer is used by IfaceParamPtrToValue
+type IfaceBuffer struct{}
+
+// IfaceParamPtrToValue detects an interface method's parameter changing from a pointer to a value
+type IfaceParamPtrToValue interface {
+	Write(buf IfaceBuffer) error
+}
+
+// FuncParamValueToPtr detects a parameter changing from a value to a pointer
+func FuncParamValueToPtr(buf *IfaceBuffer) {}
+
+// FuncParamPtrToValue detects a parameter changing from a pointer to a value
+func FuncParamPtrToValue(name string, buf IfaceBuffer) {}
+
+// GenNode becomes generic
+type GenNode[T any] struct{ v T }
+
+// FuncRetGenericInst detects a result becoming an instantiation of a type made generic
+func FuncRetGenericInst() GenNode[int] { return GenNode[int]{} }
+
+// FuncRetSliceElem detects a returned slice changing element type
+func FuncRetSliceElem() []int { return nil }
+
+// FuncParamSliceElem detects a slice parameter changing element type
+func FuncParamSliceElem(name string, keys []int) {}
+
+// FuncParamArrayLen detects an array parameter changing length, not element type
+func FuncParamArrayLen(keys [3]string) {}
+
+// FuncOptions is an options struct
+type FuncOptions struct {
+	Retries int
+}
+
+// FuncOptionsAdded detects an options struct parameter being added
+func FuncOptionsAdded(name string, opts FuncOptions) {}
+
+// FuncScalarAdded detects a scalar parameter being added
+func FuncScalarAdded(name string, retries int) {}
+
+// ConstStringValue detects a string constant changing value
+const ConstStringValue = "/v2"
+
+// ConstStringTypedValue detects a typed string constant changing value
+const ConstStringTypedValue string = "2.0"
+
+// ConstStringSameValue detects a string constant written differently with the same value
+const ConstStringSameValue = "v1"
+
+// ConsolidatedMax detects type specific functions consolidated into a generic function
+func ConsolidatedMax[T ~int | ~float64](a, b T) T { return a }
+
+// ConstraintTree detects a generic type's constraint being tightened
+type ConstraintTree[T comparable] struct {
+	Root T
+}
+
+// Insert detects a method of a generic type whose constraint was tightened
+func (t *ConstraintTree[T]) Insert(v T) {}
+
+// ConstraintLoose detects a generic type's constraint being loosened
+type ConstraintLoose[T ~int | ~string | ~float64] []T
+
+// Len detects a method of a generic type whose constraint was loosened
+func (l ConstraintLoose[T]) Len() int { return len(l) }
+
+// FuncVariadicWidened detects a variadic parameter's element type widening to an interface
+func FuncVariadicWidened(format string, args ...interface{}) {}
+
+// FuncVariadicNarrowed detects a variadic parameter's element type narrowing from an interface
+func FuncVariadicNarrowed(format string, args ...string) {}
+
+// FuncVariadicIfaceNarrowed detects a variadic parameter's element type narrowing to a wider interface
+func FuncVariadicIfaceNarrowed(args ...io.ReadCloser) {}
+
+// StructFieldSettings is used by StructFieldNamedToAnonymous
+type StructFieldSettings struct {
+	Debug bool
+}
+
+// StructFieldNamedToAnonymous detects a field's named struct type becoming an anonymous struct
+type StructFieldNamedToAnonymous struct {
+	Config struct {
+		Debug bool
+	}
+}
+
+// GenericFuncArity detects a generic function gaining a type parameter
+func GenericFuncArity[T, U any](s []T, f func(T) U) []U { return nil }
+
+// GenericizeHandler is used by the GenericizeIface functions
+type GenericizeHandler interface {
+	Handle() error
+}
+
+// GenericizeIface detects an interface parameter becoming a type parameter constrained by it
+func GenericizeIface[H GenericizeHandler](h H) {}
+
+// GenericizeIfaceTightened detects an interface parameter becoming a type parameter with a tighter constraint
+func GenericizeIfaceTightened[H interface {
+	GenericizeHandler
+	Close() error
+}](h H) {
+}
+
+// ShadowFieldIface detects a field shadowing a method promoted from an embedded interface
+type ShadowFieldIface struct {
+	io.Closer
+	Close bool
+}
+
+// ShadowFieldPtr detects a field shadowing a method promoted from an embedded pointer
+type ShadowFieldPtr struct {
+	*ShadowInner
+	Close bool
+}
+
+// FuncResultWrappedResult is used by FuncResultWrapped
+type FuncResultWrappedResult struct {
+	Count int
+}
+
+// FuncResultWrapped detects a scalar result being wrapped in a struct
+func FuncResultWrapped() (FuncResultWrappedResult, error) { return FuncResultWrappedResult{}, nil }
+
+// IfaceEmbedCompactRenamed tests for ignorance of a method, with differently named parameters, becoming an embedded equivalent interface
+type IfaceEmbedCompactRenamed interface {
+	io.ReadCloser
+}
+
+// IfaceEmbedLocalFlusher is used by IfaceEmbedCompactLocal
+type IfaceEmbedLocalFlusher interface {
+	Flush() error
+}
+
+// IfaceEmbedCompactLocal tests for ignorance of a method becoming an embedded equivalent interface in the same package
+type IfaceEmbedCompactLocal interface {
+	IfaceEmbedLocalFlusher
+}
+
+// SatisfyFlushCloser is used by SatisfyPtr
+type SatisfyFlushCloser interface {
+	Flush() error
+	Close() error
+}
+
+// SatisfyPtr detects *SatisfyPtr no longer implementing an interface, as its embedded interface lost a method
+type SatisfyPtr struct{ io.Reader }
+
+// Flush is used by SatisfyPtr
+func (*SatisfyPtr) Flush() error { return nil }
+
+// SatisfyValue detects SatisfyValue no longer implementing an interface, as its method moved to a pointer receiver
+type SatisfyValue struct{}
+
+// Close is used by SatisfyValue
+func (*SatisfyValue) Close() error { return nil }
+
+// SatisfyCloser is used by SatisfyValue
+type SatisfyCloser interface {
+	Close() error
+}
+
+// TypeSliceToArray detects a defined type's underlying slice becoming an array
+type TypeSliceToArray [64]byte
+
+// TypeArrayToSlice detects a defined type's underlying array becoming a slice
+type TypeArrayToSlice []byte
+
+// TypeArrayLen detects a defined type's underlying array changing length
+type TypeArrayLen [64]byte
+
+// TypeSliceElem detects a defined type's underlying slice changing element type
+type TypeSliceElem []int64
+
+// TypeArrayLenConst tests for ignorance of an array's length changing expression but not value
+type TypeArrayLenConst [4 * 2]byte
+
+// FuncRetAddNewTypeResult is used by FuncRetAddNewType
+type FuncRetAddNewTypeResult struct{}
+
+// FuncRetAddNewType detects a return value of a type added in the same revision
+func FuncRetAddNewType() (*FuncRetAddNewTypeResult, error) { return nil, nil }
+
+// FuncRetAddNewTypeStats is used by FuncRetAddNewTypeAfter
+type FuncRetAddNewTypeStats struct{}
+
+// FuncRetAddNewTypeAfter detects a return value of a type added in the same revision after the existing result
+func FuncRetAddNewTypeAfter() (int, FuncRetAddNewTypeStats) { return 0, FuncRetAddNewTypeStats{} }
+
+// ConstValueUntyped detects an untyped constant changing value
+const ConstValueUntyped = 200
+
+// ConstValueTyped detects a typed constant changing value
+const ConstValueTyped int64 = 1 << 20
+
+// ConstValueFloat detects a float constant changing value
+const ConstValueFloat = 2.5
+
+// ConstValueIotaA detects constants derived from iota changing value when reordered
+const (
+	ConstValueIotaA = iota
+	ConstValueIotaC
+	ConstValueIotaB
+)
+
+// ConstValueSame tests for ignorance of a constant written differently with the same value
+const ConstValueSame = 2 * 2
+
+// GenericFuncTightened detects a generic function's constraint being tightened
+func GenericFuncTightened[T comparable](s []T) []T { return s }
+
+// GenericFuncLoosened detects a generic function's constraint being loosened
+func GenericFuncLoosened[T any](s []T) []T { return s }
+
+// GenericFuncTypeSet detects a generic function's type set being narrowed
+func GenericFuncTypeSet[T ~int](s []T) {}
+
+// GenericFuncUngenericized detects a generic function's type parameter being removed
+func GenericFuncUngenericized(s []int) {}
+
+// GenericFuncSameConstraint tests for ignorance of a constraint written differently
+func GenericFuncSameConstraint[T any](s []T) {}
+
+// GenericizeAny detects an interface{} parameter becoming an unconstrained type parameter
+func GenericizeAny[T any](v T) {}
+
+// GenericizeAnyMixed detects an any parameter becoming an unconstrained type parameter alongside an inferred one
+func GenericizeAnyMixed[E, T any](s []E, v T) {}
+
+// GenericizeAnyShared detects any parameters becoming a type parameter shared between them
+func GenericizeAnyShared[T any](a, b T) bool { return false }
+
+// GenericizeAnyResult detects an any parameter becoming a type parameter also used by the result
+func GenericizeAnyResult[T any](v T) T { return v }
+
+// TypeParamsAdded detects a generic type gaining a type parameter
+type TypeParamsAdded[T, U any] struct {
+	A T
+	B U
+}
+
+// TypeParamsRemoved detects a generic type losing a type parameter
+type TypeParamsRemoved[K comparable] map[K]int
+
+// TypeParamsReordered detects a generic type's type parameters being reordered
+type TypeParamsReordered[V any, K comparable] map[K]V
+
+// TypeParamsLoosened detects a generic type's constraint being loosened
+type TypeParamsLoosened[T any] struct{ A T }
+
+// TypeParamsRenamed tests for ignorance of a generic type's type parameter being renamed
+type TypeParamsRenamed[E any] []E
+
+// StructFieldsReordered detects a struct's fields being reordered
+type StructFieldsReordered struct {
+	io.Reader
+	c    string
+	B, A int
+}
+
+// StructFieldsReorderedAdded detects a struct's fields being reordered and added as only added
+type StructFieldsReorderedAdded struct {
+	B string
+	A int
+	C bool
+}
+
+// FuncParamsCollapsed detects fixed parameters being collapsed into a slice parameter
+func FuncParamsCollapsed(format string, nums []int) {}
+
+// FuncParamsCollapsedUngrouped detects fixed parameters being collapsed into a slice parameter
+func FuncParamsCollapsedUngrouped(nums []int, err error) {}
+
+// FuncParamsCollapsedVariadic detects fixed parameters being collapsed into a variadic parameter (is not a problem)
+func FuncParamsCollapsedVariadic(format string, args ...string) {}
+
+// FuncParamsCollapsedMixed detects fixed parameters of different types being replaced by a slice parameter
+func FuncParamsCollapsedMixed(nums []int) {}
+
+// StructEmbedGeneric detects a field added to a struct embedding generic instantiations
+type StructEmbedGeneric struct {
+	GenericList[int]
+	*GenericMap[string, int]
+	A int
+}
+
+// StructEmbedGenericSame tests for ignorance of an unchanged struct embedding a generic instantiation
+type StructEmbedGenericSame struct {
+	GenericList[string]
+}
+
+// GenericNum detects a constraint's type set narrowing, reported once with its users
+type GenericNum interface{ ~int }
+
+// GenericFuncNamedConstraint tests for ignorance of its constraint GenericNum narrowing, reported by GenericNum
+func GenericFuncNamedConstraint[T GenericNum](v T) {}
+
+// GenericNumVec tests for ignorance of its constraint GenericNum narrowing, reported by GenericNum
+type GenericNumVec[T GenericNum] []T
+
+// FuncParamsCollapsedResultChanged detects results changing when fixed parameters are collapsed into a variadic parameter
+func FuncParamsCollapsedResultChanged(a ...int) string { return "" }
+
+// FuncGenericizedResultChanged detects an empty interface literal parameter becoming a type parameter while its result changes
+func FuncGenericizedResultChanged[T any](v T) string { return "" }
+
+// GenericTildeWidened detects a type set's exact term being widened to its underlying type's term (is not a problem)
+type GenericTildeWidened interface{ ~int }
+
+// GenericTildeUnionWidened detects a type set's exact term being widened within a union (is not a problem)
+type GenericTildeUnionWidened interface{ ~int | float64 }
+
+// GenericTildeNarrowed detects a type set's underlying type term being narrowed to an exact term
+type GenericTildeNarrowed interface{ int }
+
+// GenericFuncTildeWidened detects a function's constraint being widened from an exact term to its underlying type's term (is not a problem)
+func GenericFuncTildeWidened[T ~int](v T) {}
+
+// GenericTypeTildeWidened detects a type's constraint being widened from an exact term to its underlying type's term (is not a problem)
+type GenericTypeTildeWidened[T ~int] []T
@@ -329,3 +329,565 @@ type s struct{ Member int }
 func F1() s      { return s{} }
 func F2() *s     { return &s{} }
 func (s) F() int { return 0 }
+
+// FuncRetPtrToValue detects a result changing from a pointer to a value
+func FuncRetPtrToValue() *C1 { return nil }
+
+// FuncRetValueToPtr detects a result changing from a value to a pointer
+func FuncRetValueToPtr() bytes.Buffer { return bytes.Buffer{} }
+
+// GenericStack detects a type and its methods becoming generic
+type GenericStack struct{ Items []int }
+
+func (s *GenericStack) Push(x int) {}
+
+// StructChangeMembers detects changes to multiple members' types
+type StructChangeMembers struct {
+	Reader  io.Reader
+	Name    string
+	Buf     *bytes.Buffer
+}
+
+// GenerateRemoved detects removal of a type referenced by a go:generate directive
+type GenerateRemoved int
+
+// NotableStringer detects a type newly satisfying fmt.Stringer
+type NotableStringer struct{}
+
+// NotableError detects a type's pointer newly satisfying error
+type NotableError struct{}
+
+// GenericList and GenericMap are used by StructChangeTypeArgs
+type GenericList[T any] []T
+type GenericMap[K comparable, V any] map[K]V
+
+// StructChangeTypeArgs detects changes to the type arguments of generic fields
+type StructChangeTypeArgs struct {
+	List GenericList[int]
+	Map  GenericMap[string, int]
+	Same GenericMap[string, bytes.Buffer]
+}
+
+// FuncRetChangeType detects a single result changing type
+func FuncRetChangeType() error { return nil }
+
+// FuncRetChangeTypes detects multiple results changing type
+func FuncRetChangeTypes() (int, string, error) { return 0, "", nil }
+
+// Color is used by ConstEnumChangeValue
+type Color int
+
+// ConstEnumChangeValue detects a typed enum constant changing value
+const (
+	ConstEnumChangeValue Color = iota
+	ConstEnumSameValue
+	ConstEnumFixedValue Color = 10
+)
+
+// FuncRenamed detects a function renamed with the same signature
+func FuncRenamed(a int, b string) error { return nil }
+
+// IfaceEmbedReplaced detects an embedded interface replaced by its methods
+type IfaceEmbedReplaced interface {
+	Other()
+	io.Reader
+}
+
+// IfaceEmbedReplacedIncomplete detects an embedded interface partially
+// replaced by its methods
+type IfaceEmbedReplacedIncomplete interface {
+	io.ReadCloser
+}
+
+// IfaceEmbedNested detects a nested embedded interface replaced by an embed
+// and a method
+type IfaceEmbedNested interface {
+	io.ReadWriteCloser
+}
+
+// ResultError is used by FuncRetErrorToConcrete and FuncRetConcreteToError
+type ResultError struct{ Code int }
+
+func (*ResultError) Error() string { return "" }
+
+// FuncRetConcreteToError detects a concrete error result becoming error
+func FuncRetConcreteToError() *ResultError { return nil }
+
+// FuncRetErrorToConcrete detects an error result becoming a concrete error
+func FuncRetErrorToConcrete() error { return nil }
+
+// AliasRetargetIdent detects an alias retargeting another identifier
+type AliasRetargetIdent = int
+
+// AliasRetargetSelector detects an alias retargeting a type in another package
+type AliasRetargetSelector = io.Writer
+
+// AliasRetargetMap detects an alias retargeting an identical map type
+type AliasRetargetMap = map[string]interface{}
+
+// AliasRetargetGeneric detects an alias retargeting another instantiation
+type AliasRetargetGeneric = GenericList[int]
+
+// AliasToDefined detects an alias becoming a defined type
+type AliasToDefined = int
+
+// ShadowInner is embedded by ShadowMethod and ShadowField
+type ShadowInner struct{ Name string }
+
+func (ShadowInner) Close() error { return nil }
+
+// ShadowMethod detects a method shadowing a promoted field
+type ShadowMethod struct{ ShadowInner }
+
+// ShadowField detects a field shadowing a promoted method
+type ShadowField struct{ ShadowInner }
+
+// FuncVariadicMoved detects a variadic parameter becoming a regular parameter
+// followed by a new variadic
+func FuncVariadicMoved(_ ...int) {}
+
+// FuncVariadicToParam detects a variadic parameter becoming a regular parameter
+func FuncVariadicToParam(_ ...int) {}
+
+// FuncVariadicRemoved detects a variadic parameter being removed
+func FuncVariadicRemoved(_ int, _ ...int) {}
+
+// FuncVariadicElem detects a variadic parameter changing its element type
+func FuncVariadicElem(_ ...int) {}
+
+// IfaceSuperset detects an interface gaining methods
+type IfaceSuperset interface {
+	Read() error
+}
+
+// IfaceSubset detects an interface losing methods
+type IfaceSubset interface {
+	Read() error
+	Write() error
+}
+
+// IfaceOverlap detects an interface both gaining and losing methods
+type IfaceOverlap interface {
+	Read() error
+	Write() error
+}
+
+// IfaceDisjoint detects an interface replacing all its methods
+type IfaceDisjoint interface {
+	Read() error
+}
+
+// StructEmptyAddField detects an empty struct gaining a field
+type StructEmptyAddField struct{}
+
+// StructEmptyAddPriv detects an empty struct gaining an unexported field
+type StructEmptyAddPriv struct{}
+
+// NamedInt is used by FuncParamNamedToUnderlying and FuncRetNamedToUnderlying
+type NamedInt int64
+
+// FuncParamNamedToUnderlying detects a parameter changing from a named type to
+// its underlying type
+func FuncParamNamedToUnderlying(_ string, _ NamedInt) {}
+
+// FuncRetNamedToUnderlying detects a result changing from a named type to its
+// underlying type
+func FuncRetNamedToUnderlying() NamedInt { return 0 }
+
+// GenericFuncInferRemoved detects removing the parameter which inferred T
+func GenericFuncInferRemoved[T any](_ T) T { var v T; return v }
+
+// GenericFuncInferAdded detects adding a type parameter which can't be inferred
+func GenericFuncInferAdded[T any](_ T) {}
+
+// GenericFuncInferConstraint checks E remains inferred via S's constraint
+func GenericFuncInferConstraint[S ~[]E, E any](_ S) E { var v E; return v }
+
+// FuncRetRemoveError detects removing an error result
+func FuncRetRemoveError() error { return nil }
+
+// FuncRetRemoveTrailingError detects removing a trailing error result
+func FuncRetRemoveTrailingError() (int, error) { return 0, nil }
+
+// FuncRemRetNonError detects removing a result which isn't an error
+func FuncRemRetNonError() int { return 0 }
+
+// StructTagRemoveDB detects removing a db struct tag
+type StructTagRemoveDB struct {
+	ID   int    `db:"id" json:"id"`
+	Name string `db:"name"`
+}
+
+// StructTagRemoveOther detects removing a struct tag not used for mapping
+type StructTagRemoveOther struct {
+	ID int `validate:"required"`
+}
+
+// FuncRetInsertBeforeError detects a result inserted before the trailing error
+func FuncRetInsertBeforeError() (int, error) { return 0, nil }
+
+// FuncRetInsertBeforeOnlyError detects a result inserted before a sole error
+func FuncRetInsertBeforeOnlyError() error { return nil }
+
+// ConstraintNarrow detects a constraint's type set narrowing
+type ConstraintNarrow interface{ ~int | ~float64 }
+
+// ConstraintWiden detects a constraint's type set widening
+type ConstraintWiden interface{ ~int }
+
+// ConstraintEmbed detects an embedded constraint's type set narrowing
+type ConstraintEmbed interface {
+	ConstraintNarrow
+	String() string
+}
+
+// ConstraintSame checks an unchanged constraint isn't reported
+type ConstraintSame interface{ int | string }
+
+// FuncRetAddError detects an error result appended to a single result
+func FuncRetAddError() int { return 0 }
+
+// FuncRetAddAfterError detects a result appended after a trailing error
+func FuncRetAddAfterError() (int, error) { return 0, nil }
+
+// ResultIface is returned by FuncRetIfaceToImpl
+type ResultIface interface{ Get() int }
+
+type resultImpl struct{}
+
+func (*resultImpl) Get() int { return 0 }
+
+// FuncRetIfaceToImpl detects an interface result becoming a type implementing it
+func FuncRetIfaceToImpl() ResultIface { return nil }
+
+// FuncRetIfaceToNonImpl detects an interface result becoming a type not implementing it
+func FuncRetIfaceToNonImpl() ResultIface { return nil }
+
+// ProvenanceInner is embedded by ProvenanceOuter and ProvenanceDirect
+type ProvenanceInner struct{}
+
+func (ProvenanceInner) Moved() int { return 0 }
+
+// ProvenanceOuter detects a promoted method becoming directly declared
+type ProvenanceOuter struct{ ProvenanceInner }
+
+// ProvenanceDirect detects a directly declared method becoming promoted
+type ProvenanceDirect struct{ ProvenanceInner }
+
+func (ProvenanceDirect) Moved() int { return 0 }
+
+// GenericizeSlice detects a slice parameter made generic, with existing calls inferred
+func GenericizeSlice(s []int) int { return 0 }
+
+// GenericizeMap detects a map parameter made generic, with existing calls inferred
+func GenericizeMap(m map[string]int) {}
+
+// GenericizeCore detects a parameter made generic with a core type constraint
+func GenericizeCore(s []int) {}
+
+// GenericizeUnsatisfied detects a slice parameter made generic, with a constraint existing calls don't satisfy
+func GenericizeUnsatisfied(s []bool) {}
+
+// StructFieldAlias detects a field changing to an alias of its type, which is compatible
+type StructFieldAlias struct{ Field int }
+
+// StructFieldDefined detects a field changing to a defined type with the same underlying type
+type StructFieldDefined struct{ Field int }
+
+// IfaceBuffer is used by IfaceParamPtrToValue
+type IfaceBuffer struct{}
+
+// IfaceParamPtrToValue detects an interface method's parameter changing from a pointer to a value
+type IfaceParamPtrToValue interface {
+	Write(buf *IfaceBuffer) error
+}
+
+// FuncParamValueToPtr detects a parameter changing from a value to a pointer
+func FuncParamValueToPtr(buf IfaceBuffer) {}
+
+// FuncParamPtrToValue detects a parameter changing from a pointer to a value
+func FuncParamPtrToValue(name string, buf *IfaceBuffer) {}
+
+// GenNode becomes generic
+type GenNode struct{}
+
+// FuncRetGenericInst detects a result becoming an instantiation of a type made generic
+func FuncRetGenericInst() GenNode { return GenNode{} }
+
+// FuncRetSliceElem detects a returned slice changing element type
+func FuncRetSliceElem() []string { return nil }
+
+// FuncParamSliceElem detects a slice parameter changing element type
+func FuncParamSliceElem(name string, keys []string) {}
+
+// FuncParamArrayLen detects an array parameter changing length, not element type
+func FuncParamArrayLen(keys [2]string) {}
+
+// FuncOptions is an options struct
+type FuncOptions struct {
+	Retries int
+}
+
+// FuncOptionsAdded detects an options struct parameter being added
+func FuncOptionsAdded(name string) {}
+
+// FuncScalarAdded detects a scalar parameter being added
+func FuncScalarAdded(name string) {}
+
+// ConstStringValue detects a string constant changing value
+const ConstStringValue = "/v1"
+
+// ConstStringTypedValue detects a typed string constant changing value
+const ConstStringTypedValue string = "1.0"
+
+// ConstStringSameValue detects a string constant written differently with the same value
+const ConstStringSameValue = "v" + "1"
+
+// ConsolidatedMaxInt detects type specific functions consolidated into a generic function
+func ConsolidatedMaxInt(a, b int) int { return a }
+
+// ConsolidatedMaxFloat64 detects type specific functions consolidated into a generic function
+func ConsolidatedMaxFloat64(a, b float64) float64 { return a }
+
+// ConstraintTree detects a generic type's constraint being tightened
+type ConstraintTree[T any] struct {
+	Root T
+}
+
+// Insert detects a method of a generic type whose constraint was tightened
+func (t *ConstraintTree[T]) Insert(v T) {}
+
+// ConstraintLoose detects a generic type's constraint being loosened
+type ConstraintLoose[T ~int | ~string] []T
+
+// Len detects a method of a generic type whose constraint was loosened
+func (l ConstraintLoose[T]) Len() int { return len(l) }
+
+// FuncVariadicWidened detects a variadic parameter's element type widening to an interface
+func FuncVariadicWidened(format string, args ...string) {}
+
+// FuncVariadicNarrowed detects a variadic parameter's element type narrowing from an interface
+func FuncVariadicNarrowed(format string, args ...interface{}) {}
+
+// FuncVariadicIfaceNarrowed detects a variadic parameter's element type narrowing to a wider interface
+func FuncVariadicIfaceNarrowed(args ...io.Reader) {}
+
+// StructFieldSettings is used by StructFieldNamedToAnonymous
+type StructFieldSettings struct {
+	Debug bool
+}
+
+// StructFieldNamedToAnonymous detects a field's named struct type becoming an anonymous struct
+type StructFieldNamedToAnonymous struct {
+	Config StructFieldSettings
+}
+
+// GenericFuncArity detects a generic function gaining a type parameter
+func GenericFuncArity[T any](s []T, f func(T) T) []T { return nil }
+
+// GenericizeHandler is used by the GenericizeIface functions
+type GenericizeHandler interface {
+	Handle() error
+}
+
+// GenericizeIface detects an interface parameter becoming a type parameter constrained by it
+func GenericizeIface(h GenericizeHandler) {}
+
+// GenericizeIfaceTightened detects an interface parameter becoming a type parameter with a tighter constraint
+func GenericizeIfaceTightened(h GenericizeHandler) {}
+
+// ShadowFieldIface detects a field shadowing a method promoted from an embedded interface
+type ShadowFieldIface struct{ io.Closer }
+
+// ShadowFieldPtr detects a field shadowing a method promoted from an embedded pointer
+type ShadowFieldPtr struct{ *ShadowInner }
+
+// FuncResultWrapped detects a scalar result being wrapped in a struct
+func FuncResultWrapped() (int, error) { return 0, nil }
+
+// IfaceEmbedCompactRenamed tests for ignorance of a method, with differently named parameters, becoming an embedded equivalent interface
+type IfaceEmbedCompactRenamed interface {
+	Read(buf []byte) (int, error)
+	Close() error
+}
+
+// IfaceEmbedLocalFlusher is used by IfaceEmbedCompactLocal
+type IfaceEmbedLocalFlusher interface {
+	Flush() error
+}
+
+// IfaceEmbedCompactLocal tests for ignorance of a method becoming an embedded equivalent interface in the same package
+type IfaceEmbedCompactLocal interface {
+	Flush() error
+}
+
+// SatisfyFlushCloser is used by SatisfyPtr
+type SatisfyFlushCloser interface {
+	Flush() error
+	Close() error
+}
+
+// SatisfyPtr detects *SatisfyPtr no longer implementing an interface, as its embedded interface lost a method
+type SatisfyPtr struct{ io.ReadCloser }
+
+// Flush is used by SatisfyPtr
+func (*SatisfyPtr) Flush() error { return nil }
+
+// SatisfyValue detects SatisfyValue no longer implementing an interface, as its method moved to a pointer receiver
+type SatisfyValue struct{}
+
+// Close is used by SatisfyValue
+func (SatisfyValue) Close() error { return nil }
+
+// SatisfyCloser is used by SatisfyValue
+type SatisfyCloser interface {
+	Close() error
+}
+
+// TypeSliceToArray detects a defined type's underlying slice becoming an array
+type TypeSliceToArray []byte
+
+// TypeArrayToSlice detects a defined type's underlying array becoming a slice
+type TypeArrayToSlice [64]byte
+
+// TypeArrayLen detects a defined type's underlying array changing length
+type TypeArrayLen [32]byte
+
+// TypeSliceElem detects a defined type's underlying slice changing element type
+type TypeSliceElem []int
+
+// TypeArrayLenConst tests for ignorance of an array's length changing expression but not value
+type TypeArrayLenConst [8]byte
+
+// FuncRetAddNewType detects a return value of a type added in the same revision
+func FuncRetAddNewType() error { return nil }
+
+// FuncRetAddNewTypeAfter detects a return value of a type added in the same revision after the existing result
+func FuncRetAddNewTypeAfter() int { return 0 }
+
+// ConstValueUntyped detects an untyped constant changing value
+const ConstValueUntyped = 100
+
+// ConstValueTyped detects a typed constant changing value
+const ConstValueTyped int64 = 1 << 10
+
+// ConstValueFloat detects a float constant changing value
+const ConstValueFloat = 1.5
+
+// ConstValueIotaA detects constants derived from iota changing value when reordered
+const (
+	ConstValueIotaA = iota
+	ConstValueIotaB
+	ConstValueIotaC
+)
+
+// ConstValueSame tests for ignorance of a constant written differently with the same value
+const ConstValueSame = 4
+
+// GenericFuncTightened detects a generic function's constraint being tightened
+func GenericFuncTightened[T any](s []T) []T { return s }
+
+// GenericFuncLoosened detects a generic function's constraint being loosened
+func GenericFuncLoosened[T comparable](s []T) []T { return s }
+
+// GenericFuncTypeSet detects a generic function's type set being narrowed
+func GenericFuncTypeSet[T ~int | ~string](s []T) {}
+
+// GenericFuncUngenericized detects a generic function's type parameter being removed
+func GenericFuncUngenericized[T any](s []T) {}
+
+// GenericFuncSameConstraint tests for ignorance of a constraint written differently
+func GenericFuncSameConstraint[T interface{}](s []T) {}
+
+// GenericizeAny detects an interface{} parameter becoming an unconstrained type parameter
+func GenericizeAny(v interface{}) {}
+
+// GenericizeAnyMixed detects an any parameter becoming an unconstrained type parameter alongside an inferred one
+func GenericizeAnyMixed(s []int, v any) {}
+
+// GenericizeAnyShared detects any parameters becoming a type parameter shared between them
+func GenericizeAnyShared(a, b any) bool { return false }
+
+// GenericizeAnyResult detects an any parameter becoming a type parameter also used by the result
+func GenericizeAnyResult(v any) any { return v }
+
+// TypeParamsAdded detects a generic type gaining a type parameter
+type TypeParamsAdded[T any] struct{ A T }
+
+// TypeParamsRemoved detects a generic type losing a type parameter
+type TypeParamsRemoved[K comparable, V any] map[K]V
+
+// TypeParamsReordered detects a generic type's type parameters being reordered
+type TypeParamsReordered[K comparable, V any] map[K]V
+
+// TypeParamsLoosened detects a generic type's constraint being loosened
+type TypeParamsLoosened[T comparable] struct{ A T }
+
+// TypeParamsRenamed tests for ignorance of a generic type's type parameter being renamed
+type TypeParamsRenamed[T any] []T
+
+// StructFieldsReordered detects a struct's fields being reordered
+type StructFieldsReordered struct {
+	A, B int
+	io.Reader
+	c string
+}
+
+// StructFieldsReorderedAdded detects a struct's fields being reordered and added as only added
+type StructFieldsReorderedAdded struct {
+	A int
+	B string
+}
+
+// FuncParamsCollapsed detects fixed parameters being collapsed into a slice parameter
+func FuncParamsCollapsed(format string, a, b int) {}
+
+// FuncParamsCollapsedUngrouped detects fixed parameters being collapsed into a slice parameter
+func FuncParamsCollapsedUngrouped(a int, b int, c int, err error) {}
+
+// FuncParamsCollapsedVariadic detects fixed parameters being collapsed into a variadic parameter (is not a problem)
+func FuncParamsCollapsedVariadic(format string, a string, b string) {}
+
+// FuncParamsCollapsedMixed detects fixed parameters of different types being replaced by a slice parameter
+func FuncParamsCollapsedMixed(a int, b int64) {}
+
+// StructEmbedGeneric detects a field added to a struct embedding generic instantiations
+type StructEmbedGeneric struct {
+	GenericList[int]
+	*GenericMap[string, int]
+}
+
+// StructEmbedGenericSame tests for ignorance of an unchanged struct embedding a generic instantiation
+type StructEmbedGenericSame struct {
+	GenericList[string]
+}
+
+// GenericNum detects a constraint's type set narrowing, reported once with its users
+type GenericNum interface{ ~int | ~float64 }
+
+// GenericFuncNamedConstraint tests for ignorance of its constraint GenericNum narrowing, reported by GenericNum
+func GenericFuncNamedConstraint[T GenericNum](v T) {}
+
+// GenericNumVec tests for ignorance of its constraint GenericNum narrowing, reported by GenericNum
+type GenericNumVec[T GenericNum] []T
+
+// FuncParamsCollapsedResultChanged detects results changing when fixed parameters are collapsed into a variadic parameter
+func FuncParamsCollapsedResultChanged(a, b int) int { return 0 }
+
+// FuncGenericizedResultChanged detects an empty interface literal parameter becoming a type parameter while its result changes
+func FuncGenericizedResultChanged(v interface{}) int { return 0 }
+
+// GenericTildeWidened detects a type set's exact term being widened to its underlying type's term (is not a problem)
+type GenericTildeWidened interface{ int }
+
+// GenericTildeUnionWidened detects a type set's exact term being widened within a union (is not a problem)
+type GenericTildeUnionWidened interface{ int | float64 }
+
+// GenericTildeNarrowed detects a type set's underlying type term being narrowed to an exact term
+type GenericTildeNarrowed interface{ ~int }
+
+// GenericFuncTildeWidened detects a function's constraint being widened from an exact term to its underlying type's term (is not a problem)
+func GenericFuncTildeWidened[T int](v T) {}
+
+// GenericTypeTildeWidened detects a type's constraint being widened from an exact term to its underlying type's term (is not a problem)
+type GenericTypeTildeWidened[T int] []T
@@ -0,0 +1,7 @@
+package corpus
+
+var Direction <-chan int
+
+var Type chan uint
+
+var Unchanged <-chan int
@@ -0,0 +1,7 @@
+package corpus
+
+var Direction chan int
+
+var Type chan int
+
+var Unchanged <-chan int
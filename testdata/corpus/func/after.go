@@ -0,0 +1,13 @@
+package corpus
+
+func Param(uint) {}
+
+func ParamAdded(int, int) {}
+
+func Result() uint { return 0 }
+
+func ResultAdded() int { return 0 }
+
+func ResultError() (int, error) { return 0, nil }
+
+func Added() {}
@@ -0,0 +1,13 @@
+package corpus
+
+func Param(int) {}
+
+func ParamAdded(int) {}
+
+func Result() int { return 0 }
+
+func ResultAdded() {}
+
+func ResultError() int { return 0 }
+
+func Removed() {}
@@ -0,0 +1,14 @@
+package corpus
+
+import "io"
+
+type Added interface {
+	A()
+	B()
+}
+
+type Removed interface{ A() }
+
+type Changed interface{ A(uint) }
+
+type Embedded interface{ io.Reader }
@@ -0,0 +1,12 @@
+package corpus
+
+type Added interface{ A() }
+
+type Removed interface {
+	A()
+	B()
+}
+
+type Changed interface{ A(int) }
+
+type Embedded interface{ Read(p []byte) (n int, err error) }
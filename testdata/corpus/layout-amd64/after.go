@@ -0,0 +1,11 @@
+package corpus
+
+type Reordered struct {
+	B int
+	A int32
+}
+
+type Grown struct {
+	A int32
+	b int64
+}
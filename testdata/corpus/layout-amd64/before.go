@@ -0,0 +1,8 @@
+package corpus
+
+type Reordered struct {
+	A int32
+	B int
+}
+
+type Grown struct{ A int32 }
@@ -0,0 +1,30 @@
+package corpus
+
+type Added struct{ A, B int }
+
+type Removed struct{ A int }
+
+type Changed struct{ A uint }
+
+type Unexported struct {
+	A int
+	b int
+}
+
+type ZeroValue struct {
+	A int
+	B *int
+}
+
+type Wire struct {
+	A string `json:"a"`
+}
+
+type WireRenamed struct {
+	A int `json:"b"`
+}
+
+type Reordered struct {
+	B int
+	A int32
+}
@@ -0,0 +1,24 @@
+package corpus
+
+type Added struct{ A int }
+
+type Removed struct{ A, B int }
+
+type Changed struct{ A int }
+
+type Unexported struct{ A int }
+
+type ZeroValue struct{ A int }
+
+type Wire struct {
+	A int `json:"a"`
+}
+
+type WireRenamed struct {
+	A int `json:"a"`
+}
+
+type Reordered struct {
+	A int32
+	B int
+}
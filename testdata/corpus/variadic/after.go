@@ -0,0 +1,9 @@
+package corpus
+
+func Added(int, ...int) {}
+
+func Moved(int, ...int) {}
+
+func Removed(int) {}
+
+func Elem(...uint) {}
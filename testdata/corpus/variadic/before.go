@@ -0,0 +1,9 @@
+package corpus
+
+func Added(int) {}
+
+func Moved(...int) {}
+
+func Removed(int, ...int) {}
+
+func Elem(...int) {}
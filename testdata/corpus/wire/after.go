@@ -0,0 +1,31 @@
+package corpus
+
+type Retyped struct {
+	A string `json:"a"`
+}
+
+type Renamed struct {
+	A int `json:"b"`
+}
+
+type Tagged struct {
+	A int `json:"a"`
+}
+
+type OmitemptyRemoved struct {
+	A int `json:"A"`
+}
+
+type OmitemptyAdded struct {
+	A int `xml:"a,omitempty"`
+}
+
+type Keys struct {
+	A uint `json:"a" xml:"a"`
+}
+
+type Ignored struct {
+	A uint `json:"-"`
+}
+
+type Untagged struct{ A uint }
@@ -0,0 +1,29 @@
+package corpus
+
+type Retyped struct {
+	A int `json:"a"`
+}
+
+type Renamed struct {
+	A int `json:"a"`
+}
+
+type Tagged struct{ A int }
+
+type OmitemptyRemoved struct {
+	A int `json:",omitempty"`
+}
+
+type OmitemptyAdded struct {
+	A int `xml:"a"`
+}
+
+type Keys struct {
+	A int `json:"a" xml:"a"`
+}
+
+type Ignored struct {
+	A int `json:"-"`
+}
+
+type Untagged struct{ A int }
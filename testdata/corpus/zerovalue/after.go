@@ -0,0 +1,20 @@
+package corpus
+
+type Map struct {
+	A int
+	m map[int]int
+}
+
+type Pointer struct {
+	A int
+	B *int
+}
+
+type Chan struct{ C chan int }
+
+type Value struct{ A, B int }
+
+type Slice struct {
+	m map[int]int
+	B []int
+}
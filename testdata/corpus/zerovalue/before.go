@@ -0,0 +1,11 @@
+package corpus
+
+type Map struct{ A int }
+
+type Pointer struct{ A int }
+
+type Chan struct{}
+
+type Value struct{ A int }
+
+type Slice struct{ m map[int]int }
@@ -0,0 +1,153 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// typeSetChange returns a change if a constraint interface's type set, such as
+// ~int | ~float64, was narrowed, which breaks callers instantiating with the
+// removed types, or widened, which doesn't. Type sets formed by intersecting
+// multiple elements aren't compared.
+func (c DeclChecker) typeSetChange(before, after *ast.InterfaceType) (DeclChange, bool) {
+	bterms, bok := typeTerms(c.binfo.TypeOf(before), c.bpkg)
+	aterms, aok := typeTerms(c.ainfo.TypeOf(after), c.apkg)
+	if !bok || !aok {
+		return none(), false
+	}
+
+	removed, added := typeTermsDiff(bterms, aterms)
+	switch {
+	case len(bterms) == 0 && len(aterms) > 0:
+		return breaking("type set narrowed: now restricted to "+strings.Join(added, " | "), after.Pos()).withCategory(categoryTypeSetChanged), true
+	case len(bterms) > 0 && len(aterms) == 0:
+		return nonBreaking("type set widened: no longer restricted to "+strings.Join(removed, " | "), after.Pos()).withCategory(categoryTypeSetChanged), true
+	case len(removed) > 0 && len(added) > 0:
+		msg := fmt.Sprintf("type set narrowed: removed %s, added %s", strings.Join(removed, " | "), strings.Join(added, " | "))
+		return breaking(msg, after.Pos()).withCategory(categoryTypeSetChanged), true
+	case len(removed) > 0:
+		return breaking("type set narrowed: removed "+strings.Join(removed, " | "), after.Pos()).withCategory(categoryTypeSetChanged), true
+	case len(added) > 0:
+		return nonBreaking("type set widened: added "+strings.Join(added, " | "), after.Pos()).withCategory(categoryTypeSetChanged), true
+	}
+	return none(), false
+}
+
+// typeTerms returns the terms of an interface's type set keyed by their string,
+// such as ~int, which is empty if the interface isn't restricted to specific
+// types. Returns false if typ isn't an interface, or its type set is formed by
+// intersecting multiple elements.
+func typeTerms(typ types.Type, pkg *types.Package) (map[string]*types.Term, bool) {
+	iface, ok := typ.(*types.Interface)
+	if !ok {
+		return nil, false
+	}
+	var (
+		terms    = make(map[string]*types.Term)
+		elements int
+	)
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		var eterms map[string]*types.Term
+		switch etype := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			eterms = make(map[string]*types.Term)
+			for j := 0; j < etype.Len(); j++ {
+				term := etype.Term(j)
+				eterms[termString(term.Tilde(), term.Type(), pkg)] = term
+			}
+		default:
+			if _, ok := etype.Underlying().(*types.Interface); ok {
+				if eterms, ok = typeTerms(etype.Underlying(), pkg); !ok {
+					return nil, false
+				}
+				break
+			}
+			eterms = map[string]*types.Term{termString(false, etype, pkg): types.NewTerm(false, etype)}
+		}
+		if len(eterms) == 0 {
+			continue
+		}
+		if elements++; elements > 1 {
+			return nil, false
+		}
+		terms = eterms
+	}
+	return terms, true
+}
+
+// typeTermsDiff returns the strings of the terms of before not included by any
+// term of after, and of after not included by any term of before, each sorted.
+// A term is included by an equal term or a wider term, such as int by ~int, so
+// int becoming ~int is only an addition.
+func typeTermsDiff(before, after map[string]*types.Term) (removed, added []string) {
+	return excludedTerms(before, after), excludedTerms(after, before)
+}
+
+// excludedTerms returns the strings of terms not included by any of others,
+// sorted.
+func excludedTerms(terms, others map[string]*types.Term) []string {
+	var excluded []string
+	for str, term := range terms {
+		included := false
+		for _, other := range others {
+			if termIncludes(other, term) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			excluded = append(excluded, str)
+		}
+	}
+	sort.Strings(excluded)
+	return excluded
+}
+
+// termIncludes returns true if each type in the term inner is in the term
+// outer, such as ~int including int, ~int and type MyInt int. Types are
+// compared by their strings qualified by package path, as the terms are from
+// different revisions.
+func termIncludes(outer, inner *types.Term) bool {
+	otype := types.TypeString(outer.Type(), nil)
+	switch {
+	case outer.Tilde():
+		return otype == types.TypeString(inner.Type().Underlying(), nil)
+	case inner.Tilde():
+		return false
+	}
+	return otype == types.TypeString(inner.Type(), nil)
+}
+
+// termString returns a type set term, such as ~int.
+func termString(tilde bool, typ types.Type, pkg *types.Package) string {
+	str := types.TypeString(typ, types.RelativeTo(pkg))
+	if tilde {
+		return "~" + str
+	}
+	return str
+}
+
+// isTypeSetElement returns true if an interface's embedded element is a type
+// set element, such as ~int | ~float64, instead of an embedded interface.
+func isTypeSetElement(uses map[*ast.Ident]types.Object, expr ast.Expr) bool {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+		return true
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return false
+	}
+	obj, ok := uses[ident]
+	if !ok {
+		return false
+	}
+	_, ok = obj.Type().Underlying().(*types.Interface)
+	return !ok
+}
@@ -28,8 +28,19 @@ type VCS interface {
 	DefaultRevision() (before string, after string)
 }
 
-// guarantee at compile time that *Git implements VCS
-var _ VCS = (*Git)(nil)
+// RevisionChecker is optionally implemented by a VCS to report whether a
+// revision exists, such as HEAD~1 in a repository with a single commit, see
+// SetBaselineOnMissing.
+type RevisionChecker interface {
+	// HasRevision returns true if the revision exists
+	HasRevision(revision string) bool
+}
+
+// guarantee at compile time that *Git implements VCS and RevisionChecker
+var (
+	_ VCS             = (*Git)(nil)
+	_ RevisionChecker = (*Git)(nil)
+)
 
 // Git implements vcs and uses exec.Command to access repository
 type Git struct {
@@ -128,6 +139,15 @@ func (g *Git) DefaultRevision() (string, string) {
 	return "HEAD~1", "HEAD"
 }
 
+// HasRevision implements RevisionChecker
+func (g *Git) HasRevision(revision string) bool {
+	if revision == revisionFS {
+		return true
+	}
+	args := []string{"--git-dir", g.dir, "rev-parse", "--verify", "--quiet", revision + "^{commit}"}
+	return exec.Command("git", args...).Run() == nil
+}
+
 // fileInfo is a struct to simulate the real filesystem file info
 type fileInfo struct {
 	name string // base name of file
@@ -152,8 +172,11 @@ func (fi fileInfo) IsDir() bool { return fi.dir }
 // Sys is one of the method needed to implement os.FileInfo
 func (fi fileInfo) Sys() interface{} { panic("not implemented") }
 
-// guarantee at compile time that StrVCS implements VCS
-var _ VCS = (*StrVCS)(nil)
+// guarantee at compile time that StrVCS implements VCS and RevisionChecker
+var (
+	_ VCS             = (*StrVCS)(nil)
+	_ RevisionChecker = (*StrVCS)(nil)
+)
 
 // StrVCS provides a in memory vcs used for testing, but does not support
 // subdirectories.
@@ -187,6 +210,12 @@ func (v StrVCS) OpenFile(revision, path string) (io.ReadCloser, error) {
 	return ioutil.NopCloser(bytes.NewReader(v.files[revision][filepath.Base(path)])), nil
 }
 
+// HasRevision implements RevisionChecker
+func (v StrVCS) HasRevision(revision string) bool {
+	_, ok := v.files[revision]
+	return ok
+}
+
 // DefaultRevision implements VCS.DefaultRevision
 func (StrVCS) DefaultRevision() (string, string) {
 	return "rev1", "rev2"
@@ -0,0 +1,184 @@
+package apicompat
+
+import (
+	"bufio"
+	"fmt"
+	"go/importer"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Revisions used by CheckVendorDrift to describe each copy of a package.
+const (
+	revisionModule = "module"
+	revisionVendor = "vendor"
+)
+
+// CheckVendorDrift compares the API of a vendored package, in the vendor
+// directory of the module in the current working directory, against the
+// package's source at the version pinned in vendor/modules.txt, as found in
+// the module cache or the module's replacement directory. Changes describe how
+// the vendored copy differs from the pinned version, such as local patches.
+func (c *Checker) CheckVendorDrift(importPath string) ([]Change, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	modDir, err := vendoredModuleDir(wd, importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// use a copy of the checker, to read from the file system instead of its VCS
+	vendorDir := filepath.Join(wd, "vendor", filepath.FromSlash(importPath))
+	d := *c
+	d.vcs = dirVCS{from: vendorDir, to: modDir}
+	d.logf("import path: %q module: %q vendor: %q\n", importPath, modDir, vendorDir)
+
+	// both revisions are parsed from the vendor directory's path, so the
+	// packages are keyed by the same import path
+	rel := "." + string(os.PathSeparator) + filepath.Join("vendor", filepath.FromSlash(importPath))
+	d.b, d.a = make(map[string]pkg), make(map[string]pkg)
+	for _, rev := range []string{revisionModule, revisionVendor} {
+		p, err := d.parseDir(rev, rel, importer.Default())
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s copy of %q: %v", rev, importPath, err)
+		}
+		if rev == revisionModule {
+			d.b[importPath] = p
+		} else {
+			d.a[importPath] = p
+		}
+	}
+
+	changes, err := d.compare()
+	if err != nil {
+		return nil, err
+	}
+	d.logf("Changes detected: %v\n", len(changes))
+	return changes, nil
+}
+
+// vendoredModuleDir returns the directory containing the source of the
+// vendored package importPath, at the version of its module listed in the
+// vendor/modules.txt file within the module at dir.
+func vendoredModuleDir(dir, importPath string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, "vendor", "modules.txt"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// find the module with the longest path containing importPath, lines are
+	// such as "# example.com/mod v1.0.0" or "# example.com/mod v1.0.0 => ../mod"
+	var modPath, version, replace string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != "#" {
+			continue
+		}
+		if importPath != fields[1] && !strings.HasPrefix(importPath, fields[1]+"/") {
+			continue
+		}
+		if len(fields[1]) <= len(modPath) {
+			continue
+		}
+		modPath, version, replace = fields[1], fields[2], ""
+		if len(fields) >= 5 && fields[3] == "=>" {
+			replace = strings.Join(fields[4:], " ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if modPath == "" {
+		return "", fmt.Errorf("could not find module of %q in vendor/modules.txt", importPath)
+	}
+	sub := filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(importPath, modPath), "/"))
+
+	if replace != "" {
+		rfields := strings.Fields(replace)
+		if len(rfields) == 1 {
+			// replaced by a directory, relative to the module
+			rdir := rfields[0]
+			if !filepath.IsAbs(rdir) {
+				rdir = filepath.Join(dir, rdir)
+			}
+			return filepath.Join(rdir, sub), nil
+		}
+		modPath, version = rfields[0], rfields[1]
+	}
+
+	cache, err := moduleCache()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, filepath.FromSlash(escapeModulePath(modPath))+"@"+version, sub), nil
+}
+
+// moduleCache returns the directory of the module cache.
+func moduleCache() (string, error) {
+	if cache := os.Getenv("GOMODCACHE"); cache != "" {
+		return cache, nil
+	}
+	cmd := exec.Command("go", "env", "GOMODCACHE")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running %v: %v", cmd.Args, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// escapeModulePath returns the path of a module as stored in the module
+// cache, where upper case letters are replaced by an exclamation mark followed
+// by the lower case letter.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// guarantee at compile time that dirVCS implements VCS
+var _ VCS = dirVCS{}
+
+// dirVCS implements VCS using the file system, where paths within the from
+// directory are read from the to directory for revisionModule.
+type dirVCS struct {
+	from, to string
+}
+
+// path returns the path to read at revision.
+func (v dirVCS) path(revision, path string) string {
+	if revision == revisionModule && strings.HasPrefix(path, v.from) {
+		return v.to + strings.TrimPrefix(path, v.from)
+	}
+	return path
+}
+
+// ReadDir implements VCS.ReadDir
+func (v dirVCS) ReadDir(revision, path string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(v.path(revision, path))
+}
+
+// OpenFile implements VCS.OpenFile
+func (v dirVCS) OpenFile(revision, path string) (io.ReadCloser, error) {
+	return os.Open(v.path(revision, path))
+}
+
+// DefaultRevision implements VCS.DefaultRevision
+func (dirVCS) DefaultRevision() (string, string) {
+	return revisionModule, revisionVendor
+}
@@ -0,0 +1,79 @@
+package apicompat
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckVendorDrift tests a patched vendored package is compared against
+// its pinned version in the module cache.
+func TestCheckVendorDrift(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apicompat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"mod/go.mod":                               "module example.com/mod\n\nrequire example.com/Dep v1.0.0\n",
+		"mod/vendor/modules.txt":                   "# example.com/Dep v1.0.0\n## explicit\nexample.com/Dep/sub\n",
+		"mod/vendor/example.com/Dep/sub/sub.go":    "package sub\n\nfunc F(a int, b int) {}\n\nfunc Patched() {}\n",
+		"cache/example.com/!dep@v1.0.0/sub/sub.go": "package sub\n\nfunc F(a int) {}\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldCache, oldWd := os.Getenv("GOMODCACHE"), mustGetwd(t)
+	defer func() {
+		os.Setenv("GOMODCACHE", oldCache)
+		os.Chdir(oldWd)
+	}()
+	if err := os.Setenv("GOMODCACHE", filepath.Join(dir, "cache")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(dir, "mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := New().CheckVendorDrift("example.com/Dep/sub")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := []struct {
+		id, change, msg string
+	}{
+		{"F", Breaking, "parameter types changed"},
+		{"Patched", NonBreaking, "declaration added"},
+	}
+	if len(changes) != len(exp) {
+		t.Fatalf("exp %d changes got %d: %v", len(exp), len(changes), changes)
+	}
+	for i, e := range exp {
+		if changes[i].ID != e.id || changes[i].Change != e.change || changes[i].Msg != e.msg {
+			t.Errorf("exp %v got: %#v", e, changes[i])
+		}
+	}
+
+	if _, err := New().CheckVendorDrift("example.com/other"); err == nil {
+		t.Errorf("expected error for package not in vendor/modules.txt")
+	}
+}
+
+// mustGetwd returns the current working directory.
+func mustGetwd(t *testing.T) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wd
+}
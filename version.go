@@ -0,0 +1,76 @@
+package apicompat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// versionPattern matches a version such as 1.2.3, v1.2 or 1.2.3-rc.1.
+var versionPattern = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-+][0-9A-Za-z.+-]+)?$`)
+
+// errNoVersion is returned when a version file doesn't contain a version.
+var errNoVersion = errors.New("no version found")
+
+// errNoTag is returned when there's no tag for a version file's version.
+var errNoTag = errors.New("no tag found")
+
+// beforeFromVersionFile returns the before revision, a tag, resolved from the
+// version in the Checker's version file at HEAD.
+func (c Checker) beforeFromVersionFile() (string, error) {
+	git, ok := c.vcs.(*Git)
+	if !ok {
+		return "", fmt.Errorf("version file %s requires the git VCS", c.versionFile)
+	}
+	return git.VersionTag("HEAD", c.versionFile)
+}
+
+// VersionTag reads the version, such as 1.2.0, from the first non-empty line
+// of the file at path, relative to the repository's root, at revision and
+// returns the matching tag, either the version as is or prefixed with v.
+func (g *Git) VersionTag(revision, path string) (string, error) {
+	file, err := g.OpenFile(revision, filepath.Join(g.base, path))
+	if err != nil {
+		return "", fmt.Errorf("cannot read version file %s at %s: %v", path, revision, err)
+	}
+	defer file.Close()
+
+	version, err := readVersion(bufio.NewScanner(file))
+	if err != nil {
+		return "", fmt.Errorf("cannot parse version file %s at %s: %v", path, revision, err)
+	}
+
+	tags := []string{version, "v" + version}
+	if strings.HasPrefix(version, "v") {
+		tags = []string{version, strings.TrimPrefix(version, "v")}
+	}
+	for _, tag := range tags {
+		args := []string{"--git-dir", g.dir, "rev-parse", "--verify", "--quiet", "refs/tags/" + tag}
+		if err := exec.Command("git", args...).Run(); err == nil {
+			return tag, nil
+		}
+	}
+	return "", fmt.Errorf("%w for version %s from version file %s, tried: %s", errNoTag, version, path, strings.Join(tags, ", "))
+}
+
+// readVersion returns the version from the first non-empty line scanned.
+func readVersion(scanner *bufio.Scanner) (string, error) {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !versionPattern.MatchString(line) {
+			return "", fmt.Errorf("invalid version %q", line)
+		}
+		return line, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errNoVersion
+}
@@ -0,0 +1,86 @@
+package apicompat
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestBeforeFromVersionFile tests the before revision is resolved to the tag
+// of the version in a version file at HEAD.
+func TestBeforeFromVersionFile(t *testing.T) {
+	gopath := makeGOPATH(t, "example.com/mod",
+		map[string]string{
+			"VERSION":    "1.0.0\n",
+			"lib/lib.go": "package lib\n\ntype T int\n",
+		},
+		map[string]string{
+			"lib/lib.go": "package lib\n\ntype T uint\n",
+		},
+		map[string]string{
+			"lib/lib.go": "package lib\n\ntype T uint\n\nvar V int\n",
+			"BADVERSION": "not a version\n",
+			"NOTAG":      "\n2.0.0\n",
+		},
+	)
+	defer os.RemoveAll(gopath)
+	defer chdirGOPATH(t, gopath, "example.com/mod/lib")()
+
+	cmd := exec.Command("git", "tag", "v1.0.0", "HEAD~2")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("error running git %v: %v output: %s", cmd.Args, err, out)
+	}
+
+	git, err := NewGit(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := New(SetVCS(git), SetBeforeFromVersionFile("VERSION")).Check(".", false, "", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, change := range changes {
+		ids = append(ids, change.ID)
+	}
+	if strings.Join(ids, ",") != "T,V" {
+		t.Errorf("exp changes to T and V since v1.0.0, got: %v", changes)
+	}
+
+	// an explicit before revision isn't replaced
+	changes, err = New(SetVCS(git), SetBeforeFromVersionFile("VERSION")).Check(".", false, "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].ID != "V" {
+		t.Errorf("exp change to V since HEAD~1, got: %v", changes)
+	}
+
+	tests := []struct {
+		file string
+		exp  string // expected error substring
+	}{
+		{"MISSING", "cannot read version file MISSING at HEAD"},
+		{"BADVERSION", `cannot parse version file BADVERSION at HEAD: invalid version "not a version"`},
+		{"NOTAG", "no tag found for version 2.0.0 from version file NOTAG, tried: 2.0.0, v2.0.0"},
+	}
+	for _, test := range tests {
+		_, err := New(SetVCS(git), SetBeforeFromVersionFile(test.file)).Check(".", false, "", "HEAD")
+		if err == nil || !strings.Contains(err.Error(), test.exp) {
+			t.Errorf("file: %v exp error containing %q got: %v", test.file, test.exp, err)
+		}
+	}
+
+	// a missing tag establishes a baseline, but other errors are still returned
+	if _, err := New(SetVCS(git), SetBeforeFromVersionFile("NOTAG"), SetBaselineOnMissing(true)).Check(".", false, "", "HEAD"); err != ErrBaselineEstablished {
+		t.Errorf("exp ErrBaselineEstablished for missing tag, got: %v", err)
+	}
+	if _, err := New(SetVCS(git), SetBeforeFromVersionFile("BADVERSION"), SetBaselineOnMissing(true)).Check(".", false, "", "HEAD"); err == nil || err == ErrBaselineEstablished {
+		t.Errorf("exp error for invalid version, got: %v", err)
+	}
+
+	if _, err := New(SetVCS(StrVCS{}), SetBeforeFromVersionFile("VERSION")).Check("", false, "", "rev2"); err == nil {
+		t.Errorf("expected error using a version file without the git VCS")
+	}
+}
@@ -0,0 +1,104 @@
+package apicompat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// wireFormats are the struct tag keys of the serialisation formats tracked by
+// wireChanges.
+var wireFormats = []string{"json", "xml"}
+
+// wireChanges returns a description of each change to how a struct's named
+// fields are serialised by the wireFormats, and the position of the last
+// changed field. Only fields tagged for a format, before or after, are checked.
+func (c DeclChecker) wireChanges(before, after []*ast.Field) ([]string, token.Pos) {
+	afields := make(map[string]*ast.Field)
+	for _, afield := range after {
+		if len(afield.Names) > 0 {
+			afields[afield.Names[0].Name] = afield
+		}
+	}
+
+	var (
+		msgs []string
+		pos  token.Pos
+	)
+	for _, bfield := range before {
+		if len(bfield.Names) == 0 {
+			continue
+		}
+		afield, ok := afields[bfield.Names[0].Name]
+		if !ok {
+			continue
+		}
+		for _, format := range wireFormats {
+			msg := c.wireChange(format, bfield, afield)
+			if msg != "" {
+				msgs = append(msgs, msg)
+				pos = afield.Pos()
+			}
+		}
+	}
+	return msgs, pos
+}
+
+// wireChange returns a description of a change to how a field is serialised
+// by format, or an empty string if it's unchanged or not tagged for format.
+func (c DeclChecker) wireChange(format string, before, after *ast.Field) string {
+	btag, bok := fieldTag(before, format)
+	atag, aok := fieldTag(after, format)
+	if !bok && !aok {
+		return ""
+	}
+	bkey, bomit := wireKey(before, btag)
+	akey, aomit := wireKey(after, atag)
+
+	switch {
+	case bkey == "-" && akey == "-":
+		return ""
+	case bkey != akey:
+		return fmt.Sprintf("%s key %q renamed to %q", format, bkey, akey)
+	case bomit && !aomit:
+		return fmt.Sprintf("%s key %q omitempty removed", format, akey)
+	case !bomit && aomit:
+		return fmt.Sprintf("%s key %q omitempty added", format, akey)
+	case !c.exprEqual(before.Type, after.Type):
+		btype, atype := c.typeStrings(before.Type, after.Type)
+		return fmt.Sprintf("%s key %q: %s → %s", format, akey, btype, atype)
+	}
+	return ""
+}
+
+// fieldTag returns the value of a field's struct tag for key, and whether the
+// key was present.
+func fieldTag(field *ast.Field, key string) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	return reflect.StructTag(tag).Lookup(key)
+}
+
+// wireKey returns a field's serialised key given its tag value, which defaults
+// to the field's name, and whether the omitempty option is set.
+func wireKey(field *ast.Field, tag string) (key string, omitempty bool) {
+	opts := strings.Split(tag, ",")
+	key = opts[0]
+	if key == "" {
+		key = field.Names[0].Name
+	}
+	for _, opt := range opts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return key, omitempty
+}